@@ -135,9 +135,13 @@ type AssistantFilesList struct {
 }
 
 // CreateAssistant creates a new assistant.
-func (c *Client) CreateAssistant(ctx context.Context, request AssistantRequest) (response Assistant, err error) {
+func (c *Client) CreateAssistant(
+	ctx context.Context,
+	request AssistantRequest,
+	opts ...RequestOption,
+) (response Assistant, err error) {
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(assistantsSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -150,10 +154,11 @@ func (c *Client) CreateAssistant(ctx context.Context, request AssistantRequest)
 func (c *Client) RetrieveAssistant(
 	ctx context.Context,
 	assistantID string,
+	opts ...RequestOption,
 ) (response Assistant, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, assistantID)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -167,10 +172,11 @@ func (c *Client) ModifyAssistant(
 	ctx context.Context,
 	assistantID string,
 	request AssistantRequest,
+	opts ...RequestOption,
 ) (response Assistant, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, assistantID)
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -183,10 +189,11 @@ func (c *Client) ModifyAssistant(
 func (c *Client) DeleteAssistant(
 	ctx context.Context,
 	assistantID string,
+	opts ...RequestOption,
 ) (response AssistantDeleteResponse, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, assistantID)
 	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -202,6 +209,7 @@ func (c *Client) ListAssistants(
 	order *string,
 	after *string,
 	before *string,
+	opts ...RequestOption,
 ) (response AssistantsList, err error) {
 	urlValues := url.Values{}
 	if limit != nil {
@@ -224,7 +232,7 @@ func (c *Client) ListAssistants(
 
 	urlSuffix := fmt.Sprintf("%s%s", assistantsSuffix, encodedValues)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -238,11 +246,12 @@ func (c *Client) CreateAssistantFile(
 	ctx context.Context,
 	assistantID string,
 	request AssistantFileRequest,
+	opts ...RequestOption,
 ) (response AssistantFile, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s", assistantsSuffix, assistantID, assistantsFilesSuffix)
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -256,10 +265,11 @@ func (c *Client) RetrieveAssistantFile(
 	ctx context.Context,
 	assistantID string,
 	fileID string,
+	opts ...RequestOption,
 ) (response AssistantFile, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", assistantsSuffix, assistantID, assistantsFilesSuffix, fileID)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -273,10 +283,11 @@ func (c *Client) DeleteAssistantFile(
 	ctx context.Context,
 	assistantID string,
 	fileID string,
+	opts ...RequestOption,
 ) (err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", assistantsSuffix, assistantID, assistantsFilesSuffix, fileID)
 	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -293,6 +304,7 @@ func (c *Client) ListAssistantFiles(
 	order *string,
 	after *string,
 	before *string,
+	opts ...RequestOption,
 ) (response AssistantFilesList, err error) {
 	urlValues := url.Values{}
 	if limit != nil {
@@ -315,7 +327,7 @@ func (c *Client) ListAssistantFiles(
 
 	urlSuffix := fmt.Sprintf("%s/%s%s%s", assistantsSuffix, assistantID, assistantsFilesSuffix, encodedValues)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
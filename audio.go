@@ -1,12 +1,14 @@
 package openai
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 
 	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
 )
@@ -14,6 +16,15 @@ import (
 // Whisper Defines the models provided by OpenAI to use when processing audio with OpenAI.
 const (
 	Whisper1 = "whisper-1"
+
+	GPT4oTranscribe        = "gpt-4o-transcribe"
+	GPT4oMiniTranscribe    = "gpt-4o-mini-transcribe"
+	GPT4oTranscribeDiarize = "gpt-4o-transcribe-diarize"
+)
+
+var (
+	ErrTranscriptionStreamNotSupported     = errors.New("streaming is not supported with this method, please use CreateTranscriptionStream") //nolint:lll
+	ErrTranscriptionStreamUnsupportedModel = errors.New("streaming transcriptions are not supported by whisper-1")
 )
 
 // Response formats; Whisper uses AudioResponseFormatJSON by default.
@@ -25,8 +36,66 @@ const (
 	AudioResponseFormatSRT         AudioResponseFormat = "srt"
 	AudioResponseFormatVerboseJSON AudioResponseFormat = "verbose_json"
 	AudioResponseFormatVTT         AudioResponseFormat = "vtt"
+	// AudioResponseFormatDiarizedJSON returns segments labeled with their speaker.
+	// It is only supported by gpt-4o-transcribe-diarize, which also requires a
+	// ChunkingStrategy for audio longer than 30 seconds.
+	AudioResponseFormatDiarizedJSON AudioResponseFormat = "diarized_json"
+)
+
+// TranscriptionInclude is additional information to include in a transcription response.
+type TranscriptionInclude string
+
+const (
+	// TranscriptionIncludeLogprobs returns the log probabilities of the tokens of the transcription.
+	// Only supported by gpt-4o-transcribe and gpt-4o-mini-transcribe with the json response format.
+	TranscriptionIncludeLogprobs TranscriptionInclude = "logprobs"
+)
+
+// Chunking strategies of a transcription.
+const (
+	TranscriptionChunkingStrategyAuto      = "auto"
+	TranscriptionChunkingStrategyServerVAD = "server_vad"
 )
 
+// TranscriptionChunkingStrategy controls how the audio is cut into chunks.
+// With "auto", the server normalizes loudness and then uses voice activity detection to choose boundaries.
+// With "server_vad", the voice activity detection is tuned by the other fields.
+type TranscriptionChunkingStrategy struct {
+	Type              string
+	PrefixPaddingMs   int
+	SilenceDurationMs int
+	Threshold         float64
+}
+
+func (s *TranscriptionChunkingStrategy) writeFields(b utils.FormBuilder) error {
+	if s == nil {
+		return nil
+	}
+	if s.Type == "" || s.Type == TranscriptionChunkingStrategyAuto {
+		return b.WriteField("chunking_strategy", TranscriptionChunkingStrategyAuto)
+	}
+
+	fields := []struct{ name, value string }{{"chunking_strategy[type]", s.Type}}
+	if s.PrefixPaddingMs != 0 {
+		fields = append(fields, struct{ name, value string }{
+			"chunking_strategy[prefix_padding_ms]", strconv.Itoa(s.PrefixPaddingMs)})
+	}
+	if s.SilenceDurationMs != 0 {
+		fields = append(fields, struct{ name, value string }{
+			"chunking_strategy[silence_duration_ms]", strconv.Itoa(s.SilenceDurationMs)})
+	}
+	if s.Threshold != 0 {
+		fields = append(fields, struct{ name, value string }{
+			"chunking_strategy[threshold]", strconv.FormatFloat(s.Threshold, 'f', -1, 64)})
+	}
+	for _, field := range fields {
+		if err := b.WriteField(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type TranscriptionTimestampGranularity string
 
 const (
@@ -49,36 +118,118 @@ type AudioRequest struct {
 	Language               string // Only for transcription.
 	Format                 AudioResponseFormat
 	TimestampGranularities []TranscriptionTimestampGranularity // Only for transcription.
+
+	// Include requests additional information in the response, such as logprobs. Only for transcription.
+	Include []TranscriptionInclude
+	// ChunkingStrategy controls how the audio is cut into chunks. Only for transcription.
+	ChunkingStrategy *TranscriptionChunkingStrategy
+	// KnownSpeakerNames are the names of up to 4 speakers, with an audio sample of each speaker
+	// as a data URL at the same index of KnownSpeakerReferences. Only for diarization models.
+	KnownSpeakerNames      []string
+	KnownSpeakerReferences []string
+	// Stream is set by CreateTranscriptionStream.
+	Stream bool
+
+	// Progress is optionally called as the audio file is uploaded.
+	Progress UploadProgressFunc
 }
 
 // AudioResponse represents a response structure for audio API.
 type AudioResponse struct {
-	Task     string  `json:"task"`
-	Language string  `json:"language"`
-	Duration float64 `json:"duration"`
-	Segments []struct {
-		ID               int     `json:"id"`
-		Seek             int     `json:"seek"`
-		Start            float64 `json:"start"`
-		End              float64 `json:"end"`
-		Text             string  `json:"text"`
-		Tokens           []int   `json:"tokens"`
-		Temperature      float64 `json:"temperature"`
-		AvgLogprob       float64 `json:"avg_logprob"`
-		CompressionRatio float64 `json:"compression_ratio"`
-		NoSpeechProb     float64 `json:"no_speech_prob"`
-		Transient        bool    `json:"transient"`
-	} `json:"segments"`
-	Words []struct {
-		Word  string  `json:"word"`
-		Start float64 `json:"start"`
-		End   float64 `json:"end"`
-	} `json:"words"`
-	Text string `json:"text"`
+	Task     string                 `json:"task"`
+	Language string                 `json:"language"`
+	Duration float64                `json:"duration"`
+	Segments []TranscriptionSegment `json:"segments"`
+	Words    []TranscriptionWord    `json:"words"`
+	Text     string                 `json:"text"`
+
+	// Logprobs is set when TranscriptionIncludeLogprobs is requested.
+	Logprobs []TranscriptionLogprob `json:"logprobs,omitempty"`
+	Usage    *TranscriptionUsage    `json:"usage,omitempty"`
 
 	httpHeader
 }
 
+// TranscriptionSegment is a segment of a verbose_json or diarized_json transcription.
+type TranscriptionSegment struct {
+	ID               int     `json:"id"`
+	Seek             int     `json:"seek"`
+	Start            float64 `json:"start"`
+	End              float64 `json:"end"`
+	Text             string  `json:"text"`
+	Tokens           []int   `json:"tokens"`
+	Temperature      float64 `json:"temperature"`
+	AvgLogprob       float64 `json:"avg_logprob"`
+	CompressionRatio float64 `json:"compression_ratio"`
+	NoSpeechProb     float64 `json:"no_speech_prob"`
+	Transient        bool    `json:"transient"`
+
+	// Type, SegmentID and Speaker are set by diarization models. Diarized segments are
+	// identified by a string, which is decoded into SegmentID rather than ID.
+	Type      string `json:"type,omitempty"`
+	SegmentID string `json:"-"`
+	Speaker   string `json:"speaker,omitempty"`
+}
+
+type transcriptionSegmentJSON TranscriptionSegment
+
+func (s TranscriptionSegment) MarshalJSON() ([]byte, error) {
+	if s.SegmentID == "" {
+		return json.Marshal(transcriptionSegmentJSON(s))
+	}
+	return json.Marshal(struct {
+		transcriptionSegmentJSON
+		ID string `json:"id"`
+	}{transcriptionSegmentJSON(s), s.SegmentID})
+}
+
+func (s *TranscriptionSegment) UnmarshalJSON(data []byte) error {
+	var segment struct {
+		transcriptionSegmentJSON
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &segment); err != nil {
+		return err
+	}
+	*s = TranscriptionSegment(segment.transcriptionSegmentJSON)
+	if len(segment.ID) == 0 || string(segment.ID) == "null" {
+		return nil
+	}
+	if segment.ID[0] == '"' {
+		return json.Unmarshal(segment.ID, &s.SegmentID)
+	}
+	return json.Unmarshal(segment.ID, &s.ID)
+}
+
+// TranscriptionWord is a word of a transcription requested with TranscriptionTimestampGranularityWord.
+type TranscriptionWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TranscriptionLogprob is the log probability of a token of a transcription.
+type TranscriptionLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
+// TranscriptionUsage is the usage of a transcription, billed either by tokens or by duration.
+type TranscriptionUsage struct {
+	Type              string                         `json:"type"`
+	InputTokens       int                            `json:"input_tokens,omitempty"`
+	OutputTokens      int                            `json:"output_tokens,omitempty"`
+	TotalTokens       int                            `json:"total_tokens,omitempty"`
+	InputTokenDetails *TranscriptionInputTokenDetail `json:"input_token_details,omitempty"`
+	Seconds           float64                        `json:"seconds,omitempty"`
+}
+
+type TranscriptionInputTokenDetail struct {
+	TextTokens  int `json:"text_tokens"`
+	AudioTokens int `json:"audio_tokens"`
+}
+
 type audioTextResponse struct {
 	Text string `json:"text"`
 
@@ -96,16 +247,22 @@ func (r *audioTextResponse) ToAudioResponse() AudioResponse {
 func (c *Client) CreateTranscription(
 	ctx context.Context,
 	request AudioRequest,
+	opts ...RequestOption,
 ) (response AudioResponse, err error) {
-	return c.callAudioAPI(ctx, request, "transcriptions")
+	if request.Stream {
+		err = ErrTranscriptionStreamNotSupported
+		return
+	}
+	return c.callAudioAPI(ctx, request, "transcriptions", opts...)
 }
 
 // CreateTranslation — API call to translate audio into English.
 func (c *Client) CreateTranslation(
 	ctx context.Context,
 	request AudioRequest,
+	opts ...RequestOption,
 ) (response AudioResponse, err error) {
-	return c.callAudioAPI(ctx, request, "translations")
+	return c.callAudioAPI(ctx, request, "translations", opts...)
 }
 
 // callAudioAPI — API call to an audio endpoint.
@@ -113,21 +270,18 @@ func (c *Client) callAudioAPI(
 	ctx context.Context,
 	request AudioRequest,
 	endpointSuffix string,
+	opts ...RequestOption,
 ) (response AudioResponse, err error) {
-	var formBody bytes.Buffer
-	builder := c.createFormBuilder(&formBody)
-
-	if err = audioMultipartForm(request, builder); err != nil {
-		return AudioResponse{}, err
-	}
-
 	urlSuffix := fmt.Sprintf("/audio/%s", endpointSuffix)
-	req, err := c.newRequest(
+	req, err := c.newMultipartRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
-		withBody(&formBody),
-		withContentType(builder.FormDataContentType()),
+		func(b utils.FormBuilder) error {
+			return audioMultipartForm(request, b)
+		},
+		request.Progress,
+		withOptions(opts),
 	)
 	if err != nil {
 		return AudioResponse{}, err
@@ -148,7 +302,11 @@ func (c *Client) callAudioAPI(
 
 // HasJSONResponse returns true if the response format is JSON.
 func (r AudioRequest) HasJSONResponse() bool {
-	return r.Format == "" || r.Format == AudioResponseFormatJSON || r.Format == AudioResponseFormatVerboseJSON
+	switch r.Format {
+	case "", AudioResponseFormatJSON, AudioResponseFormatVerboseJSON, AudioResponseFormatDiarizedJSON:
+		return true
+	}
+	return false
 }
 
 // audioMultipartForm creates a form with audio file contents and the name of the model to use for
@@ -205,6 +363,38 @@ func audioMultipartForm(request AudioRequest, b utils.FormBuilder) error {
 		}
 	}
 
+	for _, include := range request.Include {
+		err = b.WriteField("include[]", string(include))
+		if err != nil {
+			return fmt.Errorf("writing include[]: %w", err)
+		}
+	}
+
+	err = request.ChunkingStrategy.writeFields(b)
+	if err != nil {
+		return fmt.Errorf("writing chunking_strategy: %w", err)
+	}
+
+	for _, name := range request.KnownSpeakerNames {
+		err = b.WriteField("known_speaker_names[]", name)
+		if err != nil {
+			return fmt.Errorf("writing known_speaker_names[]: %w", err)
+		}
+	}
+	for _, reference := range request.KnownSpeakerReferences {
+		err = b.WriteField("known_speaker_references[]", reference)
+		if err != nil {
+			return fmt.Errorf("writing known_speaker_references[]: %w", err)
+		}
+	}
+
+	if request.Stream {
+		err = b.WriteField("stream", "true")
+		if err != nil {
+			return fmt.Errorf("writing stream: %w", err)
+		}
+	}
+
 	// Close the multipart writer
 	return b.Close()
 }
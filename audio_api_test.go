@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"mime"
 	"mime/multipart"
@@ -26,7 +27,7 @@ func TestAudio(t *testing.T) {
 
 	testcases := []struct {
 		name     string
-		createFn func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)
+		createFn func(context.Context, openai.AudioRequest, ...openai.RequestOption) (openai.AudioResponse, error)
 	}{
 		{
 			"transcribe",
@@ -73,7 +74,7 @@ func TestAudioWithOptionalArgs(t *testing.T) {
 
 	testcases := []struct {
 		name     string
-		createFn func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)
+		createFn func(context.Context, openai.AudioRequest, ...openai.RequestOption) (openai.AudioResponse, error)
 	}{
 		{
 			"transcribe",
@@ -110,6 +111,104 @@ func TestAudioWithOptionalArgs(t *testing.T) {
 	}
 }
 
+func TestTranscriptionNewFields(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		expected := map[string][]string{
+			"include[]":                              {"logprobs"},
+			"chunking_strategy[type]":                {"server_vad"},
+			"chunking_strategy[silence_duration_ms]": {"500"},
+			"chunking_strategy[threshold]":           {"0.6"},
+			"known_speaker_names[]":                  {"agent", "customer"},
+			"known_speaker_references[]":             {"data:audio/wav;base64,AA==", "data:audio/wav;base64,AQ=="},
+		}
+		for field, values := range expected {
+			if strings.Join(r.MultipartForm.Value[field], ",") != strings.Join(values, ",") {
+				t.Errorf("field %s: expected %v, got %v", field, values, r.MultipartForm.Value[field])
+			}
+		}
+		if _, ok := r.MultipartForm.Value["stream"]; ok {
+			t.Error("non-streaming requests must not send stream")
+		}
+		fmt.Fprintln(w, `{"text":"hi","logprobs":[{"token":"hi","logprob":-0.1,"bytes":[104,105]}],`+
+			`"usage":{"type":"tokens","input_tokens":10,"output_tokens":1,"total_tokens":11}}`)
+	})
+
+	resp, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		Model:    openai.GPT4oTranscribeDiarize,
+		FilePath: "call.wav",
+		Reader:   strings.NewReader("wav"),
+		Include:  []openai.TranscriptionInclude{openai.TranscriptionIncludeLogprobs},
+		ChunkingStrategy: &openai.TranscriptionChunkingStrategy{
+			Type:              openai.TranscriptionChunkingStrategyServerVAD,
+			SilenceDurationMs: 500,
+			Threshold:         0.6,
+		},
+		KnownSpeakerNames:      []string{"agent", "customer"},
+		KnownSpeakerReferences: []string{"data:audio/wav;base64,AA==", "data:audio/wav;base64,AQ=="},
+	})
+	checks.NoError(t, err, "CreateTranscription error")
+	if len(resp.Logprobs) != 1 || resp.Logprobs[0].Token != "hi" || resp.Usage == nil || resp.Usage.TotalTokens != 11 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	_, err = client.CreateTranscription(context.Background(), openai.AudioRequest{Stream: true})
+	checks.ErrorIs(t, err, openai.ErrTranscriptionStreamNotSupported, "CreateTranscription cannot stream")
+}
+
+func TestCreateTranscriptionStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		if r.FormValue("stream") != "true" || r.FormValue("chunking_strategy") != "auto" {
+			t.Errorf("unexpected stream %q and chunking strategy %q",
+				r.FormValue("stream"), r.FormValue("chunking_strategy"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"type":"transcript.text.delta","delta":"Hello","logprobs":[{"token":"Hello","logprob":-0.5}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"type":"transcript.text.delta","delta":" world"}`+"\n\n")
+		fmt.Fprint(w, `data: {"type":"transcript.text.done","text":"Hello world",`+
+			`"usage":{"type":"tokens","input_tokens":5,"output_tokens":2,"total_tokens":7}}`+"\n\n")
+	})
+
+	stream, err := client.CreateTranscriptionStream(context.Background(), openai.AudioRequest{
+		Model:            openai.GPT4oMiniTranscribe,
+		FilePath:         "hello.mp3",
+		Reader:           strings.NewReader("mp3"),
+		ChunkingStrategy: &openai.TranscriptionChunkingStrategy{Type: openai.TranscriptionChunkingStrategyAuto},
+	})
+	checks.NoError(t, err, "CreateTranscriptionStream error")
+	defer stream.Close()
+
+	var text strings.Builder
+	var done openai.TranscriptionStreamEvent
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		switch event.Type {
+		case openai.TranscriptionStreamEventTextDelta:
+			text.WriteString(event.Delta)
+			if event.Delta == "Hello" && (len(event.Logprobs) != 1 || event.Logprobs[0].Logprob != -0.5) {
+				t.Errorf("unexpected logprobs %+v", event.Logprobs)
+			}
+		case openai.TranscriptionStreamEventTextDone:
+			done = event
+		}
+	}
+	if text.String() != "Hello world" || done.Text != "Hello world" || done.Usage == nil || done.Usage.TotalTokens != 7 {
+		t.Fatalf("unexpected transcription %q, done event %+v", text.String(), done)
+	}
+
+	_, err = client.CreateTranscriptionStream(context.Background(), openai.AudioRequest{Model: openai.Whisper1})
+	checks.ErrorIs(t, err, openai.ErrTranscriptionStreamUnsupportedModel, "whisper-1 cannot stream")
+}
+
 // handleAudioEndpoint Handles the completion endpoint by the test server.
 func handleAudioEndpoint(w http.ResponseWriter, r *http.Request) {
 	var err error
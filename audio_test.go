@@ -30,6 +30,11 @@ func TestAudioWithFailingFormBuilder(t *testing.T) {
 			TranscriptionTimestampGranularitySegment,
 			TranscriptionTimestampGranularityWord,
 		},
+		Include:                []TranscriptionInclude{TranscriptionIncludeLogprobs},
+		ChunkingStrategy:       &TranscriptionChunkingStrategy{Type: TranscriptionChunkingStrategyServerVAD},
+		KnownSpeakerNames:      []string{"agent"},
+		KnownSpeakerReferences: []string{"data:audio/wav;base64,AA=="},
+		Stream:                 true,
 	}
 
 	mockFailedErr := fmt.Errorf("mock form builder fail")
@@ -53,7 +58,10 @@ func TestAudioWithFailingFormBuilder(t *testing.T) {
 		return nil
 	}
 
-	failOn := []string{"model", "prompt", "temperature", "language", "response_format", "timestamp_granularities[]"}
+	failOn := []string{
+		"model", "prompt", "temperature", "language", "response_format", "timestamp_granularities[]",
+		"include[]", "chunking_strategy[type]", "known_speaker_names[]", "known_speaker_references[]", "stream",
+	}
 	for _, failingField := range failOn {
 		failForField = failingField
 		mockFailedErr = fmt.Errorf("mock form builder fail on field %s", failingField)
@@ -1,10 +1,13 @@
 package openai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 )
@@ -17,6 +20,17 @@ const (
 	BatchEndpointChatCompletions BatchEndpoint = "/v1/chat/completions"
 	BatchEndpointCompletions     BatchEndpoint = "/v1/completions"
 	BatchEndpointEmbeddings      BatchEndpoint = "/v1/embeddings"
+	BatchEndpointResponses       BatchEndpoint = "/v1/responses"
+	BatchEndpointModerations     BatchEndpoint = "/v1/moderations"
+)
+
+var (
+	ErrBatchFileEmpty          = errors.New("batch file must contain at least one request")
+	ErrBatchDuplicateCustomID  = errors.New("batch file contains a duplicate custom_id")
+	ErrBatchEndpointMismatch   = errors.New("all requests in a batch file must target the same endpoint")
+	ErrBatchModelMismatch      = errors.New("all requests in a batch file must use the same model")
+	ErrBatchResultNoResponse   = errors.New("batch result has no response body")
+	ErrBatchResultLineTooLarge = errors.New("batch result line exceeds the maximum size")
 )
 
 type BatchLineItem interface {
@@ -59,6 +73,125 @@ func (r BatchEmbeddingRequest) MarshalBatchLineItem() []byte {
 	return marshal
 }
 
+// BatchRequest is a generic batch line item. It can carry any request body
+// for any endpoint, which is useful for endpoints without a dedicated line item type.
+type BatchRequest struct {
+	CustomID string        `json:"custom_id"`
+	Body     any           `json:"body"`
+	Method   string        `json:"method"`
+	URL      BatchEndpoint `json:"url"`
+}
+
+func (r BatchRequest) MarshalBatchLineItem() []byte {
+	marshal, _ := json.Marshal(r)
+	return marshal
+}
+
+type BatchModerationRequest struct {
+	CustomID string            `json:"custom_id"`
+	Body     ModerationRequest `json:"body"`
+	Method   string            `json:"method"`
+	URL      BatchEndpoint     `json:"url"`
+}
+
+func (r BatchModerationRequest) MarshalBatchLineItem() []byte {
+	marshal, _ := json.Marshal(r)
+	return marshal
+}
+
+// ResponsesRequest is the body of a /v1/responses batch line item.
+// It covers the commonly used parameters of the Responses API; use AddRequest
+// with a custom body for anything not listed here.
+type ResponsesRequest struct {
+	Model              string            `json:"model"`
+	Input              any               `json:"input"`
+	Instructions       string            `json:"instructions,omitempty"`
+	MaxOutputTokens    int               `json:"max_output_tokens,omitempty"`
+	Temperature        *float32          `json:"temperature,omitempty"`
+	TopP               *float32          `json:"top_p,omitempty"`
+	Tools              []any             `json:"tools,omitempty"`
+	ToolChoice         any               `json:"tool_choice,omitempty"`
+	Text               any               `json:"text,omitempty"`
+	Reasoning          any               `json:"reasoning,omitempty"`
+	PreviousResponseID string            `json:"previous_response_id,omitempty"`
+	Store              *bool             `json:"store,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	User               string            `json:"user,omitempty"`
+}
+
+type BatchResponsesRequest struct {
+	CustomID string           `json:"custom_id"`
+	Body     ResponsesRequest `json:"body"`
+	Method   string           `json:"method"`
+	URL      BatchEndpoint    `json:"url"`
+}
+
+func (r BatchResponsesRequest) MarshalBatchLineItem() []byte {
+	marshal, _ := json.Marshal(r)
+	return marshal
+}
+
+// ResponsesOutputContent is a content part of a Responses API output item.
+type ResponsesOutputContent struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	Refusal     string `json:"refusal,omitempty"`
+	Annotations []any  `json:"annotations,omitempty"`
+}
+
+// ResponsesOutputItem is an item of the output array of a Responses API response.
+type ResponsesOutputItem struct {
+	Type      string                   `json:"type"`
+	ID        string                   `json:"id"`
+	Status    string                   `json:"status,omitempty"`
+	Role      string                   `json:"role,omitempty"`
+	Content   []ResponsesOutputContent `json:"content,omitempty"`
+	CallID    string                   `json:"call_id,omitempty"`
+	Name      string                   `json:"name,omitempty"`
+	Arguments string                   `json:"arguments,omitempty"`
+}
+
+type ResponsesUsage struct {
+	InputTokens        int `json:"input_tokens"`
+	OutputTokens       int `json:"output_tokens"`
+	TotalTokens        int `json:"total_tokens"`
+	InputTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"input_tokens_details"`
+	OutputTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"output_tokens_details"`
+}
+
+// ResponsesResponse is the body of a /v1/responses batch result.
+type ResponsesResponse struct {
+	ID        string                `json:"id"`
+	Object    string                `json:"object"`
+	CreatedAt int64                 `json:"created_at"`
+	Status    string                `json:"status"`
+	Model     string                `json:"model"`
+	Output    []ResponsesOutputItem `json:"output"`
+	Usage     ResponsesUsage        `json:"usage"`
+	Error     *APIError             `json:"error,omitempty"`
+	Metadata  map[string]string     `json:"metadata,omitempty"`
+}
+
+// OutputText concatenates the text of all output_text parts of message output items.
+func (r ResponsesResponse) OutputText() string {
+	var b bytes.Buffer
+	for _, item := range r.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, content := range item.Content {
+			if content.Type == "output_text" {
+				b.WriteString(content.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
 type Batch struct {
 	ID       string        `json:"id"`
 	Object   string        `json:"object"`
@@ -112,12 +245,13 @@ type BatchResponse struct {
 func (c *Client) CreateBatch(
 	ctx context.Context,
 	request CreateBatchRequest,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
 	if request.CompletionWindow == "" {
 		request.CompletionWindow = "24h"
 	}
 
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(batchesSuffix), withBody(request))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(batchesSuffix), withBody(request), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -169,8 +303,83 @@ func (r *UploadBatchFileRequest) AddEmbedding(customerID string, body EmbeddingR
 	})
 }
 
+func (r *UploadBatchFileRequest) AddResponse(customerID string, body ResponsesRequest) {
+	r.Lines = append(r.Lines, BatchResponsesRequest{
+		CustomID: customerID,
+		Body:     body,
+		Method:   "POST",
+		URL:      BatchEndpointResponses,
+	})
+}
+
+func (r *UploadBatchFileRequest) AddModeration(customerID string, body ModerationRequest) {
+	r.Lines = append(r.Lines, BatchModerationRequest{
+		CustomID: customerID,
+		Body:     body,
+		Method:   "POST",
+		URL:      BatchEndpointModerations,
+	})
+}
+
+// AddRequest adds an arbitrary request body for the given endpoint.
+func (r *UploadBatchFileRequest) AddRequest(customerID string, endpoint BatchEndpoint, body any) {
+	r.Lines = append(r.Lines, BatchRequest{
+		CustomID: customerID,
+		Body:     body,
+		Method:   "POST",
+		URL:      endpoint,
+	})
+}
+
+// Validate checks the batch file before uploading: it must not be empty, custom IDs must be unique,
+// and every line must target the same endpoint with the same model. UploadBatchFile does not call it.
+func (r *UploadBatchFileRequest) Validate() error {
+	if len(r.Lines) == 0 {
+		return ErrBatchFileEmpty
+	}
+
+	var (
+		endpoint  BatchEndpoint
+		model     string
+		customIDs = make(map[string]struct{}, len(r.Lines))
+	)
+	for i, line := range r.Lines {
+		var item struct {
+			CustomID string        `json:"custom_id"`
+			URL      BatchEndpoint `json:"url"`
+			Body     struct {
+				Model string `json:"model"`
+			} `json:"body"`
+		}
+		if err := json.Unmarshal(line.MarshalBatchLineItem(), &item); err != nil {
+			return fmt.Errorf("batch line %d: %w", i+1, err)
+		}
+
+		if _, ok := customIDs[item.CustomID]; ok {
+			return fmt.Errorf("batch line %d: %w: %q", i+1, ErrBatchDuplicateCustomID, item.CustomID)
+		}
+		customIDs[item.CustomID] = struct{}{}
+
+		if i == 0 {
+			endpoint, model = item.URL, item.Body.Model
+			continue
+		}
+		if item.URL != endpoint {
+			return fmt.Errorf("batch line %d: %w: %q and %q", i+1, ErrBatchEndpointMismatch, endpoint, item.URL)
+		}
+		if item.Body.Model != model {
+			return fmt.Errorf("batch line %d: %w: %q and %q", i+1, ErrBatchModelMismatch, model, item.Body.Model)
+		}
+	}
+	return nil
+}
+
 // UploadBatchFile — upload batch file.
-func (c *Client) UploadBatchFile(ctx context.Context, request UploadBatchFileRequest) (File, error) {
+func (c *Client) UploadBatchFile(
+	ctx context.Context,
+	request UploadBatchFileRequest,
+	opts ...RequestOption,
+) (File, error) {
 	if request.FileName == "" {
 		request.FileName = "@batchinput.jsonl"
 	}
@@ -178,7 +387,7 @@ func (c *Client) UploadBatchFile(ctx context.Context, request UploadBatchFileReq
 		Name:    request.FileName,
 		Bytes:   request.MarshalJSONL(),
 		Purpose: PurposeBatch,
-	})
+	}, opts...)
 }
 
 type CreateBatchWithUploadFileRequest struct {
@@ -192,12 +401,13 @@ type CreateBatchWithUploadFileRequest struct {
 func (c *Client) CreateBatchWithUploadFile(
 	ctx context.Context,
 	request CreateBatchWithUploadFileRequest,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
 	var file File
 	file, err = c.UploadBatchFile(ctx, UploadBatchFileRequest{
 		FileName: request.FileName,
 		Lines:    request.Lines,
-	})
+	}, opts...)
 	if err != nil {
 		return
 	}
@@ -206,16 +416,17 @@ func (c *Client) CreateBatchWithUploadFile(
 		Endpoint:         request.Endpoint,
 		CompletionWindow: request.CompletionWindow,
 		Metadata:         request.Metadata,
-	})
+	}, opts...)
 }
 
 // RetrieveBatch — API call to Retrieve batch.
 func (c *Client) RetrieveBatch(
 	ctx context.Context,
 	batchID string,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", batchesSuffix, batchID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -227,9 +438,10 @@ func (c *Client) RetrieveBatch(
 func (c *Client) CancelBatch(
 	ctx context.Context,
 	batchID string,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s/cancel", batchesSuffix, batchID)
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -247,7 +459,12 @@ type ListBatchResponse struct {
 }
 
 // ListBatch API call to List batch.
-func (c *Client) ListBatch(ctx context.Context, after *string, limit *int) (response ListBatchResponse, err error) {
+func (c *Client) ListBatch(
+	ctx context.Context,
+	after *string,
+	limit *int,
+	opts ...RequestOption,
+) (response ListBatchResponse, err error) {
 	urlValues := url.Values{}
 	if limit != nil {
 		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
@@ -261,7 +478,7 @@ func (c *Client) ListBatch(ctx context.Context, after *string, limit *int) (resp
 	}
 
 	urlSuffix := fmt.Sprintf("%s%s", batchesSuffix, encodedValues)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -269,3 +486,124 @@ func (c *Client) ListBatch(ctx context.Context, after *string, limit *int) (resp
 	err = c.sendRequest(req, &response)
 	return
 }
+
+// BatchResult is a single line of a batch output or error file.
+type BatchResult struct {
+	ID       string               `json:"id"`
+	CustomID string               `json:"custom_id"`
+	Response *BatchResultResponse `json:"response"`
+	Error    *BatchResultError    `json:"error"`
+}
+
+type BatchResultResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+type BatchResultError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *BatchResultError) Error() string {
+	return fmt.Sprintf("batch request failed, code: %s, message: %s", e.Code, e.Message)
+}
+
+// Decode unmarshals the response body into v. If the request failed, the
+// returned error is either the line's BatchResultError or the APIError carried
+// in the response body.
+func (r BatchResult) Decode(v any) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.Response == nil || len(r.Response.Body) == 0 {
+		return ErrBatchResultNoResponse
+	}
+	if r.Response.StatusCode >= http.StatusBadRequest {
+		var errRes ErrorResponse
+		if err := json.Unmarshal(r.Response.Body, &errRes); err == nil && errRes.Error != nil {
+			errRes.Error.HTTPStatusCode = r.Response.StatusCode
+			errRes.Error.HTTPStatus = http.StatusText(r.Response.StatusCode)
+			return errRes.Error
+		}
+		return &RequestError{
+			HTTPStatus:     http.StatusText(r.Response.StatusCode),
+			HTTPStatusCode: r.Response.StatusCode,
+			Body:           r.Response.Body,
+		}
+	}
+	return json.Unmarshal(r.Response.Body, v)
+}
+
+func (r BatchResult) DecodeChatCompletion() (response ChatCompletionResponse, err error) {
+	err = r.Decode(&response)
+	return
+}
+
+func (r BatchResult) DecodeCompletion() (response CompletionResponse, err error) {
+	err = r.Decode(&response)
+	return
+}
+
+func (r BatchResult) DecodeEmbedding() (response EmbeddingResponse, err error) {
+	err = r.Decode(&response)
+	return
+}
+
+func (r BatchResult) DecodeResponse() (response ResponsesResponse, err error) {
+	err = r.Decode(&response)
+	return
+}
+
+func (r BatchResult) DecodeModeration() (response ModerationResponse, err error) {
+	err = r.Decode(&response)
+	return
+}
+
+const maxBatchResultLineSize = 64 * 1024 * 1024
+
+// ParseBatchResults parses a batch output or error file in JSONL format.
+func ParseBatchResults(r io.Reader) ([]BatchResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxBatchResultLineSize)
+
+	var (
+		results []BatchResult
+		lineNum int
+	)
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var result BatchResult
+		if err := json.Unmarshal(line, &result); err != nil {
+			return nil, fmt.Errorf("parsing batch result line %d: %w", lineNum, err)
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, ErrBatchResultLineTooLarge
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// RetrieveBatchResults downloads and parses a batch output or error file.
+func (c *Client) RetrieveBatchResults(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) ([]BatchResult, error) {
+	content, err := c.GetFileContent(ctx, fileID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	return ParseBatchResults(content)
+}
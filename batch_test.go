@@ -2,9 +2,11 @@ package openai_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"gitlab.forensix.cn/ai/service/go-openai"
@@ -227,6 +229,140 @@ func TestUploadBatchFileRequest_AddEmbedding(t *testing.T) {
 	}
 }
 
+func TestUploadBatchFileRequest_AddResponse(t *testing.T) {
+	r := &openai.UploadBatchFileRequest{}
+	r.AddResponse("req-1", openai.ResponsesRequest{
+		Model: openai.GPT4o,
+		Input: "Hello!",
+	})
+	want := `{"custom_id":"req-1","body":{"model":"gpt-4o","input":"Hello!"},"method":"POST","url":"/v1/responses"}`
+	if got := string(r.MarshalJSONL()); got != want {
+		t.Errorf("Marshal() got = %v, want %v", got, want)
+	}
+}
+
+func TestUploadBatchFileRequest_AddModeration(t *testing.T) {
+	r := &openai.UploadBatchFileRequest{}
+	r.AddModeration("req-1", openai.ModerationRequest{
+		Model: openai.ModerationOmniLatest,
+		Input: "Hello!",
+	})
+	want := `{"custom_id":"req-1","body":{"input":"Hello!","model":"omni-moderation-latest"},"method":"POST","url":"/v1/moderations"}` //nolint:lll
+	if got := string(r.MarshalJSONL()); got != want {
+		t.Errorf("Marshal() got = %v, want %v", got, want)
+	}
+}
+
+func TestUploadBatchFileRequest_AddRequest(t *testing.T) {
+	r := &openai.UploadBatchFileRequest{}
+	r.AddRequest("req-1", openai.BatchEndpointEmbeddings, map[string]any{
+		"model": "text-embedding-3-small",
+		"input": "Hello!",
+	})
+	want := `{"custom_id":"req-1","body":{"input":"Hello!","model":"text-embedding-3-small"},"method":"POST","url":"/v1/embeddings"}` //nolint:lll
+	if got := string(r.MarshalJSONL()); got != want {
+		t.Errorf("Marshal() got = %v, want %v", got, want)
+	}
+}
+
+func TestUploadBatchFileRequest_Validate(t *testing.T) {
+	chat := openai.ChatCompletionRequest{Model: openai.GPT4o}
+	tests := []struct {
+		name    string
+		build   func(r *openai.UploadBatchFileRequest)
+		wantErr error
+	}{
+		{"empty", func(*openai.UploadBatchFileRequest) {}, openai.ErrBatchFileEmpty},
+		{"valid", func(r *openai.UploadBatchFileRequest) {
+			r.AddChatCompletion("req-1", chat)
+			r.AddChatCompletion("req-2", chat)
+		}, nil},
+		{"duplicate custom id", func(r *openai.UploadBatchFileRequest) {
+			r.AddChatCompletion("req-1", chat)
+			r.AddChatCompletion("req-1", chat)
+		}, openai.ErrBatchDuplicateCustomID},
+		{"mixed endpoints", func(r *openai.UploadBatchFileRequest) {
+			r.AddChatCompletion("req-1", chat)
+			r.AddCompletion("req-2", openai.CompletionRequest{Model: openai.GPT4o})
+		}, openai.ErrBatchEndpointMismatch},
+		{"mixed models", func(r *openai.UploadBatchFileRequest) {
+			r.AddChatCompletion("req-1", chat)
+			r.AddRequest("req-2", openai.BatchEndpointChatCompletions, openai.ChatCompletionRequest{Model: openai.GPT4oMini})
+		}, openai.ErrBatchModelMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &openai.UploadBatchFileRequest{}
+			tt.build(r)
+			err := r.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+const batchResultsJSONL = `{"id":"batch_req_1","custom_id":"req-1","response":{"status_code":200,"request_id":"r1","body":{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}},"error":null}
+{"id":"batch_req_2","custom_id":"req-2","response":{"status_code":400,"request_id":"r2","body":{"error":{"message":"bad request","type":"invalid_request_error"}}},"error":null}
+{"id":"batch_req_3","custom_id":"req-3","response":null,"error":{"code":"batch_expired","message":"expired"}}
+`
+
+func TestParseBatchResults(t *testing.T) {
+	results, err := openai.ParseBatchResults(strings.NewReader(batchResultsJSONL))
+	checks.NoError(t, err, "ParseBatchResults error")
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	chat, err := results[0].DecodeChatCompletion()
+	checks.NoError(t, err, "DecodeChatCompletion error")
+	if chat.Choices[0].Message.Content != "Hi" {
+		t.Errorf("unexpected content %q", chat.Choices[0].Message.Content)
+	}
+
+	_, err = results[1].DecodeChatCompletion()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Errorf("expected APIError with status 400, got %v", err)
+	}
+
+	_, err = results[2].DecodeChatCompletion()
+	var resultErr *openai.BatchResultError
+	if !errors.As(err, &resultErr) || resultErr.Code != "batch_expired" {
+		t.Errorf("expected BatchResultError, got %v", err)
+	}
+}
+
+func TestParseBatchResultsInvalidLine(t *testing.T) {
+	input := "\n" + strings.SplitN(batchResultsJSONL, "\n", 2)[0] + "\n\n{invalid\n"
+	_, err := openai.ParseBatchResults(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("expected an error on line 4, got %v", err)
+	}
+}
+
+func TestRetrieveBatchResultsResponses(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-out/content", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"id":"batch_req_1","custom_id":"req-1","response":{"status_code":200,"request_id":"r1","body":{"id":"resp_1","object":"response","status":"completed","model":"gpt-4o","output":[{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"Hello"},{"type":"output_text","text":" world"}]}],"usage":{"input_tokens":3,"output_tokens":2,"total_tokens":5}}},"error":null}`) //nolint:lll
+	})
+
+	results, err := client.RetrieveBatchResults(context.Background(), "file-out")
+	checks.NoError(t, err, "RetrieveBatchResults error")
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	resp, err := results[0].DecodeResponse()
+	checks.NoError(t, err, "DecodeResponse error")
+	if resp.OutputText() != "Hello world" {
+		t.Errorf("unexpected output text %q", resp.OutputText())
+	}
+	if resp.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected total tokens %d", resp.Usage.TotalTokens)
+	}
+}
+
 func handleBatchEndpoint(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		_, _ = fmt.Fprintln(w, `{
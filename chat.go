@@ -82,14 +82,39 @@ type ChatMessageImageURL struct {
 type ChatMessagePartType string
 
 const (
-	ChatMessagePartTypeText     ChatMessagePartType = "text"
-	ChatMessagePartTypeImageURL ChatMessagePartType = "image_url"
+	ChatMessagePartTypeText       ChatMessagePartType = "text"
+	ChatMessagePartTypeImageURL   ChatMessagePartType = "image_url"
+	ChatMessagePartTypeInputAudio ChatMessagePartType = "input_audio"
+	ChatMessagePartTypeFile       ChatMessagePartType = "file"
 )
 
+type InputAudioFormat string
+
+const (
+	InputAudioFormatWAV InputAudioFormat = "wav"
+	InputAudioFormatMP3 InputAudioFormat = "mp3"
+)
+
+// ChatMessageInputAudio is audio sent to the model, encoded in base64.
+type ChatMessageInputAudio struct {
+	Data   string           `json:"data"`
+	Format InputAudioFormat `json:"format"`
+}
+
+// ChatMessageFile is a file sent to the model, either an uploaded file referred to by FileID,
+// or inline FileData as a base64 data URL along with its Filename.
+type ChatMessageFile struct {
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
 type ChatMessagePart struct {
-	Type     ChatMessagePartType  `json:"type,omitempty"`
-	Text     string               `json:"text,omitempty"`
-	ImageURL *ChatMessageImageURL `json:"image_url,omitempty"`
+	Type       ChatMessagePartType    `json:"type,omitempty"`
+	Text       string                 `json:"text,omitempty"`
+	ImageURL   *ChatMessageImageURL   `json:"image_url,omitempty"`
+	InputAudio *ChatMessageInputAudio `json:"input_audio,omitempty"`
+	File       *ChatMessageFile       `json:"file,omitempty"`
 }
 
 type ChatCompletionMessage struct {
@@ -117,6 +142,10 @@ type ChatCompletionMessage struct {
 
 	// For Role=tool prompts this should be set to the ID given in the assistant's prior request to call a tool.
 	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Audio is the audio response of the model when the audio modality is requested.
+	// Requests refer to it by ID: its data, transcript and expiry are not sent.
+	Audio *ChatCompletionAudio `json:"audio,omitempty"`
 }
 
 func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
@@ -125,29 +154,31 @@ func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
 	}
 	if len(m.MultiContent) > 0 {
 		msg := struct {
-			Role             string            `json:"role"`
-			Content          string            `json:"-"`
-			Refusal          string            `json:"refusal,omitempty"`
-			MultiContent     []ChatMessagePart `json:"content,omitempty"`
-			Name             string            `json:"name,omitempty"`
-			ReasoningContent string            `json:"reasoning_content,omitempty"`
-			FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-			ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-			ToolCallID       string            `json:"tool_call_id,omitempty"`
+			Role             string               `json:"role"`
+			Content          string               `json:"-"`
+			Refusal          string               `json:"refusal,omitempty"`
+			MultiContent     []ChatMessagePart    `json:"content,omitempty"`
+			Name             string               `json:"name,omitempty"`
+			ReasoningContent string               `json:"reasoning_content,omitempty"`
+			FunctionCall     *FunctionCall        `json:"function_call,omitempty"`
+			ToolCalls        []ToolCall           `json:"tool_calls,omitempty"`
+			ToolCallID       string               `json:"tool_call_id,omitempty"`
+			Audio            *ChatCompletionAudio `json:"audio,omitempty"`
 		}(m)
 		return json.Marshal(msg)
 	}
 
 	msg := struct {
-		Role             string            `json:"role"`
-		Content          string            `json:"content,omitempty"`
-		Refusal          string            `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart `json:"-"`
-		Name             string            `json:"name,omitempty"`
-		ReasoningContent string            `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-		ToolCallID       string            `json:"tool_call_id,omitempty"`
+		Role             string               `json:"role"`
+		Content          string               `json:"content,omitempty"`
+		Refusal          string               `json:"refusal,omitempty"`
+		MultiContent     []ChatMessagePart    `json:"-"`
+		Name             string               `json:"name,omitempty"`
+		ReasoningContent string               `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall        `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall           `json:"tool_calls,omitempty"`
+		ToolCallID       string               `json:"tool_call_id,omitempty"`
+		Audio            *ChatCompletionAudio `json:"audio,omitempty"`
 	}(m)
 	return json.Marshal(msg)
 }
@@ -158,11 +189,12 @@ func (m *ChatCompletionMessage) UnmarshalJSON(bs []byte) error {
 		Content          string `json:"content"`
 		Refusal          string `json:"refusal,omitempty"`
 		MultiContent     []ChatMessagePart
-		Name             string        `json:"name,omitempty"`
-		ReasoningContent string        `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall    `json:"tool_calls,omitempty"`
-		ToolCallID       string        `json:"tool_call_id,omitempty"`
+		Name             string               `json:"name,omitempty"`
+		ReasoningContent string               `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall        `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall           `json:"tool_calls,omitempty"`
+		ToolCallID       string               `json:"tool_call_id,omitempty"`
+		Audio            *ChatCompletionAudio `json:"audio,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(bs, &msg); err == nil {
@@ -172,13 +204,14 @@ func (m *ChatCompletionMessage) UnmarshalJSON(bs []byte) error {
 	multiMsg := struct {
 		Role             string `json:"role"`
 		Content          string
-		Refusal          string            `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart `json:"content"`
-		Name             string            `json:"name,omitempty"`
-		ReasoningContent string            `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-		ToolCallID       string            `json:"tool_call_id,omitempty"`
+		Refusal          string               `json:"refusal,omitempty"`
+		MultiContent     []ChatMessagePart    `json:"content"`
+		Name             string               `json:"name,omitempty"`
+		ReasoningContent string               `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall        `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall           `json:"tool_calls,omitempty"`
+		ToolCallID       string               `json:"tool_call_id,omitempty"`
+		Audio            *ChatCompletionAudio `json:"audio,omitempty"`
 	}{}
 	if err := json.Unmarshal(bs, &multiMsg); err != nil {
 		return err
@@ -275,12 +308,48 @@ type ChatCompletionRequest struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 	// Configuration for a predicted output.
 	Prediction *Prediction `json:"prediction,omitempty"`
+	// Modalities are the output types of the model, ["text"] by default. Audio output is
+	// requested with ["text", "audio"] and configured with Audio.
+	Modalities []ChatCompletionModality `json:"modalities,omitempty"`
+	// Audio configures the audio output. It is required when Modalities include audio.
+	Audio *ChatCompletionAudioOptions `json:"audio,omitempty"`
 	// ChatTemplateKwargs provides a way to add non-standard parameters to the request body.
 	// Additional kwargs to pass to the template renderer. Will be accessible by the chat template.
 	// Such as think mode for qwen3. "chat_template_kwargs": {"enable_thinking": false}
 	// https://qwen.readthedocs.io/en/latest/deployment/vllm.html#thinking-non-thinking-modes
 	ChatTemplateKwargs map[string]any `json:"chat_template_kwargs,omitempty"`
-	ExtraBody          map[string]any `json:"extra_body,omitempty"`
+	// ExtraBody are non-standard top-level fields merged into the request body,
+	// replacing the fields of the same name.
+	ExtraBody map[string]any `json:"-"`
+}
+
+func (r ChatCompletionRequest) MarshalJSON() ([]byte, error) {
+	type chatCompletionRequest ChatCompletionRequest
+	r.Messages = audioReferences(r.Messages)
+	body, err := json.Marshal(chatCompletionRequest(r))
+	if err != nil || len(r.ExtraBody) == 0 {
+		return body, err
+	}
+	return mergeJSONFields(body, r.ExtraBody)
+}
+
+// audioReferences returns messages with their audio replaced by a reference to its ID,
+// so that replies appended to a conversation do not send their audio back.
+func audioReferences(messages []ChatCompletionMessage) []ChatCompletionMessage {
+	var referenced []ChatCompletionMessage
+	for i, message := range messages {
+		if message.Audio == nil || *message.Audio == (ChatCompletionAudio{ID: message.Audio.ID}) {
+			continue
+		}
+		if referenced == nil {
+			referenced = append([]ChatCompletionMessage(nil), messages...)
+		}
+		referenced[i].Audio = &ChatCompletionAudio{ID: message.Audio.ID}
+	}
+	if referenced == nil {
+		return messages
+	}
+	return referenced
 }
 
 type StreamOptions struct {
@@ -396,6 +465,8 @@ type ChatCompletionResponse struct {
 	Usage               Usage                  `json:"usage"`
 	SystemFingerprint   string                 `json:"system_fingerprint"`
 	PromptFilterResults []PromptFilterResult   `json:"prompt_filter_results,omitempty"`
+	// Metadata is the metadata of a stored chat completion.
+	Metadata map[string]string `json:"metadata,omitempty"`
 
 	httpHeader
 }
@@ -404,6 +475,7 @@ type ChatCompletionResponse struct {
 func (c *Client) CreateChatCompletion(
 	ctx context.Context,
 	request ChatCompletionRequest,
+	opts ...RequestOption,
 ) (response ChatCompletionResponse, err error) {
 	if request.Stream {
 		err = ErrChatCompletionStreamNotSupported
@@ -426,6 +498,7 @@ func (c *Client) CreateChatCompletion(
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
 		withBody(request),
+		withOptions(opts),
 	)
 	if err != nil {
 		return
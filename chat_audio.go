@@ -0,0 +1,141 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ChatCompletionModality string
+
+const (
+	ChatCompletionModalityText  ChatCompletionModality = "text"
+	ChatCompletionModalityAudio ChatCompletionModality = "audio"
+)
+
+// ChatCompletionAudioFormat is the format of the audio output of a chat completion.
+// Streamed audio must be pcm16: 24kHz mono 16-bit little-endian samples, as SpeechPCMFormat.
+type ChatCompletionAudioFormat string
+
+const (
+	ChatCompletionAudioFormatWAV   ChatCompletionAudioFormat = "wav"
+	ChatCompletionAudioFormatAAC   ChatCompletionAudioFormat = "aac"
+	ChatCompletionAudioFormatMP3   ChatCompletionAudioFormat = "mp3"
+	ChatCompletionAudioFormatFLAC  ChatCompletionAudioFormat = "flac"
+	ChatCompletionAudioFormatOpus  ChatCompletionAudioFormat = "opus"
+	ChatCompletionAudioFormatPCM16 ChatCompletionAudioFormat = "pcm16"
+)
+
+var (
+	ErrChatCompletionAudioEmpty = errors.New("chat completion audio has no data")
+	ErrInputAudioFormatUnknown  = errors.New("input audio must be a WAV or MP3 file")
+)
+
+// ChatCompletionAudioOptions configures the audio output of a chat completion.
+type ChatCompletionAudioOptions struct {
+	Voice  SpeechVoice               `json:"voice"`
+	Format ChatCompletionAudioFormat `json:"format"`
+}
+
+// ChatCompletionAudio is the audio response of a chat completion. In streams, every
+// delta carries a part of Data and Transcript, and the ID and ExpiresAt are set once.
+type ChatCompletionAudio struct {
+	ID string `json:"id"`
+	// Data is the audio encoded in base64, in the format of the request.
+	Data       string `json:"data,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+	// ExpiresAt is the Unix time after which the audio can no longer be referred to by ID.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+// Decode returns the bytes of the audio.
+func (a ChatCompletionAudio) Decode() ([]byte, error) {
+	if a.Data == "" {
+		return nil, ErrChatCompletionAudioEmpty
+	}
+	return base64.StdEncoding.DecodeString(a.Data)
+}
+
+// ChatCompletionStreamAudio accumulates the audio deltas of a chat completion stream.
+type ChatCompletionStreamAudio struct {
+	ID         string
+	Transcript string
+	ExpiresAt  int64
+	// Data is the decoded audio received so far.
+	Data []byte
+}
+
+// Add appends an audio delta. Deltas without audio are ignored, so the Audio of
+// every ChatCompletionStreamChoiceDelta can be added as it is received.
+func (a *ChatCompletionStreamAudio) Add(delta *ChatCompletionAudio) error {
+	if delta == nil {
+		return nil
+	}
+	if delta.ID != "" {
+		a.ID = delta.ID
+	}
+	if delta.ExpiresAt != 0 {
+		a.ExpiresAt = delta.ExpiresAt
+	}
+	a.Transcript += delta.Transcript
+	if delta.Data == "" {
+		return nil
+	}
+	data, err := delta.Decode()
+	if err != nil {
+		return err
+	}
+	a.Data = append(a.Data, data...)
+	return nil
+}
+
+// WAV returns the accumulated pcm16 audio as a WAV file.
+func (a *ChatCompletionStreamAudio) WAV() []byte {
+	return append(SpeechPCMFormat.WAVHeader(int64(len(a.Data))), a.Data...)
+}
+
+// DetectInputAudioFormat returns the format of audio from its content, or an empty
+// string if it is neither a WAV nor an MP3 file.
+func DetectInputAudioFormat(data []byte) InputAudioFormat {
+	if len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")) {
+		return InputAudioFormatWAV
+	}
+	if bytes.HasPrefix(data, []byte("ID3")) {
+		return InputAudioFormatMP3
+	}
+	if _, ok := parseMP3FrameHeader(data); ok {
+		return InputAudioFormatMP3
+	}
+	return ""
+}
+
+// InputAudioFromBytes returns the input audio of a chat message part holding a WAV or MP3 file.
+func InputAudioFromBytes(data []byte) (*ChatMessageInputAudio, error) {
+	format := DetectInputAudioFormat(data)
+	if format == "" {
+		return nil, ErrInputAudioFormatUnknown
+	}
+	return &ChatMessageInputAudio{Data: base64.StdEncoding.EncodeToString(data), Format: format}, nil
+}
+
+// InputAudioFromFile reads a local WAV or MP3 file and returns it as the input audio of a chat message part.
+// The format is detected from the content, or from the extension if the content is not recognized.
+func InputAudioFromFile(path string) (*ChatMessageInputAudio, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	audio, err := InputAudioFromBytes(data)
+	if !errors.Is(err, ErrInputAudioFormatUnknown) {
+		return audio, err
+	}
+
+	switch format := InputAudioFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")); format {
+	case InputAudioFormatWAV, InputAudioFormatMP3:
+		return &ChatMessageInputAudio{Data: base64.StdEncoding.EncodeToString(data), Format: format}, nil
+	}
+	return nil, err
+}
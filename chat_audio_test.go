@@ -0,0 +1,148 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestInputAudioFromFile(t *testing.T) {
+	dir := t.TempDir()
+	wav := append(openai.SpeechPCMFormat.WAVHeader(2), 1, 0)
+	files := map[string][]byte{
+		"speech.wav":  wav,
+		"speech.data": wav,
+		"music.mp3":   testMP3Frame(0),
+		"tagged":      []byte("ID3\x04\x00\x00\x00\x00\x00\x00"),
+		"raw.mp3":     []byte("not detected"),
+		"notes.txt":   []byte("not audio"),
+	}
+	for name, data := range files {
+		checks.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600), "WriteFile error")
+	}
+
+	for name, format := range map[string]openai.InputAudioFormat{
+		"speech.wav":  openai.InputAudioFormatWAV,
+		"speech.data": openai.InputAudioFormatWAV,
+		"music.mp3":   openai.InputAudioFormatMP3,
+		"tagged":      openai.InputAudioFormatMP3,
+		"raw.mp3":     openai.InputAudioFormatMP3,
+	} {
+		audio, err := openai.InputAudioFromFile(filepath.Join(dir, name))
+		checks.NoError(t, err, "InputAudioFromFile error")
+		if audio.Format != format || audio.Data != base64.StdEncoding.EncodeToString(files[name]) {
+			t.Errorf("unexpected input audio of %s: %s", name, audio.Format)
+		}
+	}
+
+	_, err := openai.InputAudioFromFile(filepath.Join(dir, "notes.txt"))
+	checks.ErrorIs(t, err, openai.ErrInputAudioFormatUnknown, "text is not audio")
+	_, err = openai.InputAudioFromBytes([]byte("RIFF\x00\x00\x00\x00WEBP"))
+	checks.ErrorIs(t, err, openai.ErrInputAudioFormatUnknown, "WEBP is not audio")
+}
+
+func TestChatCompletionsAudio(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		//nolint:lll
+		expected := `{"audio":{"format":"wav","voice":"alloy"},"messages":[{"content":[{"input_audio":{"data":"AQI=","format":"mp3"},"type":"input_audio"}],"role":"user"},{"audio":{"id":"audio_1"},"role":"assistant"}],"modalities":["text","audio"],"model":"gpt-4o-audio-preview"}`
+		if body, _ := json.Marshal(request); string(body) != expected {
+			t.Errorf("unexpected request %s", body)
+		}
+
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":null,`+
+			`"audio":{"id":"audio_2","data":"AwQ=","transcript":"Hello","expires_at":1729018505}}}]}`)
+	})
+
+	response, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:      "gpt-4o-audio-preview",
+		Modalities: []openai.ChatCompletionModality{openai.ChatCompletionModalityText, openai.ChatCompletionModalityAudio},
+		Audio:      &openai.ChatCompletionAudioOptions{Voice: openai.VoiceAlloy, Format: openai.ChatCompletionAudioFormatWAV},
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{{
+				Type:       openai.ChatMessagePartTypeInputAudio,
+				InputAudio: &openai.ChatMessageInputAudio{Data: "AQI=", Format: openai.InputAudioFormatMP3},
+			}}},
+			{Role: openai.ChatMessageRoleAssistant, Audio: &openai.ChatCompletionAudio{
+				ID:         "audio_1",
+				Data:       "AwQ=",
+				Transcript: "Hi",
+				ExpiresAt:  1729018505,
+			}},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	audio := response.Choices[0].Message.Audio
+	if audio == nil || audio.ID != "audio_2" || audio.Transcript != "Hello" || audio.ExpiresAt != 1729018505 {
+		t.Fatalf("unexpected audio %+v", audio)
+	}
+	data, err := audio.Decode()
+	checks.NoError(t, err, "Decode error")
+	if !bytes.Equal(data, []byte{3, 4}) {
+		t.Errorf("unexpected audio data %v", data)
+	}
+	_, err = openai.ChatCompletionAudio{ID: "audio_2"}.Decode()
+	checks.ErrorIs(t, err, openai.ErrChatCompletionAudioEmpty, "an audio reference has no data")
+}
+
+func TestCreateChatCompletionStreamAudio(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{
+			`{"role":"assistant","audio":{"id":"audio_1","transcript":"Hel"}}`,
+			`{"audio":{"data":"AQA=","transcript":"lo"}}`,
+			`{"audio":{"data":"AgA="}}`,
+			`{"audio":{"expires_at":1729018505}}`,
+			`{}`,
+		} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:      "gpt-4o-audio-preview",
+		Modalities: []openai.ChatCompletionModality{openai.ChatCompletionModalityText, openai.ChatCompletionModalityAudio},
+		Audio: &openai.ChatCompletionAudioOptions{
+			Voice:  openai.VoiceAlloy,
+			Format: openai.ChatCompletionAudioFormatPCM16,
+		},
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Say hello"}},
+		Stream:   true,
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var audio openai.ChatCompletionStreamAudio
+	for {
+		response, recvErr := stream.Recv()
+		if recvErr != nil {
+			break
+		}
+		checks.NoError(t, audio.Add(response.Choices[0].Delta.Audio), "Add error")
+	}
+
+	if audio.ID != "audio_1" || audio.Transcript != "Hello" || audio.ExpiresAt != 1729018505 {
+		t.Fatalf("unexpected audio %+v", audio)
+	}
+	expected := append(openai.SpeechPCMFormat.WAVHeader(4), 1, 0, 2, 0)
+	if !bytes.Equal(audio.WAV(), expected) {
+		t.Errorf("unexpected WAV %v", audio.WAV())
+	}
+	checks.HasError(t, audio.Add(&openai.ChatCompletionAudio{Data: "not base64"}), "invalid data")
+}
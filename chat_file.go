@@ -0,0 +1,47 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+const pdfMIMEType = "application/pdf"
+
+var ErrChatFileNotPDF = errors.New("file is not a PDF document")
+
+// ChatFileFromID returns the file of a chat message part referring to an uploaded file.
+func ChatFileFromID(fileID string) *ChatMessageFile {
+	return &ChatMessageFile{FileID: fileID}
+}
+
+// ChatFileFromBytes returns the file of a chat message part holding data inline as a data URL.
+// The MIME type is derived from the extension of filename.
+func ChatFileFromBytes(filename string, data []byte) *ChatMessageFile {
+	mimeType := mime.TypeByExtension(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return &ChatMessageFile{
+		FileData: "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+		Filename: filename,
+	}
+}
+
+// ChatFileFromPDF reads a local PDF document and returns it inline as the file of a chat message part.
+func ChatFileFromPDF(path string) (*ChatMessageFile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, ErrChatFileNotPDF
+	}
+	return &ChatMessageFile{
+		FileData: "data:" + pdfMIMEType + ";base64," + base64.StdEncoding.EncodeToString(data),
+		Filename: filepath.Base(path),
+	}, nil
+}
@@ -0,0 +1,45 @@
+package openai_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestChatFileFromPDF(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-1.7\n%%EOF\n")
+	path := filepath.Join(dir, "report.pdf")
+	checks.NoError(t, os.WriteFile(path, pdf, 0o600), "WriteFile error")
+
+	file, err := openai.ChatFileFromPDF(path)
+	checks.NoError(t, err, "ChatFileFromPDF error")
+	if file.Filename != "report.pdf" || file.FileID != "" ||
+		file.FileData != "data:application/pdf;base64,"+base64.StdEncoding.EncodeToString(pdf) {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	notPDF := filepath.Join(dir, "notes.pdf")
+	checks.NoError(t, os.WriteFile(notPDF, []byte("notes"), 0o600), "WriteFile error")
+	_, err = openai.ChatFileFromPDF(notPDF)
+	checks.ErrorIs(t, err, openai.ErrChatFileNotPDF, "the content must be a PDF document")
+	_, err = openai.ChatFileFromPDF(filepath.Join(dir, "missing.pdf"))
+	checks.HasError(t, err, "missing file")
+}
+
+func TestChatFileFromBytes(t *testing.T) {
+	file := openai.ChatFileFromBytes("data.bin.unknownext", []byte{1})
+	if file.FileData != "data:application/octet-stream;base64,AQ==" || file.Filename != "data.bin.unknownext" {
+		t.Errorf("unexpected file %+v", file)
+	}
+	if file = openai.ChatFileFromBytes("contract.pdf", []byte{1}); file.FileData != "data:application/pdf;base64,AQ==" {
+		t.Errorf("unexpected file %+v", file)
+	}
+	if file = openai.ChatFileFromID("file-abc123"); file.FileID != "file-abc123" || file.FileData != "" {
+		t.Errorf("unexpected file %+v", file)
+	}
+}
@@ -0,0 +1,309 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ChatCompletionList is a page of the chat completions stored with ChatCompletionRequest.Store.
+type ChatCompletionList struct {
+	Object  string                   `json:"object"`
+	Data    []ChatCompletionResponse `json:"data"`
+	FirstID string                   `json:"first_id"`
+	LastID  string                   `json:"last_id"`
+	HasMore bool                     `json:"has_more"`
+
+	httpHeader
+}
+
+// ChatCompletionStoredMessage is a message of the request of a stored chat completion.
+type ChatCompletionStoredMessage struct {
+	ID string `json:"id"`
+	ChatCompletionMessage
+}
+
+func (m ChatCompletionStoredMessage) MarshalJSON() ([]byte, error) {
+	message, err := m.ChatCompletionMessage.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(message, &fields); err != nil {
+		return nil, err
+	}
+	if fields["id"], err = json.Marshal(m.ID); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func (m *ChatCompletionStoredMessage) UnmarshalJSON(bs []byte) error {
+	var message struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(bs, &message); err != nil {
+		return err
+	}
+	m.ID = message.ID
+	return m.ChatCompletionMessage.UnmarshalJSON(bs)
+}
+
+// ChatCompletionMessageList is a page of the messages of a stored chat completion.
+type ChatCompletionMessageList struct {
+	Object  string                        `json:"object"`
+	Data    []ChatCompletionStoredMessage `json:"data"`
+	FirstID string                        `json:"first_id"`
+	LastID  string                        `json:"last_id"`
+	HasMore bool                          `json:"has_more"`
+
+	httpHeader
+}
+
+type ChatCompletionDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+
+	httpHeader
+}
+
+type listChatCompletionsParameters struct {
+	model    *string
+	metadata map[string]string
+	after    *string
+	limit    *int
+	order    *string
+
+	requestOptions []RequestOption
+}
+
+type ListChatCompletionsParameter func(*listChatCompletionsParameters)
+
+// ListChatCompletionsWithRequestOptions sets the options of the request listing the chat completions.
+func ListChatCompletionsWithRequestOptions(opts ...RequestOption) ListChatCompletionsParameter {
+	return func(args *listChatCompletionsParameters) {
+		args.requestOptions = append(args.requestOptions, opts...)
+	}
+}
+
+// ListChatCompletionsWithModel only returns chat completions generated by the given model.
+func ListChatCompletionsWithModel(model string) ListChatCompletionsParameter {
+	return func(args *listChatCompletionsParameters) {
+		args.model = &model
+	}
+}
+
+// ListChatCompletionsWithMetadata filters chat completions by a metadata key and value.
+func ListChatCompletionsWithMetadata(key, value string) ListChatCompletionsParameter {
+	return func(args *listChatCompletionsParameters) {
+		if args.metadata == nil {
+			args.metadata = make(map[string]string)
+		}
+		args.metadata[key] = value
+	}
+}
+
+// ListChatCompletionsWithAfter is the cursor for pagination, the ID of the last chat completion of the previous page.
+func ListChatCompletionsWithAfter(after string) ListChatCompletionsParameter {
+	return func(args *listChatCompletionsParameters) {
+		args.after = &after
+	}
+}
+
+// ListChatCompletionsWithLimit limits the number of chat completions returned, 20 by default.
+func ListChatCompletionsWithLimit(limit int) ListChatCompletionsParameter {
+	return func(args *listChatCompletionsParameters) {
+		args.limit = &limit
+	}
+}
+
+// ListChatCompletionsWithOrder sorts chat completions by creation time, "asc" or "desc".
+func ListChatCompletionsWithOrder(order string) ListChatCompletionsParameter {
+	return func(args *listChatCompletionsParameters) {
+		args.order = &order
+	}
+}
+
+// ListChatCompletions lists the stored chat completions.
+// Use ChatCompletionList.HasMore and ListChatCompletionsWithAfter(ChatCompletionList.LastID)
+// to page through all chat completions.
+func (c *Client) ListChatCompletions(
+	ctx context.Context,
+	setters ...ListChatCompletionsParameter,
+) (response ChatCompletionList, err error) {
+	parameters := &listChatCompletionsParameters{}
+	for _, setter := range setters {
+		setter(parameters)
+	}
+
+	urlValues := url.Values{}
+	if parameters.model != nil {
+		urlValues.Add("model", *parameters.model)
+	}
+	for key, value := range parameters.metadata {
+		urlValues.Add(fmt.Sprintf("metadata[%s]", key), value)
+	}
+	if parameters.after != nil {
+		urlValues.Add("after", *parameters.after)
+	}
+	if parameters.limit != nil {
+		urlValues.Add("limit", fmt.Sprintf("%d", *parameters.limit))
+	}
+	if parameters.order != nil {
+		urlValues.Add("order", *parameters.order)
+	}
+
+	encodedValues := ""
+	if len(urlValues) > 0 {
+		encodedValues = "?" + urlValues.Encode()
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(chatCompletionsSuffix+encodedValues),
+		withOptions(parameters.requestOptions),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// GetChatCompletion retrieves a stored chat completion.
+func (c *Client) GetChatCompletion(
+	ctx context.Context,
+	completionID string,
+	opts ...RequestOption,
+) (response ChatCompletionResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(chatCompletionsSuffix+"/"+completionID), withOptions(opts))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// UpdateChatCompletion replaces the metadata of a stored chat completion.
+func (c *Client) UpdateChatCompletion(
+	ctx context.Context,
+	completionID string,
+	metadata map[string]string,
+	opts ...RequestOption,
+) (response ChatCompletionResponse, err error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(chatCompletionsSuffix+"/"+completionID),
+		withBody(map[string]any{"metadata": metadata}),
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// DeleteChatCompletion deletes a stored chat completion.
+func (c *Client) DeleteChatCompletion(
+	ctx context.Context,
+	completionID string,
+	opts ...RequestOption,
+) (response ChatCompletionDeleteResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(chatCompletionsSuffix+"/"+completionID), withOptions(opts))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+type listChatCompletionMessagesParameters struct {
+	after *string
+	limit *int
+	order *string
+
+	requestOptions []RequestOption
+}
+
+type ListChatCompletionMessagesParameter func(*listChatCompletionMessagesParameters)
+
+// ListChatCompletionMessagesWithRequestOptions sets the options of the request listing the chat completion messages.
+func ListChatCompletionMessagesWithRequestOptions(opts ...RequestOption) ListChatCompletionMessagesParameter {
+	return func(args *listChatCompletionMessagesParameters) {
+		args.requestOptions = append(args.requestOptions, opts...)
+	}
+}
+
+// ListChatCompletionMessagesWithAfter is the cursor for pagination, the ID of the last message of the previous page.
+func ListChatCompletionMessagesWithAfter(after string) ListChatCompletionMessagesParameter {
+	return func(args *listChatCompletionMessagesParameters) {
+		args.after = &after
+	}
+}
+
+// ListChatCompletionMessagesWithLimit limits the number of messages returned, 20 by default.
+func ListChatCompletionMessagesWithLimit(limit int) ListChatCompletionMessagesParameter {
+	return func(args *listChatCompletionMessagesParameters) {
+		args.limit = &limit
+	}
+}
+
+// ListChatCompletionMessagesWithOrder sorts messages by their position, "asc" or "desc".
+func ListChatCompletionMessagesWithOrder(order string) ListChatCompletionMessagesParameter {
+	return func(args *listChatCompletionMessagesParameters) {
+		args.order = &order
+	}
+}
+
+// ListChatCompletionMessages lists the messages of the request of a stored chat completion.
+// Use ChatCompletionMessageList.HasMore and ListChatCompletionMessagesWithAfter(ChatCompletionMessageList.LastID)
+// to page through all messages.
+func (c *Client) ListChatCompletionMessages(
+	ctx context.Context,
+	completionID string,
+	setters ...ListChatCompletionMessagesParameter,
+) (response ChatCompletionMessageList, err error) {
+	parameters := &listChatCompletionMessagesParameters{}
+	for _, setter := range setters {
+		setter(parameters)
+	}
+
+	urlValues := url.Values{}
+	if parameters.after != nil {
+		urlValues.Add("after", *parameters.after)
+	}
+	if parameters.limit != nil {
+		urlValues.Add("limit", fmt.Sprintf("%d", *parameters.limit))
+	}
+	if parameters.order != nil {
+		urlValues.Add("order", *parameters.order)
+	}
+
+	encodedValues := ""
+	if len(urlValues) > 0 {
+		encodedValues = "?" + urlValues.Encode()
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(chatCompletionsSuffix+"/"+completionID+"/messages"+encodedValues),
+		withOptions(parameters.requestOptions),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
@@ -0,0 +1,113 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestListChatCompletions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		query := r.URL.Query()
+		if query.Get("model") != openai.GPT4o || query.Get("metadata[project]") != "audit" ||
+			query.Get("after") != "chatcmpl-1" || query.Get("limit") != "2" || query.Get("order") != "asc" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"chatcmpl-2","object":"chat.completion","model":"gpt-4o",`+
+			`"metadata":{"project":"audit"},"choices":[{"index":0,"message":{"role":"assistant","content":"Hi"}}]}],`+
+			`"first_id":"chatcmpl-2","last_id":"chatcmpl-2","has_more":true}`)
+	})
+
+	list, err := client.ListChatCompletions(context.Background(),
+		openai.ListChatCompletionsWithModel(openai.GPT4o),
+		openai.ListChatCompletionsWithMetadata("project", "audit"),
+		openai.ListChatCompletionsWithAfter("chatcmpl-1"),
+		openai.ListChatCompletionsWithLimit(2),
+		openai.ListChatCompletionsWithOrder("asc"),
+	)
+	checks.NoError(t, err, "ListChatCompletions error")
+	if len(list.Data) != 1 || !list.HasMore || list.LastID != "chatcmpl-2" ||
+		list.Data[0].Metadata["project"] != "audit" || list.Data[0].Choices[0].Message.Content != "Hi" {
+		t.Errorf("unexpected list %+v", list)
+	}
+}
+
+func TestStoredChatCompletion(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions/chatcmpl-1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","metadata":{"project":"audit"}}`)
+		case http.MethodPost:
+			var request map[string]map[string]string
+			checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+			response := openai.ChatCompletionResponse{ID: "chatcmpl-1", Metadata: request["metadata"]}
+			checks.NoError(t, json.NewEncoder(w).Encode(response), "Encode error")
+		case http.MethodDelete:
+			fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion.deleted","deleted":true}`)
+		}
+	})
+	ctx := context.Background()
+
+	completion, err := client.GetChatCompletion(ctx, "chatcmpl-1")
+	checks.NoError(t, err, "GetChatCompletion error")
+	if completion.ID != "chatcmpl-1" || completion.Metadata["project"] != "audit" {
+		t.Errorf("unexpected completion %+v", completion)
+	}
+
+	completion, err = client.UpdateChatCompletion(ctx, "chatcmpl-1", map[string]string{"reviewed": "true"})
+	checks.NoError(t, err, "UpdateChatCompletion error")
+	if completion.Metadata["reviewed"] != "true" {
+		t.Errorf("unexpected metadata %+v", completion.Metadata)
+	}
+
+	deleted, err := client.DeleteChatCompletion(ctx, "chatcmpl-1")
+	checks.NoError(t, err, "DeleteChatCompletion error")
+	if !deleted.Deleted || deleted.ID != "chatcmpl-1" {
+		t.Errorf("unexpected delete response %+v", deleted)
+	}
+}
+
+func TestListChatCompletionMessages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions/chatcmpl-1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "after=msg-0&limit=2&order=desc" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"msg-1","role":"user","content":"Hello"},`+
+			`{"id":"msg-2","role":"user","content":[{"type":"text","text":"Look"}]}],`+
+			`"first_id":"msg-1","last_id":"msg-2","has_more":false}`)
+	})
+
+	list, err := client.ListChatCompletionMessages(context.Background(), "chatcmpl-1",
+		openai.ListChatCompletionMessagesWithAfter("msg-0"),
+		openai.ListChatCompletionMessagesWithLimit(2),
+		openai.ListChatCompletionMessagesWithOrder("desc"),
+	)
+	checks.NoError(t, err, "ListChatCompletionMessages error")
+	if len(list.Data) != 2 || list.HasMore || list.FirstID != "msg-1" {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	if list.Data[0].ID != "msg-1" || list.Data[0].Content != "Hello" ||
+		list.Data[1].ID != "msg-2" || list.Data[1].MultiContent[0].Text != "Look" {
+		t.Errorf("unexpected messages %+v", list.Data)
+	}
+
+	encoded, err := json.Marshal(list.Data[0])
+	checks.NoError(t, err, "Marshal error")
+	if string(encoded) != `{"content":"Hello","id":"msg-1","role":"user"}` {
+		t.Errorf("unexpected encoding %s", encoded)
+	}
+}
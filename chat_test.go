@@ -756,6 +756,35 @@ func TestMultipartChatMessageSerialization(t *testing.T) {
 	}
 }
 
+func TestFileChatMessageSerialization(t *testing.T) {
+	jsonText := `{"role":"user","content":[{"type":"text","text":"Summarize"},` +
+		`{"type":"file","file":{"file_id":"file-abc123"}},` +
+		`{"type":"file","file":{"file_data":"data:application/pdf;base64,JVBERi0=","filename":"contract.pdf"}}]}`
+
+	var msg openai.ChatCompletionMessage
+	if err := json.Unmarshal([]byte(jsonText), &msg); err != nil {
+		t.Fatalf("Expected no error: %s", err)
+	}
+	if len(msg.MultiContent) != 3 {
+		t.Fatalf("unexpected parts %+v", msg.MultiContent)
+	}
+	parts := msg.MultiContent
+	if parts[1].Type != openai.ChatMessagePartTypeFile || parts[1].File == nil || parts[1].File.FileID != "file-abc123" {
+		t.Errorf("invalid file part: %+v", parts[1])
+	}
+	if parts[2].File == nil || parts[2].File.Filename != "contract.pdf" || parts[2].File.FileID != "" {
+		t.Errorf("invalid inline file part: %+v", parts[2])
+	}
+
+	s, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Expected no error: %s", err)
+	}
+	if string(s) != jsonText {
+		t.Fatalf("invalid message: %s", string(s))
+	}
+}
+
 // handleChatCompletionEndpoint Handles the ChatGPT completion endpoint by the test server.
 func handleChatCompletionEndpoint(w http.ResponseWriter, r *http.Request) {
 	var err error
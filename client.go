@@ -4,15 +4,19 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
 )
 
+var ErrExtraBodyNotJSON = errors.New("extra body fields can only be merged into a JSON object body")
+
 // Client is OpenAI GPT-3 API client.
 type Client struct {
 	config ClientConfig
@@ -71,49 +75,287 @@ func NewOrgClient(authToken, org string) *Client {
 	return NewClientWithConfig(config)
 }
 
-type requestOptions struct {
-	body   any
-	header http.Header
+// RequestOptions are the options of a single request, set by RequestOption.
+type RequestOptions struct {
+	// Header is added to the headers of the request, replacing the headers set by the client.
+	Header http.Header
+	// Query is added to the query parameters of the request.
+	Query url.Values
+	// ExtraBody are top-level fields merged into the JSON body of the request,
+	// replacing the fields of the same name.
+	ExtraBody map[string]any
+	// BaseURL replaces the BaseURL of the client configuration.
+	BaseURL string
+	// Timeout limits the duration of the request, including reading the response.
+	// For streams, it limits the duration of the whole stream.
+	Timeout time.Duration
+
+	body any
+}
+
+// RequestOption sets an option of a single request. Every Client method accepts request options;
+// methods which take list parameters accept them through their WithRequestOptions parameter.
+// Options can also be written outside of this package by setting the fields of RequestOptions.
+type RequestOption func(*RequestOptions)
+
+// WithHeader sets a header of the request.
+func WithHeader(key, value string) RequestOption {
+	return func(args *RequestOptions) {
+		args.Header.Set(key, value)
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header, so that a retried request is only processed once.
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader("Idempotency-Key", key)
+}
+
+// WithQueryParam adds a query parameter to the request.
+func WithQueryParam(key, value string) RequestOption {
+	return func(args *RequestOptions) {
+		args.Query.Add(key, value)
+	}
+}
+
+// WithExtraBody merges fields into the JSON body of the request.
+func WithExtraBody(fields map[string]any) RequestOption {
+	return func(args *RequestOptions) {
+		if args.ExtraBody == nil {
+			args.ExtraBody = make(map[string]any, len(fields))
+		}
+		for key, value := range fields {
+			args.ExtraBody[key] = value
+		}
+	}
 }
 
-type requestOption func(*requestOptions)
+// WithBaseURL sends the request to baseURL instead of the BaseURL of the client configuration.
+func WithBaseURL(baseURL string) RequestOption {
+	return func(args *RequestOptions) {
+		args.BaseURL = baseURL
+	}
+}
+
+// WithTimeout limits the duration of the request.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(args *RequestOptions) {
+		args.Timeout = timeout
+	}
+}
+
+// withOptions applies the options given to a Client method.
+func withOptions(opts []RequestOption) RequestOption {
+	return func(args *RequestOptions) {
+		for _, opt := range opts {
+			opt(args)
+		}
+	}
+}
 
-func withBody(body any) requestOption {
-	return func(args *requestOptions) {
+func withBody(body any) RequestOption {
+	return func(args *RequestOptions) {
 		args.body = body
 	}
 }
 
-func withContentType(contentType string) requestOption {
-	return func(args *requestOptions) {
-		args.header.Set("Content-Type", contentType)
+func withContentType(contentType string) RequestOption {
+	return func(args *RequestOptions) {
+		args.Header.Set("Content-Type", contentType)
 	}
 }
 
-func withBetaAssistantVersion(version string) requestOption {
-	return func(args *requestOptions) {
-		args.header.Set("OpenAI-Beta", fmt.Sprintf("assistants=%s", version))
+func withBetaAssistantVersion(version string) RequestOption {
+	return func(args *RequestOptions) {
+		args.Header.Set("OpenAI-Beta", fmt.Sprintf("assistants=%s", version))
 	}
 }
 
-func (c *Client) newRequest(ctx context.Context, method, url string, setters ...requestOption) (*http.Request, error) {
-	// Default Options
-	args := &requestOptions{
-		body:   nil,
-		header: make(http.Header),
+func newRequestOptions(setters []RequestOption) *RequestOptions {
+	args := &RequestOptions{
+		Header: make(http.Header),
+		Query:  make(url.Values),
 	}
 	for _, setter := range setters {
 		setter(args)
 	}
-	req, err := c.requestBuilder.Build(ctx, method, url, args.body, args.header)
+	return args
+}
+
+// newRequest creates a request with the options applied. When the options set a timeout,
+// the request holds its timer until it is released, so a request that is built must be sent
+// with sendRequest, sendRequestRaw or sendRequestStream, or released with releaseRequest.
+// The timeout is released here if the request cannot be built.
+func (c *Client) newRequest(
+	ctx context.Context,
+	method, requestURL string,
+	setters ...RequestOption,
+) (*http.Request, error) {
+	args := newRequestOptions(setters)
+	requestURL, err := args.url(c.config.BaseURL, requestURL)
+	if err != nil {
+		return nil, err
+	}
+	body, err := args.mergedBody()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := args.context(ctx)
+	req, err := c.requestBuilder.Build(ctx, method, requestURL, body, args.Header.Clone())
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 	c.setCommonHeaders(req)
+	// The headers of the options replace the headers of the client.
+	for key, values := range args.Header {
+		req.Header[key] = values
+	}
+	return req, nil
+}
+
+// url returns requestURL with the base URL and query parameters of the options.
+func (o *RequestOptions) url(clientBaseURL, requestURL string) (string, error) {
+	if o.BaseURL != "" {
+		clientBaseURL = strings.TrimRight(clientBaseURL, "/")
+		if strings.HasPrefix(requestURL, clientBaseURL) {
+			requestURL = strings.TrimRight(o.BaseURL, "/") + strings.TrimPrefix(requestURL, clientBaseURL)
+		}
+	}
+	return o.withQuery(requestURL)
+}
+
+// withQuery returns requestURL with the query parameters of the options appended to its own,
+// which are kept as they are.
+func (o *RequestOptions) withQuery(requestURL string) (string, error) {
+	if len(o.Query) == 0 {
+		return requestURL, nil
+	}
+
+	parsed, err := url.Parse(requestURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.RawQuery != "" {
+		parsed.RawQuery += "&"
+	}
+	parsed.RawQuery += o.Query.Encode()
+	return parsed.String(), nil
+}
+
+// mergedBody returns the body of the request with the ExtraBody fields merged into it.
+func (o *RequestOptions) mergedBody() (any, error) {
+	if len(o.ExtraBody) == 0 {
+		return o.body, nil
+	}
+	if _, ok := o.body.(io.Reader); ok {
+		return nil, ErrExtraBodyNotJSON
+	}
+	body := []byte("{}")
+	if o.body != nil {
+		var err error
+		if body, err = json.Marshal(o.body); err != nil {
+			return nil, err
+		}
+	}
+	merged, err := mergeJSONFields(body, o.ExtraBody)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(merged), nil
+}
+
+// mergeJSONFields sets the top-level fields of a JSON object.
+func mergeJSONFields(object []byte, fields map[string]any) ([]byte, error) {
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(object, &merged); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExtraBodyNotJSON, err)
+	}
+	if merged == nil {
+		return nil, ErrExtraBodyNotJSON
+	}
+	for key, value := range fields {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = encoded
+	}
+	return json.Marshal(merged)
+}
+
+type requestCancelKey struct{}
+
+// context returns the context of the request, with the timeout of the options.
+// The returned function releases the timeout and must be called if the request
+// is not built; once the request is built, it is released by releaseRequest.
+func (o *RequestOptions) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	return context.WithValue(ctx, requestCancelKey{}, cancel), cancel
+}
+
+// releaseRequest releases the timeout of a request once its response is read.
+func releaseRequest(req *http.Request) {
+	if cancel, ok := req.Context().Value(requestCancelKey{}).(context.CancelFunc); ok {
+		cancel()
+	}
+}
+
+// releaseOnClose releases the timeout of a request when the response body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	req *http.Request
+}
+
+func (r releaseOnClose) Close() error {
+	defer releaseRequest(r.req)
+	return r.ReadCloser.Close()
+}
+
+// UploadProgressFunc is called while a multipart request body is being sent.
+// written is the number of body bytes sent so far; total is the size of the
+// body, or -1 if it is not known in advance.
+type UploadProgressFunc func(written, total int64)
+
+// newMultipartRequest creates a request whose multipart body is streamed by build
+// instead of being buffered in memory. Form building errors are returned here,
+// before anything is sent. The body can be produced again through GetBody when
+// all of its file sources are seekable or re-opened by build.
+// Like the requests of newRequest, the request must be sent once it is built.
+func (c *Client) newMultipartRequest(
+	ctx context.Context,
+	method, url string,
+	build utils.FormBodyFunc,
+	progress UploadProgressFunc,
+	setters ...RequestOption,
+) (*http.Request, error) {
+	body, err := utils.NewMultipartBody(c.createFormBuilder, build, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := body.Reader()
+	setters = append(setters, withBody(reader), withContentType(body.FormDataContentType()))
+	req, err := c.newRequest(ctx, method, url, setters...)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	if length := body.ContentLength(); length >= 0 {
+		req.ContentLength = length
+	}
+	if body.Replayable() {
+		req.GetBody = body.GetBody
+	}
 	return req, nil
 }
 
 func (c *Client) sendRequest(req *http.Request, v Response) error {
+	defer releaseRequest(req)
 	req.Header.Set("Accept", "application/json")
 
 	// Check whether Content-Type is already set, Upload Files API requires
@@ -144,32 +386,40 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 func (c *Client) sendRequestRaw(req *http.Request) (response RawResponse, err error) {
 	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body should be closed by outer function
 	if err != nil {
+		releaseRequest(req)
 		return
 	}
 
 	if isFailureStatusCode(resp) {
+		defer releaseRequest(req)
 		err = c.handleErrorResp(resp)
 		return
 	}
 
 	response.SetHeader(resp.Header)
-	response.ReadCloser = resp.Body
+	response.ReadCloser = releaseOnClose{ReadCloser: resp.Body, req: req}
 	return
 }
 
 func sendRequestStream[T streamable](client *Client, req *http.Request) (*streamReader[T], error) {
-	req.Header.Set("Content-Type", "application/json")
+	// Streamed image edits are sent as multipart/form-data.
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
 	resp, err := client.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
 	if err != nil {
+		releaseRequest(req)
 		return new(streamReader[T]), err
 	}
 	if isFailureStatusCode(resp) {
+		defer releaseRequest(req)
 		return new(streamReader[T]), client.handleErrorResp(resp)
 	}
+	resp.Body = releaseOnClose{ReadCloser: resp.Body, req: req}
 	return &streamReader[T]{
 		emptyMessagesLimit: client.config.EmptyMessagesLimit,
 		reader:             bufio.NewReader(resp.Body),
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"gitlab.forensix.cn/ai/service/go-openai/internal/test"
 	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
@@ -22,6 +23,29 @@ func (*failingRequestBuilder) Build(_ context.Context, _, _ string, _ any, _ htt
 	return nil, errTestRequestBuilderFailed
 }
 
+// contextRequestBuilder fails after recording the context of the request.
+type contextRequestBuilder struct {
+	ctx context.Context
+}
+
+func (b *contextRequestBuilder) Build(ctx context.Context, _, _ string, _ any, _ http.Header) (*http.Request, error) {
+	b.ctx = ctx
+	return nil, errTestRequestBuilderFailed
+}
+
+func TestNewRequestReleasesTimeoutOnError(t *testing.T) {
+	client := NewClientWithConfig(DefaultConfig(test.GetTestToken()))
+	builder := &contextRequestBuilder{}
+	client.requestBuilder = builder
+
+	_, err := client.newRequest(context.Background(), http.MethodGet, client.fullURL("/models"),
+		WithTimeout(time.Hour))
+	checks.ErrorIs(t, err, errTestRequestBuilderFailed, "newRequest should return the builder error")
+	if !errors.Is(builder.ctx.Err(), context.Canceled) {
+		t.Errorf("the timeout of a request which is not built should be released, got %v", builder.ctx.Err())
+	}
+}
+
 func TestClient(t *testing.T) {
 	const mockToken = "mock token"
 	client := NewClient(mockToken)
@@ -317,6 +341,24 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"ListFineTuningJobEvents", func() (any, error) {
 			return client.ListFineTuningJobEvents(ctx, "")
 		}},
+		{"ListFineTuningJobs", func() (any, error) {
+			return client.ListFineTuningJobs(ctx)
+		}},
+		{"ListFineTuningJobCheckpoints", func() (any, error) {
+			return client.ListFineTuningJobCheckpoints(ctx, "")
+		}},
+		{"PauseFineTuningJob", func() (any, error) {
+			return client.PauseFineTuningJob(ctx, "")
+		}},
+		{"ResumeFineTuningJob", func() (any, error) {
+			return client.ResumeFineTuningJob(ctx, "")
+		}},
+		{"RetrieveFineTuningResults", func() (any, error) {
+			return client.RetrieveFineTuningResults(ctx, "")
+		}},
+		{"WatchFineTuningJob", func() (any, error) {
+			return client.WatchFineTuningJob(ctx, "", FineTuningJobWatchOptions{}).Recv()
+		}},
 		{"Moderations", func() (any, error) {
 			return client.Moderations(ctx, ModerationRequest{})
 		}},
@@ -329,6 +371,9 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"CreateImage", func() (any, error) {
 			return client.CreateImage(ctx, ImageRequest{})
 		}},
+		{"CreateImageStream", func() (any, error) {
+			return client.CreateImageStream(ctx, ImageRequest{Model: CreateImageModelGptImage1})
+		}},
 		{"CreateFileBytes", func() (any, error) {
 			return client.CreateFileBytes(ctx, FileBytesRequest{})
 		}},
@@ -344,6 +389,21 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"ListFiles", func() (any, error) {
 			return client.ListFiles(ctx)
 		}},
+		{"ListChatCompletions", func() (any, error) {
+			return client.ListChatCompletions(ctx)
+		}},
+		{"GetChatCompletion", func() (any, error) {
+			return client.GetChatCompletion(ctx, "")
+		}},
+		{"UpdateChatCompletion", func() (any, error) {
+			return client.UpdateChatCompletion(ctx, "", nil)
+		}},
+		{"DeleteChatCompletion", func() (any, error) {
+			return client.DeleteChatCompletion(ctx, "")
+		}},
+		{"ListChatCompletionMessages", func() (any, error) {
+			return client.ListChatCompletionMessages(ctx, "")
+		}},
 		{"ListEngines", func() (any, error) {
 			return client.ListEngines(ctx)
 		}},
@@ -449,6 +509,16 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"CreateSpeech", func() (any, error) {
 			return client.CreateSpeech(ctx, CreateSpeechRequest{Model: TTSModel1, Voice: VoiceAlloy})
 		}},
+		{"CreateSpeechStream", func() (any, error) {
+			return client.CreateSpeechStream(ctx, CreateSpeechRequest{Model: TTSModelGPT4oMini, Voice: VoiceAlloy})
+		}},
+		{"CreateLongSpeech", func() (any, error) {
+			request := LongSpeechRequest{CreateSpeechRequest: CreateSpeechRequest{Input: "Hi."}}
+			return client.CreateLongSpeech(ctx, request, io.Discard)
+		}},
+		{"StreamSpeech", func() (any, error) {
+			return client.StreamSpeech(ctx, CreateSpeechRequest{Model: TTSModel1, Voice: VoiceAlloy}, io.Discard)
+		}},
 		{"CreateBatch", func() (any, error) {
 			return client.CreateBatch(ctx, CreateBatchRequest{})
 		}},
@@ -0,0 +1,496 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultConversationReserveTokens = 4096
+	defaultConversationSummaryModel  = GPT4oMini
+	defaultConversationSummaryTarget = 0.5
+	defaultConversationSummaryPrompt = "Summarize the following conversation between a user and an assistant. " +
+		"Keep the facts, decisions, names and open questions needed to continue the conversation. " +
+		"Reply with the summary only."
+
+	// ConversationSummaryName is the name of the system message holding the summary of the earlier turns
+	// of a conversation. It is not pinned, so that it is summarized again with the next dropped turns.
+	ConversationSummaryName = "conversation_summary"
+)
+
+var (
+	ErrConversationBudgetUnknown = errors.New("the context window of the model is unknown, set ConversationConfig.MaxTokens") //nolint:lll
+	ErrConversationOverBudget    = errors.New("the conversation does not fit in its token budget")
+	ErrConversationSummaryEmpty  = errors.New("the summary of the conversation is empty")
+	ErrConversationSummaryClient = errors.New("the summary strategy has no client")
+)
+
+// ModelContextWindows lists the context window, in tokens, of the chat models.
+// Add models to it, or set ConversationConfig.MaxTokens, for models which are not listed.
+var ModelContextWindows = map[string]int{
+	GPT3Dot5Turbo:        16385,
+	GPT3Dot5Turbo0125:    16385,
+	GPT3Dot5Turbo1106:    16385,
+	GPT3Dot5Turbo16K:     16385,
+	GPT4:                 8192,
+	GPT40613:             8192,
+	GPT432K:              32768,
+	GPT4Turbo:            128000,
+	GPT4Turbo20240409:    128000,
+	GPT4TurboPreview:     128000,
+	GPT4o:                128000,
+	GPT4o20240513:        128000,
+	GPT4o20240806:        128000,
+	GPT4o20241120:        128000,
+	GPT4oLatest:          128000,
+	GPT4oMini:            128000,
+	GPT4oMini20240718:    128000,
+	GPT4Dot1:             1047576,
+	GPT4Dot120250414:     1047576,
+	GPT4Dot1Mini:         1047576,
+	GPT4Dot1Mini20250414: 1047576,
+	GPT4Dot1Nano:         1047576,
+	GPT4Dot1Nano20250414: 1047576,
+	GPT4Dot5Preview:      128000,
+	O1:                   200000,
+	O120241217:           200000,
+	O1Mini:               128000,
+	O3:                   200000,
+	O320250416:           200000,
+	O3Mini:               200000,
+	O3Mini20250131:       200000,
+	O4Mini:               200000,
+	O4Mini20250416:       200000,
+}
+
+// ConversationBudget is the number of tokens the history of a conversation may use.
+type ConversationBudget struct {
+	MaxTokens int
+	// CountTokens counts the tokens of a piece of text.
+	CountTokens func(string) int
+	// RequestOptions are the options of the request the history is fitted for.
+	// Strategies which send requests of their own, such as SummaryStrategy, send them with these options.
+	RequestOptions []RequestOption
+}
+
+// Tokens estimates the number of tokens of messages sent as the history of a request.
+func (b ConversationBudget) Tokens(messages []ChatCompletionMessage) int {
+	countTokens := b.CountTokens
+	if countTokens == nil {
+		countTokens = ApproximateTokenCount
+	}
+	tokens := tokensPerReply
+	for _, message := range messages {
+		tokens += estimateMessageTokens(message, countTokens)
+	}
+	return tokens
+}
+
+// Fits reports whether messages fit in the budget.
+func (b ConversationBudget) Fits(messages []ChatCompletionMessage) bool {
+	return b.Tokens(messages) <= b.MaxTokens
+}
+
+// ConversationStrategy shortens a history which does not fit in the token budget of a conversation.
+type ConversationStrategy interface {
+	Fit(ctx context.Context, history []ChatCompletionMessage, budget ConversationBudget) ([]ChatCompletionMessage, error)
+}
+
+// ConversationConfig configures a Conversation.
+type ConversationConfig struct {
+	// ID identifies the conversation in Store.
+	ID string
+	// Store persists the history after every change. Without a store, the history only lives in the Conversation.
+	Store ConversationStore
+	// Model is used by the requests which set no model. Its context window sets the token budget.
+	Model string
+	// MaxTokens is the token budget of the history and the tools of a request.
+	// Defaults to the context window of the model, looked up in ModelContextWindows, less the reserved tokens.
+	MaxTokens int
+	// ReserveTokens are kept free in the context window for the reply. Defaults to the MaxCompletionTokens
+	// or MaxTokens of the request, or to 4096.
+	ReserveTokens int
+	// Strategy shortens the history when it does not fit in the budget. Defaults to a SlidingWindowStrategy.
+	Strategy ConversationStrategy
+	// CountTokens counts the tokens of a piece of text. Defaults to ApproximateTokenCount;
+	// plug in a real tokenizer for exact numbers.
+	CountTokens func(string) int
+}
+
+// Conversation owns the history of a chat and keeps it within the token budget of the model.
+// Messages dropped or summarized to fit the budget are removed from the history.
+// It is not safe for concurrent use.
+type Conversation struct {
+	client  *Client
+	config  ConversationConfig
+	history []ChatCompletionMessage
+}
+
+// NewConversation returns a conversation, with the history loaded from config.Store if there is one.
+func NewConversation(ctx context.Context, client *Client, config ConversationConfig) (*Conversation, error) {
+	if config.Strategy == nil {
+		config.Strategy = SlidingWindowStrategy{}
+	}
+	if config.CountTokens == nil {
+		config.CountTokens = ApproximateTokenCount
+	}
+
+	conversation := &Conversation{client: client, config: config}
+	if config.Store != nil {
+		history, err := config.Store.Load(ctx, config.ID)
+		if err != nil {
+			return nil, err
+		}
+		conversation.history = history
+	}
+	return conversation, nil
+}
+
+// Messages returns a copy of the history.
+func (c *Conversation) Messages() []ChatCompletionMessage {
+	return append([]ChatCompletionMessage(nil), c.history...)
+}
+
+// Add appends messages to the history, such as the system prompt or the results of tool calls.
+func (c *Conversation) Add(ctx context.Context, messages ...ChatCompletionMessage) error {
+	c.history = append(c.history, messages...)
+	return c.save(ctx)
+}
+
+// Clear removes every message of the history.
+func (c *Conversation) Clear(ctx context.Context) error {
+	c.history = nil
+	if c.config.Store == nil {
+		return nil
+	}
+	return c.config.Store.Delete(ctx, c.config.ID)
+}
+
+// Fit shortens the history with the strategy of the conversation until it fits in the budget of request,
+// and returns it. The options are those of request, passed on to the strategy.
+func (c *Conversation) Fit(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) ([]ChatCompletionMessage, error) {
+	budget, err := c.budget(request)
+	if err != nil {
+		return nil, err
+	}
+	budget.RequestOptions = opts
+	if budget.Fits(c.history) {
+		return c.Messages(), nil
+	}
+
+	history, err := c.config.Strategy.Fit(ctx, c.Messages(), budget)
+	if err != nil {
+		return nil, err
+	}
+	if !budget.Fits(history) {
+		return nil, fmt.Errorf("%w: %d tokens for a budget of %d", ErrConversationOverBudget,
+			budget.Tokens(history), budget.MaxTokens)
+	}
+	c.history = history
+	if err = c.save(ctx); err != nil {
+		return nil, err
+	}
+	return c.Messages(), nil
+}
+
+// CreateChatCompletion appends the messages of request to the history, sends the fitted history
+// and appends the reply to it. If it fails, the history is restored, so that the request can be retried.
+func (c *Conversation) CreateChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (response ChatCompletionResponse, err error) {
+	if request.Model == "" {
+		request.Model = c.config.Model
+	}
+	previous := c.Messages()
+	defer func() {
+		if err == nil {
+			return
+		}
+		c.history = previous
+		if saveErr := c.save(ctx); saveErr != nil {
+			err = errors.Join(err, saveErr)
+		}
+	}()
+
+	if err = c.Add(ctx, request.Messages...); err != nil {
+		return
+	}
+
+	request.Messages, err = c.Fit(ctx, request, opts...)
+	if err != nil {
+		return
+	}
+
+	response, err = c.client.CreateChatCompletion(ctx, request, opts...)
+	if err != nil || len(response.Choices) == 0 {
+		return
+	}
+	err = c.Add(ctx, response.Choices[0].Message)
+	return
+}
+
+// budget returns the token budget of the history of request, less the tokens of its tools.
+func (c *Conversation) budget(request ChatCompletionRequest) (ConversationBudget, error) {
+	budget := ConversationBudget{MaxTokens: c.config.MaxTokens, CountTokens: c.config.CountTokens}
+	if budget.MaxTokens <= 0 {
+		model := request.Model
+		if model == "" {
+			model = c.config.Model
+		}
+		window, ok := ModelContextWindows[model]
+		if !ok {
+			return budget, fmt.Errorf("%w: %q", ErrConversationBudgetUnknown, model)
+		}
+
+		reserve := c.config.ReserveTokens
+		switch {
+		case request.MaxCompletionTokens > 0:
+			reserve = request.MaxCompletionTokens
+		case request.MaxTokens > 0:
+			reserve = request.MaxTokens
+		case reserve <= 0:
+			reserve = defaultConversationReserveTokens
+		}
+		budget.MaxTokens = window - reserve
+	}
+
+	if len(request.Tools) > 0 {
+		if data, err := json.Marshal(request.Tools); err == nil {
+			budget.MaxTokens -= c.config.CountTokens(string(data))
+		}
+	}
+	return budget, nil
+}
+
+func (c *Conversation) save(ctx context.Context) error {
+	if c.config.Store == nil {
+		return nil
+	}
+	return c.config.Store.Save(ctx, c.config.ID, c.history)
+}
+
+// SlidingWindowStrategy drops the oldest messages of the history until it fits in the budget.
+// An assistant message with tool calls is dropped together with the results of its calls,
+// and the latest turn is never dropped.
+type SlidingWindowStrategy struct {
+	// PinnedRoles are the roles of the messages which are never dropped. Defaults to system and developer;
+	// set it to an empty, non-nil slice to pin no message.
+	PinnedRoles []string
+}
+
+func (s SlidingWindowStrategy) Fit(
+	ctx context.Context,
+	history []ChatCompletionMessage,
+	budget ConversationBudget,
+) ([]ChatCompletionMessage, error) {
+	kept, _ := s.split(history, budget)
+	return kept, nil
+}
+
+// split returns the messages kept in the budget and the messages dropped, both in the order of history.
+func (s SlidingWindowStrategy) split(
+	history []ChatCompletionMessage,
+	budget ConversationBudget,
+) (kept, dropped []ChatCompletionMessage) {
+	turns := conversationTurns(history)
+	keep := make([]bool, len(turns))
+	for i, turn := range turns {
+		keep[i] = s.pinned(turn[0])
+	}
+	if len(turns) > 0 {
+		keep[len(turns)-1] = true
+	}
+
+	// The turns are added from the latest while they fit.
+	tokens := budget.Tokens(joinConversationTurns(turns, keep))
+	full := false
+	for i := len(turns) - 2; i >= 0; i-- {
+		if keep[i] {
+			continue
+		}
+		turnTokens := budget.Tokens(turns[i]) - tokensPerReply
+		if full || tokens+turnTokens > budget.MaxTokens {
+			full = true
+			dropped = append(append([]ChatCompletionMessage(nil), turns[i]...), dropped...)
+			continue
+		}
+		keep[i] = true
+		tokens += turnTokens
+	}
+	return joinConversationTurns(turns, keep), dropped
+}
+
+func (s SlidingWindowStrategy) pinned(message ChatCompletionMessage) bool {
+	if message.Name == ConversationSummaryName {
+		return false
+	}
+	roles := s.PinnedRoles
+	if roles == nil {
+		roles = []string{ChatMessageRoleSystem, ChatMessageRoleDeveloper}
+	}
+	for _, role := range roles {
+		if message.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// conversationTurns groups the history into the units which are kept or dropped together:
+// an assistant message with its tool or function results, or any other single message.
+func conversationTurns(history []ChatCompletionMessage) [][]ChatCompletionMessage {
+	var turns [][]ChatCompletionMessage
+	for i, message := range history {
+		isResult := message.Role == ChatMessageRoleTool || message.Role == ChatMessageRoleFunction
+		if isResult && len(turns) > 0 {
+			last := turns[len(turns)-1]
+			if call := last[0]; len(call.ToolCalls) > 0 || call.FunctionCall != nil {
+				turns[len(turns)-1] = history[i-len(last) : i+1]
+				continue
+			}
+		}
+		turns = append(turns, history[i:i+1])
+	}
+	return turns
+}
+
+func joinConversationTurns(turns [][]ChatCompletionMessage, keep []bool) []ChatCompletionMessage {
+	var messages []ChatCompletionMessage
+	for i, turn := range turns {
+		if keep[i] {
+			messages = append(messages, turn...)
+		}
+	}
+	return messages
+}
+
+// SummaryStrategy replaces the oldest messages of the history by a summary written by the model.
+// The summary is a system message named ConversationSummaryName, placed before the kept messages.
+type SummaryStrategy struct {
+	// Client writes the summary. It is required.
+	Client *Client
+	// Model writes the summary. Defaults to gpt-4o-mini.
+	Model string
+	// Prompt is the instruction given to the model. Defaults to a generic summary prompt.
+	Prompt string
+	// Target is the share of the budget left to the kept messages, so that the history is not
+	// summarized again on the next turn. Defaults to 0.5.
+	Target float64
+	// Window selects the kept messages; the messages it drops are summarized.
+	Window SlidingWindowStrategy
+}
+
+func (s SummaryStrategy) Fit(
+	ctx context.Context,
+	history []ChatCompletionMessage,
+	budget ConversationBudget,
+) ([]ChatCompletionMessage, error) {
+	target := s.Target
+	if target <= 0 || target > 1 {
+		target = defaultConversationSummaryTarget
+	}
+	kept, dropped := s.Window.split(history, ConversationBudget{
+		MaxTokens:   int(float64(budget.MaxTokens) * target),
+		CountTokens: budget.CountTokens,
+	})
+	if len(dropped) == 0 {
+		return kept, nil
+	}
+
+	summary, err := s.summarize(ctx, dropped, budget.RequestOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// The dropped messages are all older than the first kept message which is not pinned.
+	messages := make([]ChatCompletionMessage, 0, len(kept)+1)
+	inserted := false
+	for _, message := range kept {
+		if !inserted && !s.Window.pinned(message) {
+			messages = append(messages, summary)
+			inserted = true
+		}
+		messages = append(messages, message)
+	}
+	if !inserted {
+		messages = append(messages, summary)
+	}
+	return messages, nil
+}
+
+func (s SummaryStrategy) summarize(
+	ctx context.Context,
+	messages []ChatCompletionMessage,
+	opts []RequestOption,
+) (summary ChatCompletionMessage, err error) {
+	if s.Client == nil {
+		err = ErrConversationSummaryClient
+		return
+	}
+	model := s.Model
+	if model == "" {
+		model = defaultConversationSummaryModel
+	}
+	prompt := s.Prompt
+	if prompt == "" {
+		prompt = defaultConversationSummaryPrompt
+	}
+
+	response, err := s.Client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model: model,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: prompt},
+			{Role: ChatMessageRoleUser, Content: conversationTranscript(messages)},
+		},
+	}, opts...)
+	if err != nil {
+		return
+	}
+	if len(response.Choices) == 0 || strings.TrimSpace(response.Choices[0].Message.Content) == "" {
+		err = ErrConversationSummaryEmpty
+		return
+	}
+
+	summary = ChatCompletionMessage{
+		Role:    ChatMessageRoleSystem,
+		Name:    ConversationSummaryName,
+		Content: "Summary of the earlier conversation:\n" + strings.TrimSpace(response.Choices[0].Message.Content),
+	}
+	return
+}
+
+// conversationTranscript writes messages as plain text, one message per paragraph.
+func conversationTranscript(messages []ChatCompletionMessage) string {
+	var b strings.Builder
+	for _, message := range messages {
+		role := message.Role
+		if message.Name == ConversationSummaryName {
+			role = "earlier summary"
+		}
+		b.WriteString(role)
+		b.WriteString(": ")
+		b.WriteString(message.Content)
+		for _, part := range message.MultiContent {
+			if part.Type == ChatMessagePartTypeText {
+				b.WriteString(part.Text)
+			}
+		}
+		for _, call := range message.ToolCalls {
+			fmt.Fprintf(&b, "[call %s(%s)]", call.Function.Name, call.Function.Arguments)
+		}
+		if message.FunctionCall != nil {
+			fmt.Fprintf(&b, "[call %s(%s)]", message.FunctionCall.Name, message.FunctionCall.Arguments)
+		}
+		b.WriteString("\n\n")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,130 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrConversationIDInvalid = errors.New("conversation ID must be a non-empty file name")
+
+// ConversationStore persists the history of conversations by ID.
+type ConversationStore interface {
+	// Load returns the history of the conversation, or no message if it was never saved.
+	Load(ctx context.Context, id string) ([]ChatCompletionMessage, error)
+	// Save replaces the history of the conversation.
+	Save(ctx context.Context, id string, messages []ChatCompletionMessage) error
+	// Delete removes the history of the conversation. Deleting an unknown conversation is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryConversationStore keeps the history of conversations in memory. It is safe for concurrent use.
+type MemoryConversationStore struct {
+	mu            sync.Mutex
+	conversations map[string][]ChatCompletionMessage
+}
+
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{conversations: map[string][]ChatCompletionMessage{}}
+}
+
+func (s *MemoryConversationStore) Load(_ context.Context, id string) ([]ChatCompletionMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ChatCompletionMessage(nil), s.conversations[id]...), nil
+}
+
+func (s *MemoryConversationStore) Save(_ context.Context, id string, messages []ChatCompletionMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversations[id] = append([]ChatCompletionMessage(nil), messages...)
+	return nil
+}
+
+func (s *MemoryConversationStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conversations, id)
+	return nil
+}
+
+// FileConversationStore keeps the history of every conversation in a JSON file named after its ID
+// in a directory. Files are replaced atomically, so a crash never leaves a partial history.
+type FileConversationStore struct {
+	dir string
+}
+
+// NewFileConversationStore returns a store writing to dir, which is created on the first save.
+func NewFileConversationStore(dir string) *FileConversationStore {
+	return &FileConversationStore{dir: dir}
+}
+
+func (s *FileConversationStore) Load(_ context.Context, id string) (messages []ChatCompletionMessage, err error) {
+	path, err := s.path(id)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &messages)
+	return
+}
+
+func (s *FileConversationStore) Save(_ context.Context, id string, messages []ChatCompletionMessage) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if messages == nil {
+		messages = []ChatCompletionMessage{}
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(s.dir, "."+id+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (s *FileConversationStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileConversationStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", ErrConversationIDInvalid
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
@@ -0,0 +1,73 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestConversationStores(t *testing.T) {
+	stores := map[string]openai.ConversationStore{
+		"memory": openai.NewMemoryConversationStore(),
+		"file":   openai.NewFileConversationStore(t.TempDir() + "/conversations"),
+	}
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "be brief"},
+		{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: "what is this?"},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://example.com/a.png"}},
+		}},
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+			ID:       "call_1",
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: "lookup", Arguments: `{"q":"a.png"}`},
+		}}},
+		{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "a cat"},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			loaded, err := store.Load(ctx, "unknown")
+			checks.NoError(t, err, "Load error")
+			if len(loaded) != 0 {
+				t.Errorf("expected no message, got %v", loaded)
+			}
+
+			checks.NoError(t, store.Save(ctx, "c1", messages), "Save error")
+			checks.NoError(t, store.Save(ctx, "c2", messages[:1]), "Save error")
+			loaded, err = store.Load(ctx, "c1")
+			checks.NoError(t, err, "Load error")
+			if len(loaded) != len(messages) || loaded[1].MultiContent[1].ImageURL.URL != "https://example.com/a.png" ||
+				loaded[2].ToolCalls[0].Function.Arguments != `{"q":"a.png"}` || loaded[3].ToolCallID != "call_1" {
+				t.Errorf("unexpected history %+v", loaded)
+			}
+
+			checks.NoError(t, store.Delete(ctx, "c1"), "Delete error")
+			checks.NoError(t, store.Delete(ctx, "c1"), "Delete error")
+			loaded, err = store.Load(ctx, "c1")
+			checks.NoError(t, err, "Load error")
+			if len(loaded) != 0 {
+				t.Errorf("expected no message after Delete, got %v", loaded)
+			}
+			loaded, err = store.Load(ctx, "c2")
+			checks.NoError(t, err, "Load error")
+			if len(loaded) != 1 {
+				t.Errorf("unexpected history %+v", loaded)
+			}
+		})
+	}
+}
+
+func TestFileConversationStoreInvalidID(t *testing.T) {
+	store := openai.NewFileConversationStore(t.TempDir())
+	for _, id := range []string{"", "..", "../c1", `a\b`} {
+		err := store.Save(context.Background(), id, nil)
+		if !errors.Is(err, openai.ErrConversationIDInvalid) {
+			t.Errorf("%q: expected ErrConversationIDInvalid, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+// countWords counts one token per word, so that the budgets of the tests are easy to compute:
+// a message costs 3 tokens, plus one for its role and one per word of its content.
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+func conversationHistory() []openai.ChatCompletionMessage {
+	return []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "be brief"},
+		{Role: openai.ChatMessageRoleUser, Content: "one"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "two"},
+		{Role: openai.ChatMessageRoleUser, Content: "three"},
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+			ID:       "call_1",
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: "lookup", Arguments: "{}"},
+		}}},
+		{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "result"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "four"},
+		{Role: openai.ChatMessageRoleUser, Content: "five"},
+	}
+}
+
+func messageContents(messages []openai.ChatCompletionMessage) []string {
+	contents := make([]string, len(messages))
+	for i, message := range messages {
+		contents[i] = message.Role + ":" + message.Content
+	}
+	return contents
+}
+
+func TestSlidingWindowStrategy(t *testing.T) {
+	history := conversationHistory()
+	budget := openai.ConversationBudget{CountTokens: countWords}
+	if tokens := budget.Tokens(history); tokens != 45 {
+		t.Fatalf("expected 45 tokens, got %d", tokens)
+	}
+
+	testCases := []struct {
+		name     string
+		strategy openai.SlidingWindowStrategy
+		budget   int
+		expected []string
+	}{
+		{
+			name:     "tool call kept with its result",
+			budget:   30,
+			expected: []string{"system:be brief", "assistant:", "tool:result", "assistant:four", "user:five"},
+		},
+		{
+			name:     "tool call dropped with its result",
+			budget:   25,
+			expected: []string{"system:be brief", "assistant:four", "user:five"},
+		},
+		{
+			name:     "nothing pinned",
+			strategy: openai.SlidingWindowStrategy{PinnedRoles: []string{}},
+			budget:   20,
+			expected: []string{"assistant:four", "user:five"},
+		},
+		{
+			name:     "latest turn always kept",
+			budget:   1,
+			expected: []string{"system:be brief", "user:five"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			budget.MaxTokens = tc.budget
+			fitted, err := tc.strategy.Fit(context.Background(), history, budget)
+			checks.NoError(t, err, "Fit error")
+			if got := messageContents(fitted); strings.Join(got, "|") != strings.Join(tc.expected, "|") {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+	if got := messageContents(history); len(got) != 8 || got[4] != "assistant:" || got[5] != "tool:result" {
+		t.Errorf("Fit modified the history: %v", got)
+	}
+}
+
+func TestSummaryStrategy(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.Model != openai.GPT4oMini || len(request.Messages) != 2 {
+			t.Fatalf("unexpected summary request %+v", request)
+		}
+		if r.Header.Get("X-Trace-Id") != "trace-1" {
+			t.Errorf("the summary request should be sent with the options of the budget")
+		}
+		transcript := request.Messages[1].Content
+		expected := "user: one\n\nassistant: two\n\nuser: three\n\nassistant: [call lookup({})]\n\ntool: result"
+		if transcript != expected {
+			t.Errorf("unexpected transcript %q", transcript)
+		}
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"The user counted to three."}}]}`)
+	})
+
+	budget := openai.ConversationBudget{MaxTokens: 40, CountTokens: countWords}
+	_, err := openai.SummaryStrategy{}.Fit(context.Background(), conversationHistory(), budget)
+	checks.ErrorIs(t, err, openai.ErrConversationSummaryClient, "Fit should fail without a client")
+
+	strategy := openai.SummaryStrategy{Client: client}
+	budget.RequestOptions = []openai.RequestOption{openai.WithHeader("X-Trace-Id", "trace-1")}
+	fitted, err := strategy.Fit(context.Background(), conversationHistory(), budget)
+	checks.NoError(t, err, "Fit error")
+
+	if len(fitted) != 4 || fitted[0].Content != "be brief" || fitted[2].Content != "four" || fitted[3].Content != "five" {
+		t.Fatalf("unexpected history %v", messageContents(fitted))
+	}
+	summary := fitted[1]
+	if summary.Role != openai.ChatMessageRoleSystem || summary.Name != openai.ConversationSummaryName ||
+		!strings.HasSuffix(summary.Content, "The user counted to three.") {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if !budget.Fits(fitted) {
+		t.Errorf("summarized history does not fit: %d tokens", budget.Tokens(fitted))
+	}
+}
+
+func TestConversationCreateChatCompletion(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	budget := openai.ConversationBudget{MaxTokens: 30, CountTokens: countWords}
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.Model != openai.GPT4o || request.Messages[0].Role != openai.ChatMessageRoleSystem {
+			t.Errorf("unexpected request %+v", request)
+		}
+		if !budget.Fits(request.Messages) {
+			t.Errorf("history over budget: %d tokens", budget.Tokens(request.Messages))
+		}
+		last := request.Messages[len(request.Messages)-1].Content
+		response := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "you said " + last},
+		}}}
+		checks.NoError(t, json.NewEncoder(w).Encode(response), "Encode error")
+	})
+
+	ctx := context.Background()
+	store := openai.NewMemoryConversationStore()
+	config := openai.ConversationConfig{
+		ID:          "conversation-1",
+		Store:       store,
+		Model:       openai.GPT4o,
+		MaxTokens:   budget.MaxTokens,
+		CountTokens: countWords,
+	}
+	conversation, err := openai.NewConversation(ctx, client, config)
+	checks.NoError(t, err, "NewConversation error")
+	checks.NoError(t, conversation.Add(ctx, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: "be brief",
+	}), "Add error")
+
+	for i := 1; i <= 5; i++ {
+		response, sendErr := conversation.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("turn %d", i)}},
+		})
+		checks.NoError(t, sendErr, "CreateChatCompletion error")
+		if expected := fmt.Sprintf("you said turn %d", i); response.Choices[0].Message.Content != expected {
+			t.Errorf("expected %q, got %q", expected, response.Choices[0].Message.Content)
+		}
+	}
+
+	messages := conversation.Messages()
+	if messages[0].Content != "be brief" || messages[len(messages)-1].Content != "you said turn 5" {
+		t.Errorf("unexpected history %v", messageContents(messages))
+	}
+	if len(messages) >= 11 {
+		t.Errorf("history was not shortened: %v", messageContents(messages))
+	}
+
+	reloaded, err := openai.NewConversation(ctx, client, config)
+	checks.NoError(t, err, "NewConversation error")
+	got, expected := messageContents(reloaded.Messages()), messageContents(messages)
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected the stored history %v, got %v", expected, got)
+	}
+
+	checks.NoError(t, reloaded.Clear(ctx), "Clear error")
+	stored, err := store.Load(ctx, config.ID)
+	checks.NoError(t, err, "Load error")
+	if len(stored) != 0 || len(reloaded.Messages()) != 0 {
+		t.Errorf("history was not cleared: %v", stored)
+	}
+}
+
+func TestConversationCreateChatCompletionFailure(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fail := true
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if fail {
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		if len(request.Messages) != 2 {
+			t.Errorf("the failed turn was sent again: %v", messageContents(request.Messages))
+		}
+		response := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hello"},
+		}}}
+		checks.NoError(t, json.NewEncoder(w).Encode(response), "Encode error")
+	})
+
+	ctx := context.Background()
+	store := openai.NewMemoryConversationStore()
+	conversation, err := openai.NewConversation(ctx, client, openai.ConversationConfig{
+		ID:          "conversation-1",
+		Store:       store,
+		Model:       openai.GPT4o,
+		MaxTokens:   100,
+		CountTokens: countWords,
+	})
+	checks.NoError(t, err, "NewConversation error")
+	checks.NoError(t, conversation.Add(ctx, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: "be brief",
+	}), "Add error")
+
+	request := openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+	}
+	_, err = conversation.CreateChatCompletion(ctx, request)
+	checks.HasError(t, err, "CreateChatCompletion should fail")
+	stored, err := store.Load(ctx, "conversation-1")
+	checks.NoError(t, err, "Load error")
+	if len(conversation.Messages()) != 1 || len(stored) != 1 {
+		t.Errorf("the failed turn was kept: %v", messageContents(stored))
+	}
+
+	fail = false
+	_, err = conversation.CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	got := strings.Join(messageContents(conversation.Messages()), "|")
+	if got != "system:be brief|user:hi|assistant:hello" {
+		t.Errorf("unexpected history %s", got)
+	}
+}
+
+func TestConversationBudget(t *testing.T) {
+	ctx := context.Background()
+	client := openai.NewClient("test")
+
+	conversation, err := openai.NewConversation(ctx, client, openai.ConversationConfig{Model: "my-model"})
+	checks.NoError(t, err, "NewConversation error")
+	_, err = conversation.Fit(ctx, openai.ChatCompletionRequest{})
+	if !errors.Is(err, openai.ErrConversationBudgetUnknown) {
+		t.Errorf("expected ErrConversationBudgetUnknown, got %v", err)
+	}
+
+	conversation, err = openai.NewConversation(ctx, client, openai.ConversationConfig{
+		Model:       openai.GPT4,
+		CountTokens: countWords,
+	})
+	checks.NoError(t, err, "NewConversation error")
+	checks.NoError(t, conversation.Add(ctx, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: strings.Repeat("word ", 200),
+	}), "Add error")
+
+	// gpt-4 has a context window of 8192 tokens, of which 8000 are reserved for the reply.
+	_, err = conversation.Fit(ctx, openai.ChatCompletionRequest{MaxCompletionTokens: 8000})
+	if !errors.Is(err, openai.ErrConversationOverBudget) {
+		t.Errorf("expected ErrConversationOverBudget, got %v", err)
+	}
+	messages, err := conversation.Fit(ctx, openai.ChatCompletionRequest{MaxCompletionTokens: 7000})
+	checks.NoError(t, err, "Fit error")
+	if len(messages) != 1 {
+		t.Errorf("unexpected history %v", messageContents(messages))
+	}
+}
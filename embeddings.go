@@ -239,6 +239,7 @@ func (r EmbeddingRequestTokens) Convert() EmbeddingRequest {
 func (c *Client) CreateEmbeddings(
 	ctx context.Context,
 	conv EmbeddingRequestConverter,
+	opts ...RequestOption,
 ) (res EmbeddingResponse, err error) {
 	baseReq := conv.Convert()
 	req, err := c.newRequest(
@@ -246,6 +247,7 @@ func (c *Client) CreateEmbeddings(
 		http.MethodPost,
 		c.fullURL("/embeddings", withModel(string(baseReq.Model))),
 		withBody(baseReq),
+		withOptions(opts),
 	)
 	if err != nil {
 		return
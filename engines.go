@@ -25,8 +25,8 @@ type EnginesList struct {
 
 // ListEngines Lists the currently available engines, and provides basic
 // information about each option such as the owner and availability.
-func (c *Client) ListEngines(ctx context.Context) (engines EnginesList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/engines"))
+func (c *Client) ListEngines(ctx context.Context, opts ...RequestOption) (engines EnginesList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/engines"), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -40,9 +40,10 @@ func (c *Client) ListEngines(ctx context.Context) (engines EnginesList, err erro
 func (c *Client) GetEngine(
 	ctx context.Context,
 	engineID string,
+	opts ...RequestOption,
 ) (engine Engine, err error) {
 	urlSuffix := fmt.Sprintf("/engines/%s", engineID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -10,33 +10,44 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	client := openai.NewClient(os.Getenv("OPENAI_API_KEY"))
 
-	req := openai.ChatCompletionRequest{
+	// The conversation drops the oldest turns once the history no longer fits in the context window.
+	conversation, err := openai.NewConversation(ctx, client, openai.ConversationConfig{
 		Model: openai.GPT3Dot5Turbo,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "you are a helpful chatbot",
-			},
-		},
+	})
+	if err != nil {
+		fmt.Printf("Conversation error: %v\n", err)
+		return
+	}
+	err = conversation.Add(ctx, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: "you are a helpful chatbot",
+	})
+	if err != nil {
+		fmt.Printf("Conversation error: %v\n", err)
+		return
 	}
+
 	fmt.Println("Conversation")
 	fmt.Println("---------------------")
 	fmt.Print("> ")
 	s := bufio.NewScanner(os.Stdin)
 	for s.Scan() {
-		req.Messages = append(req.Messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: s.Text(),
+		resp, err := conversation.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: s.Text(),
+				},
+			},
 		})
-		resp, err := client.CreateChatCompletion(context.Background(), req)
 		if err != nil {
 			fmt.Printf("ChatCompletion error: %v\n", err)
 			continue
 		}
 		fmt.Printf("%s\n\n", resp.Choices[0].Message.Content)
-		req.Messages = append(req.Messages, resp.Choices[0].Message)
 		fmt.Print("> ")
 	}
 }
@@ -3,15 +3,25 @@ package openai
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+
+	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
 )
 
 type FileRequest struct {
 	FileName string `json:"file"`
 	FilePath string `json:"-"`
 	Purpose  string `json:"purpose"`
+	// ExpiresAfter optionally sets an expiration policy for the file.
+	ExpiresAfter *FileExpiresAfter `json:"-"`
+	// Progress is optionally called as the file is uploaded.
+	Progress UploadProgressFunc `json:"-"`
 }
 
 // PurposeType represents the purpose of the file when uploading.
@@ -23,8 +33,41 @@ const (
 	PurposeAssistants       PurposeType = "assistants"
 	PurposeAssistantsOutput PurposeType = "assistants_output"
 	PurposeBatch            PurposeType = "batch"
+	PurposeBatchOutput      PurposeType = "batch_output"
+	PurposeVision           PurposeType = "vision"
+	PurposeUserData         PurposeType = "user_data"
+	PurposeEvals            PurposeType = "evals"
 )
 
+var ErrFileSizeMismatch = errors.New("downloaded file size does not match the file metadata")
+
+// FileExpiresAfter is the expiration policy for an uploaded file.
+// By default, files with purpose=batch expire after 30 days and all other files persist until deleted.
+type FileExpiresAfter struct {
+	// Anchor is the timestamp the expiration is relative to. Only "created_at" is supported.
+	Anchor string `json:"anchor"`
+	// Seconds is the number of seconds after the anchor time that the file expires,
+	// between 3600 (1 hour) and 2592000 (30 days).
+	Seconds int64 `json:"seconds"`
+}
+
+const FileExpiresAfterAnchorCreatedAt = "created_at"
+
+func (e *FileExpiresAfter) writeFields(b utils.FormBuilder) error {
+	if e == nil {
+		return nil
+	}
+	anchor := e.Anchor
+	if anchor == "" {
+		anchor = FileExpiresAfterAnchorCreatedAt
+	}
+	err := b.WriteField("expires_after[anchor]", anchor)
+	if err != nil {
+		return err
+	}
+	return b.WriteField("expires_after[seconds]", strconv.FormatInt(e.Seconds, 10))
+}
+
 // FileBytesRequest represents a file upload request.
 type FileBytesRequest struct {
 	// the name of the uploaded file in OpenAI
@@ -33,6 +76,25 @@ type FileBytesRequest struct {
 	Bytes []byte
 	// the purpose of the file
 	Purpose PurposeType
+	// optional expiration policy of the file
+	ExpiresAfter *FileExpiresAfter
+	// optionally called as the file is uploaded
+	Progress UploadProgressFunc
+}
+
+// FileReaderRequest represents a file upload request from an io.Reader.
+type FileReaderRequest struct {
+	// the name of the uploaded file in OpenAI
+	Name string
+	// the contents of the file. Seekable readers such as *os.File or *bytes.Reader
+	// have a known size and can be re-sent if the request is retried.
+	Reader io.Reader
+	// the purpose of the file
+	Purpose PurposeType
+	// optional expiration policy of the file
+	ExpiresAfter *FileExpiresAfter
+	// optionally called as the file is uploaded
+	Progress UploadProgressFunc
 }
 
 // File struct represents an OpenAPI file.
@@ -45,6 +107,7 @@ type File struct {
 	Status        string `json:"status"`
 	Purpose       string `json:"purpose"`
 	StatusDetails string `json:"status_details"`
+	ExpiresAt     int64  `json:"expires_at,omitempty"`
 
 	httpHeader
 }
@@ -53,32 +116,51 @@ type File struct {
 type FilesList struct {
 	Files []File `json:"data"`
 
+	Object  string `json:"object"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
+
 	httpHeader
 }
 
 // CreateFileBytes uploads bytes directly to OpenAI without requiring a local file.
-func (c *Client) CreateFileBytes(ctx context.Context, request FileBytesRequest) (file File, err error) {
-	var b bytes.Buffer
-	reader := bytes.NewReader(request.Bytes)
-	builder := c.createFormBuilder(&b)
-
-	err = builder.WriteField("purpose", string(request.Purpose))
-	if err != nil {
-		return
-	}
+func (c *Client) CreateFileBytes(
+	ctx context.Context,
+	request FileBytesRequest,
+	opts ...RequestOption,
+) (file File, err error) {
+	return c.CreateFileReader(ctx, FileReaderRequest{
+		Name:         request.Name,
+		Reader:       bytes.NewReader(request.Bytes),
+		Purpose:      request.Purpose,
+		ExpiresAfter: request.ExpiresAfter,
+		Progress:     request.Progress,
+	}, opts...)
+}
 
-	err = builder.CreateFormFileReader("file", reader, request.Name)
+// CreateFileReader uploads the contents of an io.Reader to OpenAI.
+// The body is streamed rather than buffered, so arbitrarily large readers can be uploaded.
+func (c *Client) CreateFileReader(
+	ctx context.Context,
+	request FileReaderRequest,
+	opts ...RequestOption,
+) (file File, err error) {
+	req, err := c.newMultipartRequest(ctx, http.MethodPost, c.fullURL("/files"),
+		request.multipartForm, request.Progress, withOptions(opts))
 	if err != nil {
 		return
 	}
 
-	err = builder.Close()
-	if err != nil {
-		return
-	}
+	err = c.sendRequest(req, &file)
+	return
+}
 
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"),
-		withBody(&b), withContentType(builder.FormDataContentType()))
+// CreateFile uploads a jsonl file to GPT3
+// FilePath must be a local file path.
+func (c *Client) CreateFile(ctx context.Context, request FileRequest, opts ...RequestOption) (file File, err error) {
+	req, err := c.newMultipartRequest(ctx, http.MethodPost, c.fullURL("/files"),
+		request.multipartForm, request.Progress, withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -87,46 +169,54 @@ func (c *Client) CreateFileBytes(ctx context.Context, request FileBytesRequest)
 	return
 }
 
-// CreateFile uploads a jsonl file to GPT3
-// FilePath must be a local file path.
-func (c *Client) CreateFile(ctx context.Context, request FileRequest) (file File, err error) {
-	var b bytes.Buffer
-	builder := c.createFormBuilder(&b)
+func (r FileReaderRequest) multipartForm(b utils.FormBuilder) error {
+	err := b.WriteField("purpose", string(r.Purpose))
+	if err != nil {
+		return err
+	}
 
-	err = builder.WriteField("purpose", request.Purpose)
+	err = r.ExpiresAfter.writeFields(b)
 	if err != nil {
-		return
+		return err
 	}
 
-	fileData, err := os.Open(request.FilePath)
+	err = b.CreateFormFileReader("file", r.Reader, r.Name)
 	if err != nil {
-		return
+		return err
 	}
-	defer fileData.Close()
 
-	err = builder.CreateFormFile("file", fileData)
+	return b.Close()
+}
+
+// multipartForm opens FilePath on every call so the body can be re-sent on retries.
+func (r FileRequest) multipartForm(b utils.FormBuilder) error {
+	err := b.WriteField("purpose", r.Purpose)
 	if err != nil {
-		return
+		return err
 	}
 
-	err = builder.Close()
+	err = r.ExpiresAfter.writeFields(b)
 	if err != nil {
-		return
+		return err
 	}
 
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"),
-		withBody(&b), withContentType(builder.FormDataContentType()))
+	fileData, err := os.Open(r.FilePath)
 	if err != nil {
-		return
+		return err
 	}
+	defer fileData.Close()
 
-	err = c.sendRequest(req, &file)
-	return
+	err = b.CreateFormFile("file", fileData)
+	if err != nil {
+		return err
+	}
+
+	return b.Close()
 }
 
 // DeleteFile deletes an existing file.
-func (c *Client) DeleteFile(ctx context.Context, fileID string) (err error) {
-	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/files/"+fileID))
+func (c *Client) DeleteFile(ctx context.Context, fileID string, opts ...RequestOption) (err error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/files/"+fileID), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -135,10 +225,86 @@ func (c *Client) DeleteFile(ctx context.Context, fileID string) (err error) {
 	return
 }
 
+type listFilesParameters struct {
+	purpose *PurposeType
+	order   *string
+	after   *string
+	limit   *int
+
+	requestOptions []RequestOption
+}
+
+type ListFilesParameter func(*listFilesParameters)
+
+// ListFilesWithRequestOptions sets the options of the request listing the files.
+func ListFilesWithRequestOptions(opts ...RequestOption) ListFilesParameter {
+	return func(args *listFilesParameters) {
+		args.requestOptions = append(args.requestOptions, opts...)
+	}
+}
+
+// ListFilesWithPurpose only returns files with the given purpose.
+func ListFilesWithPurpose(purpose PurposeType) ListFilesParameter {
+	return func(args *listFilesParameters) {
+		args.purpose = &purpose
+	}
+}
+
+// ListFilesWithOrder sorts files by created_at, "asc" or "desc".
+func ListFilesWithOrder(order string) ListFilesParameter {
+	return func(args *listFilesParameters) {
+		args.order = &order
+	}
+}
+
+// ListFilesWithAfter is the cursor for pagination, the ID of the last file of the previous page.
+func ListFilesWithAfter(after string) ListFilesParameter {
+	return func(args *listFilesParameters) {
+		args.after = &after
+	}
+}
+
+// ListFilesWithLimit limits the number of files returned, between 1 and 10,000.
+func ListFilesWithLimit(limit int) ListFilesParameter {
+	return func(args *listFilesParameters) {
+		args.limit = &limit
+	}
+}
+
 // ListFiles Lists the currently available files,
 // and provides basic information about each file such as the file name and purpose.
-func (c *Client) ListFiles(ctx context.Context) (files FilesList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/files"))
+// Use FilesList.HasMore and ListFilesWithAfter(FilesList.LastID) to page through all files.
+func (c *Client) ListFiles(ctx context.Context, setters ...ListFilesParameter) (files FilesList, err error) {
+	parameters := &listFilesParameters{}
+	for _, setter := range setters {
+		setter(parameters)
+	}
+
+	urlValues := url.Values{}
+	if parameters.purpose != nil {
+		urlValues.Add("purpose", string(*parameters.purpose))
+	}
+	if parameters.order != nil {
+		urlValues.Add("order", *parameters.order)
+	}
+	if parameters.after != nil {
+		urlValues.Add("after", *parameters.after)
+	}
+	if parameters.limit != nil {
+		urlValues.Add("limit", fmt.Sprintf("%d", *parameters.limit))
+	}
+
+	encodedValues := ""
+	if len(urlValues) > 0 {
+		encodedValues = "?" + urlValues.Encode()
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL("/files"+encodedValues),
+		withOptions(parameters.requestOptions),
+	)
 	if err != nil {
 		return
 	}
@@ -149,9 +315,9 @@ func (c *Client) ListFiles(ctx context.Context) (files FilesList, err error) {
 
 // GetFile Retrieves a file instance, providing basic information about the file
 // such as the file name and purpose.
-func (c *Client) GetFile(ctx context.Context, fileID string) (file File, err error) {
+func (c *Client) GetFile(ctx context.Context, fileID string, opts ...RequestOption) (file File, err error) {
 	urlSuffix := fmt.Sprintf("/files/%s", fileID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -160,12 +326,46 @@ func (c *Client) GetFile(ctx context.Context, fileID string) (file File, err err
 	return
 }
 
-func (c *Client) GetFileContent(ctx context.Context, fileID string) (content RawResponse, err error) {
+func (c *Client) GetFileContent(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) (content RawResponse, err error) {
 	urlSuffix := fmt.Sprintf("/files/%s/content", fileID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
 
 	return c.sendRequestRaw(req)
 }
+
+// DownloadFile streams the content of a file to w and verifies that the number of
+// bytes written matches the size reported by the file metadata.
+func (c *Client) DownloadFile(
+	ctx context.Context,
+	fileID string,
+	w io.Writer,
+	opts ...RequestOption,
+) (written int64, err error) {
+	file, err := c.GetFile(ctx, fileID, opts...)
+	if err != nil {
+		return
+	}
+
+	content, err := c.GetFileContent(ctx, fileID, opts...)
+	if err != nil {
+		return
+	}
+	defer content.Close()
+
+	written, err = io.Copy(w, content)
+	if err != nil {
+		return
+	}
+
+	if written != int64(file.Bytes) {
+		err = fmt.Errorf("%w: expected %d bytes, got %d", ErrFileSizeMismatch, file.Bytes, written)
+	}
+	return
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,6 +43,54 @@ func TestFileUpload(t *testing.T) {
 	checks.NoError(t, err, "CreateFile error")
 }
 
+func TestFileReaderUpload(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var contentLength int64
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		handleCreateFile(w, r)
+	})
+
+	var written, total int64
+	content := strings.Repeat("{}\n", 4096)
+	file, err := client.CreateFileReader(context.Background(), openai.FileReaderRequest{
+		Name:    "batch.jsonl",
+		Reader:  strings.NewReader(content),
+		Purpose: openai.PurposeBatch,
+		Progress: func(w, t int64) {
+			written, total = w, t
+		},
+	})
+	checks.NoError(t, err, "CreateFileReader error")
+	if file.Bytes != len(content) {
+		t.Errorf("expected %d bytes to be uploaded, got %d", len(content), file.Bytes)
+	}
+	if contentLength <= int64(len(content)) {
+		t.Errorf("expected a known content length, got %d", contentLength)
+	}
+	if written != total || total != contentLength {
+		t.Errorf("unexpected progress %d/%d for content length %d", written, total, contentLength)
+	}
+}
+
+func TestFileReaderUploadUnknownSize(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files", handleCreateFile)
+
+	file, err := client.CreateFileReader(context.Background(), openai.FileReaderRequest{
+		Name:    "batch.jsonl",
+		Reader:  io.MultiReader(strings.NewReader("hello"), strings.NewReader(" world")),
+		Purpose: openai.PurposeBatch,
+	})
+	checks.NoError(t, err, "CreateFileReader error")
+	if file.Bytes != len("hello world") {
+		t.Errorf("unexpected uploaded size %d", file.Bytes)
+	}
+}
+
 // handleCreateFile Handles the images endpoint by the test server.
 func handleCreateFile(w http.ResponseWriter, r *http.Request) {
 	var err error
@@ -102,6 +151,78 @@ func TestListFile(t *testing.T) {
 	checks.NoError(t, err, "ListFiles error")
 }
 
+func TestListFilePagination(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("purpose") != "vision" || query.Get("order") != "asc" ||
+			query.Get("after") != "file-1" || query.Get("limit") != "2" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"file-2","purpose":"vision"},{"id":"file-3","purpose":"vision"}],`+
+			`"first_id":"file-2","last_id":"file-3","has_more":true}`)
+	})
+	files, err := client.ListFiles(context.Background(),
+		openai.ListFilesWithPurpose(openai.PurposeVision),
+		openai.ListFilesWithOrder("asc"),
+		openai.ListFilesWithAfter("file-1"),
+		openai.ListFilesWithLimit(2),
+	)
+	checks.NoError(t, err, "ListFiles error")
+	if len(files.Files) != 2 || files.LastID != "file-3" || !files.HasMore {
+		t.Errorf("unexpected files list %+v", files)
+	}
+}
+
+func TestFileUploadWithExpiration(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("expires_after[anchor]") != "created_at" || r.FormValue("expires_after[seconds]") != "3600" {
+			http.Error(w, "missing expiration", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, `{"id":"file-1","purpose":"user_data","expires_at":1700003600}`)
+	})
+	file, err := client.CreateFileBytes(context.Background(), openai.FileBytesRequest{
+		Name:         "notes.txt",
+		Bytes:        []byte("notes"),
+		Purpose:      openai.PurposeUserData,
+		ExpiresAfter: &openai.FileExpiresAfter{Seconds: 3600},
+	})
+	checks.NoError(t, err, "CreateFileBytes error")
+	if file.ExpiresAt != 1700003600 {
+		t.Errorf("unexpected expires_at %d", file.ExpiresAt)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	const content = "{\"prompt\": \"foo\"}\n"
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	fileBytes := len(content)
+	server.RegisterHandler("/v1/files/deadbeef", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"id":"deadbeef","bytes":%d}`, fileBytes)
+	})
+	server.RegisterHandler("/v1/files/deadbeef/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, content)
+	})
+
+	var buf strings.Builder
+	written, err := client.DownloadFile(context.Background(), "deadbeef", &buf)
+	checks.NoError(t, err, "DownloadFile error")
+	if written != int64(len(content)) || buf.String() != content {
+		t.Errorf("unexpected download %q (%d bytes)", buf.String(), written)
+	}
+
+	fileBytes = len(content) + 1
+	_, err = client.DownloadFile(context.Background(), "deadbeef", io.Discard)
+	checks.ErrorIs(t, err, openai.ErrFileSizeMismatch, "DownloadFile should verify the file size")
+}
+
 func TestGetFile(t *testing.T) {
 	client, server, teardown := setupOpenAITestServer()
 	defer teardown()
@@ -0,0 +1,577 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+var ErrFineTuningInvalidWeight = errors.New("weight must be 0 or 1")
+
+// FineTuningExample is a single line of a chat fine-tuning JSONL file.
+type FineTuningExample struct {
+	Messages          []FineTuningMessage `json:"messages"`
+	Tools             []Tool              `json:"tools,omitempty"`
+	ParallelToolCalls *bool               `json:"parallel_tool_calls,omitempty"`
+}
+
+// FineTuningMessage is a chat message of a fine-tuning example.
+// Weight may be set on assistant messages: 0 excludes the message from training, 1 includes it.
+type FineTuningMessage struct {
+	ChatCompletionMessage
+	Weight *int
+}
+
+func (m FineTuningMessage) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(m.ChatCompletionMessage)
+	if err != nil || m.Weight == nil {
+		return data, err
+	}
+
+	// data is always a non-empty JSON object, so the weight is spliced in before the closing brace.
+	out := make([]byte, 0, len(data)+16)
+	out = append(out, data[:len(data)-1]...)
+	out = append(out, fmt.Sprintf(`,"weight":%d}`, *m.Weight)...)
+	return out, nil
+}
+
+func (m *FineTuningMessage) UnmarshalJSON(bs []byte) error {
+	var weight struct {
+		Weight json.RawMessage `json:"weight"`
+	}
+	if err := json.Unmarshal(bs, &weight); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bs, &m.ChatCompletionMessage); err != nil {
+		return err
+	}
+
+	m.Weight = nil
+	if len(weight.Weight) == 0 || string(weight.Weight) == "null" {
+		return nil
+	}
+	var value int
+	if err := json.Unmarshal(weight.Weight, &value); err != nil {
+		return fmt.Errorf("%w, got %s", ErrFineTuningInvalidWeight, weight.Weight)
+	}
+	m.Weight = &value
+	return nil
+}
+
+// FineTuningIssueSeverity tells whether an issue prevents the dataset from being used.
+type FineTuningIssueSeverity string
+
+const (
+	FineTuningIssueError   FineTuningIssueSeverity = "error"
+	FineTuningIssueWarning FineTuningIssueSeverity = "warning"
+)
+
+// FineTuningIssueCode identifies the kind of problem found in a fine-tuning dataset.
+type FineTuningIssueCode string
+
+const (
+	FineTuningIssueInvalidJSON           FineTuningIssueCode = "invalid_json"
+	FineTuningIssueMissingMessages       FineTuningIssueCode = "missing_messages"
+	FineTuningIssueUnrecognizedRole      FineTuningIssueCode = "unrecognized_role"
+	FineTuningIssueMissingContent        FineTuningIssueCode = "missing_content"
+	FineTuningIssueSystemMessagePosition FineTuningIssueCode = "system_message_position"
+	FineTuningIssueConsecutiveRoles      FineTuningIssueCode = "consecutive_roles"
+	FineTuningIssueMissingUserMessage    FineTuningIssueCode = "missing_user_message"
+	FineTuningIssueMissingAssistant      FineTuningIssueCode = "missing_final_assistant_message"
+	FineTuningIssueInvalidWeight         FineTuningIssueCode = "invalid_weight"
+	FineTuningIssueNoTrainedMessages     FineTuningIssueCode = "no_trained_messages"
+	FineTuningIssueMissingToolCallID     FineTuningIssueCode = "missing_tool_call_id"
+	FineTuningIssueDuplicateToolCallID   FineTuningIssueCode = "duplicate_tool_call_id"
+	FineTuningIssueUnexpectedToolMessage FineTuningIssueCode = "unexpected_tool_message"
+	FineTuningIssueMissingToolResult     FineTuningIssueCode = "missing_tool_result"
+	FineTuningIssueUndefinedTool         FineTuningIssueCode = "undefined_tool"
+	FineTuningIssueInvalidToolArguments  FineTuningIssueCode = "invalid_tool_arguments"
+	FineTuningIssueExampleTooLong        FineTuningIssueCode = "example_too_long"
+	FineTuningIssueTooFewExamples        FineTuningIssueCode = "too_few_examples"
+	FineTuningIssueUnknownModel          FineTuningIssueCode = "unknown_model"
+)
+
+// FineTuningIssue is a single problem found in a fine-tuning dataset.
+type FineTuningIssue struct {
+	// Line is the 1-based line of the example in the dataset, or 0 for issues about the whole dataset.
+	Line int
+	// Message is the index of the message within the example, or -1 if the issue is about the whole example.
+	Message  int
+	Severity FineTuningIssueSeverity
+	Code     FineTuningIssueCode
+	Detail   string
+}
+
+func (i FineTuningIssue) String() string {
+	switch {
+	case i.Line == 0:
+		return fmt.Sprintf("%s: %s: %s", i.Severity, i.Code, i.Detail)
+	case i.Message < 0:
+		return fmt.Sprintf("line %d: %s: %s: %s", i.Line, i.Severity, i.Code, i.Detail)
+	default:
+		return fmt.Sprintf("line %d, message %d: %s: %s: %s", i.Line, i.Message, i.Severity, i.Code, i.Detail)
+	}
+}
+
+// FineTuningModelLimits holds the per-example token limit and training price of a fine-tunable model.
+type FineTuningModelLimits struct {
+	// MaxTokensPerExample is the context length of a training example. Longer examples are truncated.
+	MaxTokensPerExample int
+	// PricePerMillionTokens is the training price in USD per million trained tokens.
+	PricePerMillionTokens float64
+}
+
+// FineTuningModels lists the limits and published training prices of the fine-tunable models.
+// Prices change over time; override them with FineTuningDatasetOptions when needed.
+var FineTuningModels = map[string]FineTuningModelLimits{
+	GPT3Dot5Turbo0125:    {MaxTokensPerExample: 16385, PricePerMillionTokens: 8},
+	GPT3Dot5Turbo:        {MaxTokensPerExample: 16385, PricePerMillionTokens: 8},
+	GPT4o20240806:        {MaxTokensPerExample: 65536, PricePerMillionTokens: 25},
+	GPT4o:                {MaxTokensPerExample: 65536, PricePerMillionTokens: 25},
+	GPT4oMini20240718:    {MaxTokensPerExample: 65536, PricePerMillionTokens: 3},
+	GPT4oMini:            {MaxTokensPerExample: 65536, PricePerMillionTokens: 3},
+	GPT4Dot120250414:     {MaxTokensPerExample: 65536, PricePerMillionTokens: 25},
+	GPT4Dot1:             {MaxTokensPerExample: 65536, PricePerMillionTokens: 25},
+	GPT4Dot1Mini20250414: {MaxTokensPerExample: 65536, PricePerMillionTokens: 5},
+	GPT4Dot1Mini:         {MaxTokensPerExample: 65536, PricePerMillionTokens: 5},
+	GPT4Dot1Nano20250414: {MaxTokensPerExample: 65536, PricePerMillionTokens: 1.5},
+	GPT4Dot1Nano:         {MaxTokensPerExample: 65536, PricePerMillionTokens: 1.5},
+}
+
+// FineTuningDatasetOptions configures ValidateFineTuningDataset.
+type FineTuningDatasetOptions struct {
+	// Model is the base model to be fine-tuned, used to look up FineTuningModels.
+	Model string
+	// Epochs is the number of training epochs. 0 picks the number the API chooses for "auto".
+	Epochs int
+	// MaxTokensPerExample overrides the per-example token limit of the model.
+	MaxTokensPerExample int
+	// PricePerMillionTokens overrides the training price of the model.
+	PricePerMillionTokens float64
+	// CountTokens counts the tokens of a piece of text. Defaults to ApproximateTokenCount;
+	// plug in a real tokenizer for exact numbers.
+	CountTokens func(string) int
+}
+
+// FineTuningDatasetReport is the result of validating a fine-tuning dataset.
+type FineTuningDatasetReport struct {
+	Examples        int
+	InvalidExamples int
+	Issues          []FineTuningIssue
+
+	// TotalTokens is the sum of the tokens of every example.
+	TotalTokens      int
+	MinExampleTokens int
+	MaxExampleTokens int
+	// TruncatedExamples is the number of examples longer than the per-example limit.
+	TruncatedExamples int
+	// BilledTokensPerEpoch is TotalTokens with every example capped at the per-example limit.
+	BilledTokensPerEpoch int
+
+	Epochs int
+	// EstimatedTrainingTokens is BilledTokensPerEpoch multiplied by Epochs.
+	EstimatedTrainingTokens int
+	// EstimatedCost is the estimated training cost in USD, or 0 if the price of the model is unknown.
+	EstimatedCost float64
+}
+
+// Valid reports whether the dataset has no issue of error severity.
+func (r *FineTuningDatasetReport) Valid() bool {
+	return len(r.Errors()) == 0
+}
+
+// Errors returns the issues that would make the fine-tuning job fail.
+func (r *FineTuningDatasetReport) Errors() []FineTuningIssue {
+	return r.filter(FineTuningIssueError)
+}
+
+// Warnings returns the issues that do not prevent training but are likely mistakes.
+func (r *FineTuningDatasetReport) Warnings() []FineTuningIssue {
+	return r.filter(FineTuningIssueWarning)
+}
+
+func (r *FineTuningDatasetReport) filter(severity FineTuningIssueSeverity) []FineTuningIssue {
+	var issues []FineTuningIssue
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+const (
+	fineTuningMinExamples       = 10
+	fineTuningTargetEpochs      = 3
+	fineTuningMinTargetExamples = 100
+	fineTuningMaxTargetExamples = 25000
+	fineTuningMaxDefaultEpochs  = 25
+)
+
+// FineTuningDefaultEpochs returns the number of epochs the API picks for "auto" given the number of examples.
+func FineTuningDefaultEpochs(examples int) int {
+	if examples <= 0 {
+		return fineTuningTargetEpochs
+	}
+	epochs := fineTuningTargetEpochs
+	if examples*epochs < fineTuningMinTargetExamples {
+		epochs = fineTuningMinTargetExamples / examples
+		if epochs > fineTuningMaxDefaultEpochs {
+			epochs = fineTuningMaxDefaultEpochs
+		}
+	} else if examples*epochs > fineTuningMaxTargetExamples {
+		epochs = fineTuningMaxTargetExamples / examples
+		if epochs < 1 {
+			epochs = 1
+		}
+	}
+	return epochs
+}
+
+// ValidateFineTuningFile validates a chat fine-tuning JSONL file on disk.
+func ValidateFineTuningFile(path string, options FineTuningDatasetOptions) (FineTuningDatasetReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FineTuningDatasetReport{}, err
+	}
+	defer f.Close()
+	return ValidateFineTuningDataset(f, options)
+}
+
+// ValidateFineTuningDataset validates a chat fine-tuning JSONL dataset and estimates the cost of training on it.
+// Problems with the data are reported in the returned report; the error is only set if r cannot be read.
+func ValidateFineTuningDataset(r io.Reader, options FineTuningDatasetOptions) (FineTuningDatasetReport, error) {
+	report := FineTuningDatasetReport{}
+	limits, knownModel := FineTuningModels[options.Model]
+	if options.MaxTokensPerExample > 0 {
+		limits.MaxTokensPerExample = options.MaxTokensPerExample
+	}
+	if options.PricePerMillionTokens > 0 {
+		limits.PricePerMillionTokens = options.PricePerMillionTokens
+	}
+	if !knownModel && (limits.MaxTokensPerExample == 0 || limits.PricePerMillionTokens == 0) {
+		report.Issues = append(report.Issues, FineTuningIssue{
+			Message:  -1,
+			Severity: FineTuningIssueWarning,
+			Code:     FineTuningIssueUnknownModel,
+			Detail:   fmt.Sprintf("no limits are known for model %q, token limit or cost are not checked", options.Model),
+		})
+	}
+
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return report, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			report.addExample(line, data, limits, options.CountTokens)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if report.Examples < fineTuningMinExamples {
+		report.Issues = append(report.Issues, FineTuningIssue{
+			Message:  -1,
+			Severity: FineTuningIssueError,
+			Code:     FineTuningIssueTooFewExamples,
+			Detail:   fmt.Sprintf("at least %d examples are required, got %d", fineTuningMinExamples, report.Examples),
+		})
+	}
+
+	report.Epochs = options.Epochs
+	if report.Epochs <= 0 {
+		report.Epochs = FineTuningDefaultEpochs(report.Examples)
+	}
+	report.EstimatedTrainingTokens = report.BilledTokensPerEpoch * report.Epochs
+	report.EstimatedCost = float64(report.EstimatedTrainingTokens) / 1e6 * limits.PricePerMillionTokens
+	return report, nil
+}
+
+func (r *FineTuningDatasetReport) addExample(
+	line int,
+	data []byte,
+	limits FineTuningModelLimits,
+	countTokens func(string) int,
+) {
+	r.Examples++
+
+	var example FineTuningExample
+	if err := json.Unmarshal(data, &example); err != nil {
+		r.InvalidExamples++
+		code := FineTuningIssueInvalidJSON
+		if errors.Is(err, ErrFineTuningInvalidWeight) {
+			code = FineTuningIssueInvalidWeight
+		}
+		r.Issues = append(r.Issues, FineTuningIssue{
+			Line:     line,
+			Message:  -1,
+			Severity: FineTuningIssueError,
+			Code:     code,
+			Detail:   err.Error(),
+		})
+		return
+	}
+
+	issues := ValidateFineTuningExample(example)
+	invalid := false
+	for _, issue := range issues {
+		issue.Line = line
+		r.Issues = append(r.Issues, issue)
+		invalid = invalid || issue.Severity == FineTuningIssueError
+	}
+	if invalid {
+		r.InvalidExamples++
+	}
+
+	tokens := example.EstimateTokens(countTokens)
+	r.TotalTokens += tokens
+	if r.MinExampleTokens == 0 || tokens < r.MinExampleTokens {
+		r.MinExampleTokens = tokens
+	}
+	if tokens > r.MaxExampleTokens {
+		r.MaxExampleTokens = tokens
+	}
+
+	billed := tokens
+	if limits.MaxTokensPerExample > 0 && tokens > limits.MaxTokensPerExample {
+		billed = limits.MaxTokensPerExample
+		r.TruncatedExamples++
+		r.Issues = append(r.Issues, FineTuningIssue{
+			Line:     line,
+			Message:  -1,
+			Severity: FineTuningIssueWarning,
+			Code:     FineTuningIssueExampleTooLong,
+			Detail: fmt.Sprintf("example has about %d tokens and will be truncated to %d",
+				tokens, limits.MaxTokensPerExample),
+		})
+	}
+	r.BilledTokensPerEpoch += billed
+}
+
+// ValidateFineTuningExample checks a single example and returns the issues found, with Line set to 0.
+func ValidateFineTuningExample(example FineTuningExample) []FineTuningIssue {
+	v := &fineTuningExampleValidator{
+		tools:   map[string]bool{},
+		pending: map[string]bool{},
+		seen:    map[string]bool{},
+	}
+	for _, tool := range example.Tools {
+		if tool.Function != nil {
+			v.tools[tool.Function.Name] = true
+		}
+	}
+	return v.validate(example)
+}
+
+type fineTuningExampleValidator struct {
+	issues []FineTuningIssue
+	tools  map[string]bool
+	// pending holds the tool call IDs of the last assistant message that have no result yet,
+	// order keeps them in the order of the calls.
+	pending map[string]bool
+	order   []string
+	seen    map[string]bool
+}
+
+func (v *fineTuningExampleValidator) add(
+	message int,
+	severity FineTuningIssueSeverity,
+	code FineTuningIssueCode,
+	format string,
+	args ...any,
+) {
+	v.issues = append(v.issues, FineTuningIssue{
+		Message:  message,
+		Severity: severity,
+		Code:     code,
+		Detail:   fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *fineTuningExampleValidator) validate(example FineTuningExample) []FineTuningIssue {
+	if len(example.Messages) == 0 {
+		v.add(-1, FineTuningIssueError, FineTuningIssueMissingMessages, "example has no messages")
+		return v.issues
+	}
+
+	hasUser, trained, weighted := false, false, false
+	for i, message := range example.Messages {
+		v.checkRole(example.Messages, i)
+		v.checkContent(i, message)
+		v.checkTools(i, message)
+
+		switch message.Role {
+		case ChatMessageRoleUser:
+			hasUser = true
+		case ChatMessageRoleAssistant:
+			weighted = weighted || message.Weight != nil
+			trained = trained || message.Weight == nil || *message.Weight == 1
+		}
+
+		if message.Weight == nil {
+			continue
+		}
+		if message.Role != ChatMessageRoleAssistant {
+			v.add(i, FineTuningIssueError, FineTuningIssueInvalidWeight,
+				"weight is only allowed on assistant messages, got role %q", message.Role)
+		} else if *message.Weight != 0 && *message.Weight != 1 {
+			v.add(i, FineTuningIssueError, FineTuningIssueInvalidWeight, "weight must be 0 or 1, got %d", *message.Weight)
+		}
+	}
+
+	if !hasUser {
+		v.add(-1, FineTuningIssueError, FineTuningIssueMissingUserMessage, "example has no user message")
+	}
+	last := len(example.Messages) - 1
+	if example.Messages[last].Role != ChatMessageRoleAssistant {
+		v.add(last, FineTuningIssueError, FineTuningIssueMissingAssistant,
+			"example must end with an assistant message, got role %q", example.Messages[last].Role)
+	}
+	if weighted && !trained {
+		v.add(-1, FineTuningIssueWarning, FineTuningIssueNoTrainedMessages,
+			"every assistant message has weight 0, the example does not train anything")
+	}
+	return v.issues
+}
+
+func (v *fineTuningExampleValidator) checkRole(messages []FineTuningMessage, i int) {
+	role := messages[i].Role
+	switch role {
+	case ChatMessageRoleSystem, ChatMessageRoleDeveloper:
+		if i > 0 && messages[i-1].Role != ChatMessageRoleSystem && messages[i-1].Role != ChatMessageRoleDeveloper {
+			v.add(i, FineTuningIssueError, FineTuningIssueSystemMessagePosition,
+				"%s messages must come before every other message", role)
+		}
+	case ChatMessageRoleUser, ChatMessageRoleAssistant:
+		if i > 0 && messages[i-1].Role == role && len(messages[i-1].ToolCalls) == 0 {
+			v.add(i, FineTuningIssueWarning, FineTuningIssueConsecutiveRoles,
+				"two consecutive %s messages", role)
+		}
+	case ChatMessageRoleTool, ChatMessageRoleFunction:
+	default:
+		v.add(i, FineTuningIssueError, FineTuningIssueUnrecognizedRole, "unrecognized role %q", role)
+	}
+}
+
+func (v *fineTuningExampleValidator) checkContent(i int, message FineTuningMessage) {
+	if message.Content != "" || len(message.MultiContent) > 0 {
+		return
+	}
+	if message.Role == ChatMessageRoleAssistant && (len(message.ToolCalls) > 0 || message.FunctionCall != nil) {
+		return
+	}
+	v.add(i, FineTuningIssueError, FineTuningIssueMissingContent, "%s message has no content", message.Role)
+}
+
+func (v *fineTuningExampleValidator) checkTools(i int, message FineTuningMessage) {
+	switch message.Role {
+	case ChatMessageRoleTool:
+		switch {
+		case message.ToolCallID == "":
+			v.add(i, FineTuningIssueError, FineTuningIssueMissingToolCallID, "tool message has no tool_call_id")
+		case !v.pending[message.ToolCallID]:
+			v.add(i, FineTuningIssueError, FineTuningIssueUnexpectedToolMessage,
+				"tool message answers %q which is not a pending call of the previous assistant message",
+				message.ToolCallID)
+		default:
+			delete(v.pending, message.ToolCallID)
+		}
+		return
+	case ChatMessageRoleAssistant:
+		v.flushPending(i)
+	default:
+		v.flushPending(i)
+		return
+	}
+
+	for _, call := range message.ToolCalls {
+		switch {
+		case call.ID == "":
+			v.add(i, FineTuningIssueError, FineTuningIssueMissingToolCallID, "tool call has no id")
+		case v.seen[call.ID]:
+			v.add(i, FineTuningIssueError, FineTuningIssueDuplicateToolCallID, "tool call id %q is used twice", call.ID)
+		default:
+			v.seen[call.ID] = true
+			v.pending[call.ID] = true
+			v.order = append(v.order, call.ID)
+		}
+
+		if !v.tools[call.Function.Name] {
+			v.add(i, FineTuningIssueError, FineTuningIssueUndefinedTool,
+				"tool %q is not defined in the tools of the example", call.Function.Name)
+		}
+		if !json.Valid([]byte(call.Function.Arguments)) {
+			v.add(i, FineTuningIssueError, FineTuningIssueInvalidToolArguments,
+				"arguments of tool call %q are not valid JSON", call.ID)
+		}
+	}
+}
+
+// flushPending reports the tool calls that were not answered before message i.
+// A final assistant message may end with tool calls, which is how function calling is trained.
+func (v *fineTuningExampleValidator) flushPending(i int) {
+	for _, id := range v.order {
+		if v.pending[id] {
+			v.add(i, FineTuningIssueError, FineTuningIssueMissingToolResult, "tool call %q has no tool result", id)
+			delete(v.pending, id)
+		}
+	}
+	v.order = v.order[:0]
+}
+
+// EstimateTokens estimates the number of tokens of the example as it is counted for training,
+// following the chat format overhead of the OpenAI cookbook. countTokens defaults to ApproximateTokenCount.
+func (e FineTuningExample) EstimateTokens(countTokens func(string) int) int {
+	if countTokens == nil {
+		countTokens = ApproximateTokenCount
+	}
+
+	tokens := tokensPerReply
+	for _, message := range e.Messages {
+		tokens += estimateMessageTokens(message.ChatCompletionMessage, countTokens)
+	}
+	if len(e.Tools) > 0 {
+		if data, err := json.Marshal(e.Tools); err == nil {
+			tokens += countTokens(string(data))
+		}
+	}
+	return tokens
+}
+
+const (
+	tokensPerMessage = 3
+	tokensPerName    = 1
+	tokensPerReply   = 3
+)
+
+// estimateMessageTokens estimates the number of tokens of a chat message, its format overhead included.
+func estimateMessageTokens(message ChatCompletionMessage, countTokens func(string) int) int {
+	tokens := tokensPerMessage + countTokens(message.Role) + countTokens(message.Content)
+	for _, part := range message.MultiContent {
+		tokens += countTokens(part.Text)
+	}
+	if message.Name != "" {
+		tokens += tokensPerName + countTokens(message.Name)
+	}
+	if message.FunctionCall != nil {
+		tokens += countTokens(message.FunctionCall.Name) + countTokens(message.FunctionCall.Arguments)
+	}
+	for _, call := range message.ToolCalls {
+		tokens += countTokens(call.Function.Name) + countTokens(call.Function.Arguments)
+	}
+	return tokens
+}
+
+// ApproximateTokenCount estimates the number of tokens of text as one token per four characters,
+// which is close to the tokenizers of the GPT models for English text.
+func ApproximateTokenCount(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
@@ -0,0 +1,244 @@
+package openai_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+const validFineTuningLine = `{"messages":[{"role":"system","content":"You are terse."},` +
+	`{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"}]}`
+
+func fineTuningCodes(issues []openai.FineTuningIssue) []openai.FineTuningIssueCode {
+	codes := make([]openai.FineTuningIssueCode, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func hasFineTuningCode(issues []openai.FineTuningIssue, code openai.FineTuningIssueCode) bool {
+	for _, issue := range issues {
+		if issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateFineTuningDatasetValid(t *testing.T) {
+	lines := make([]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		lines = append(lines, validFineTuningLine)
+	}
+	data := strings.Join(lines, "\n") + "\n\n"
+
+	report, err := openai.ValidateFineTuningDataset(strings.NewReader(data), openai.FineTuningDatasetOptions{
+		Model:       openai.GPT4oMini20240718,
+		CountTokens: func(string) int { return 1 },
+	})
+	checks.NoError(t, err, "ValidateFineTuningDataset error")
+	if !report.Valid() || len(report.Issues) != 0 {
+		t.Fatalf("expected a valid dataset, got issues %v", report.Issues)
+	}
+	if report.Examples != 10 || report.InvalidExamples != 0 {
+		t.Fatalf("unexpected example counts %d/%d", report.Examples, report.InvalidExamples)
+	}
+
+	// 3 messages of 3 + 2 tokens each, plus 3 tokens for the reply.
+	if report.MinExampleTokens != 18 || report.MaxExampleTokens != 18 || report.TotalTokens != 180 {
+		t.Fatalf("unexpected token totals %+v", report)
+	}
+	// 10 examples * 3 epochs is below 100 trained examples, so auto picks 100/10 epochs.
+	if report.Epochs != 10 || report.EstimatedTrainingTokens != 1800 {
+		t.Fatalf("unexpected epochs %d and training tokens %d", report.Epochs, report.EstimatedTrainingTokens)
+	}
+	if report.EstimatedCost != 1800.0/1e6*3 {
+		t.Fatalf("unexpected cost %f", report.EstimatedCost)
+	}
+}
+
+func TestValidateFineTuningDatasetIssues(t *testing.T) {
+	lines := []string{
+		`not json`,
+		`{"messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello","weight":0.5}]}`,
+		`{"messages":[]}`,
+		`{"messages":[{"role":"user","content":"Hi"},{"role":"system","content":"late"},` +
+			`{"role":"assistant","content":"Hello"}]}`,
+		`{"messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"},` +
+			`{"role":"user","content":"Bye"}]}`,
+		`{"messages":[{"role":"user","content":"Hi","weight":1},{"role":"assistant","content":"Hello","weight":0}]}`,
+		`{"messages":[{"role":"user","content":"` + strings.Repeat("word ", 40) + `"},` +
+			`{"role":"assistant","content":"yes"}]}`,
+	}
+	report, err := openai.ValidateFineTuningDataset(strings.NewReader(strings.Join(lines, "\n")),
+		openai.FineTuningDatasetOptions{Model: openai.GPT4oMini, Epochs: 2, MaxTokensPerExample: 50})
+	checks.NoError(t, err, "ValidateFineTuningDataset error")
+
+	if report.Valid() {
+		t.Fatal("expected an invalid dataset")
+	}
+	if report.Examples != 7 || report.InvalidExamples != 6 {
+		t.Fatalf("unexpected example counts %d/%d", report.Examples, report.InvalidExamples)
+	}
+
+	expected := map[int][]openai.FineTuningIssueCode{
+		1: {openai.FineTuningIssueInvalidJSON},
+		2: {openai.FineTuningIssueInvalidWeight},
+		3: {openai.FineTuningIssueMissingMessages},
+		4: {openai.FineTuningIssueSystemMessagePosition},
+		5: {openai.FineTuningIssueMissingAssistant},
+		6: {openai.FineTuningIssueInvalidWeight, openai.FineTuningIssueNoTrainedMessages},
+		7: {openai.FineTuningIssueExampleTooLong},
+		0: {openai.FineTuningIssueTooFewExamples},
+	}
+	for line, codes := range expected {
+		var issues []openai.FineTuningIssue
+		for _, issue := range report.Issues {
+			if issue.Line == line {
+				issues = append(issues, issue)
+			}
+		}
+		got := fineTuningCodes(issues)
+		if len(got) != len(codes) {
+			t.Fatalf("line %d: expected %v, got %v", line, codes, got)
+		}
+		for i := range codes {
+			if got[i] != codes[i] {
+				t.Fatalf("line %d: expected %v, got %v", line, codes, got)
+			}
+		}
+	}
+
+	if report.TruncatedExamples != 1 || report.Epochs != 2 {
+		t.Fatalf("unexpected truncation %d or epochs %d", report.TruncatedExamples, report.Epochs)
+	}
+	if report.BilledTokensPerEpoch >= report.TotalTokens {
+		t.Fatalf("billed tokens %d should be capped below %d", report.BilledTokensPerEpoch, report.TotalTokens)
+	}
+	if len(report.Warnings()) != 2 || report.Warnings()[0].String() == "" {
+		t.Fatalf("unexpected warnings %v", report.Warnings())
+	}
+}
+
+func TestValidateFineTuningExampleTools(t *testing.T) {
+	tools := []openai.Tool{{
+		Type:     openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{Name: "get_weather"},
+	}}
+	call := func(id, name, args string) openai.ToolCall {
+		return openai.ToolCall{
+			ID:       id,
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: name, Arguments: args},
+		}
+	}
+	message := func(role, content string) openai.FineTuningMessage {
+		return openai.FineTuningMessage{ChatCompletionMessage: openai.ChatCompletionMessage{Role: role, Content: content}}
+	}
+	assistantCalls := func(calls ...openai.ToolCall) openai.FineTuningMessage {
+		return openai.FineTuningMessage{ChatCompletionMessage: openai.ChatCompletionMessage{
+			Role:      openai.ChatMessageRoleAssistant,
+			ToolCalls: calls,
+		}}
+	}
+	toolResult := func(id string) openai.FineTuningMessage {
+		return openai.FineTuningMessage{ChatCompletionMessage: openai.ChatCompletionMessage{
+			Role:       openai.ChatMessageRoleTool,
+			Content:    "sunny",
+			ToolCallID: id,
+		}}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		issues := openai.ValidateFineTuningExample(openai.FineTuningExample{
+			Tools: tools,
+			Messages: []openai.FineTuningMessage{
+				message(openai.ChatMessageRoleUser, "Weather?"),
+				assistantCalls(call("a", "get_weather", `{"city":"Paris"}`), call("b", "get_weather", `{}`)),
+				toolResult("b"),
+				toolResult("a"),
+				message(openai.ChatMessageRoleAssistant, "Sunny"),
+			},
+		})
+		if len(issues) != 0 {
+			t.Fatalf("unexpected issues %v", issues)
+		}
+	})
+
+	t.Run("final tool call", func(t *testing.T) {
+		issues := openai.ValidateFineTuningExample(openai.FineTuningExample{
+			Tools: tools,
+			Messages: []openai.FineTuningMessage{
+				message(openai.ChatMessageRoleUser, "Weather?"),
+				assistantCalls(call("a", "get_weather", `{}`)),
+			},
+		})
+		if len(issues) != 0 {
+			t.Fatalf("unexpected issues %v", issues)
+		}
+	})
+
+	t.Run("inconsistent", func(t *testing.T) {
+		issues := openai.ValidateFineTuningExample(openai.FineTuningExample{
+			Tools: tools,
+			Messages: []openai.FineTuningMessage{
+				message(openai.ChatMessageRoleUser, "Weather?"),
+				toolResult("x"),
+				assistantCalls(call("a", "get_time", `{`), call("a", "get_weather", `{}`), call("", "get_weather", `{}`)),
+				toolResult("a"),
+				assistantCalls(call("c", "get_weather", `{}`)),
+				message(openai.ChatMessageRoleAssistant, "Sunny"),
+			},
+		})
+		for _, code := range []openai.FineTuningIssueCode{
+			openai.FineTuningIssueUnexpectedToolMessage,
+			openai.FineTuningIssueUndefinedTool,
+			openai.FineTuningIssueInvalidToolArguments,
+			openai.FineTuningIssueDuplicateToolCallID,
+			openai.FineTuningIssueMissingToolCallID,
+			openai.FineTuningIssueMissingToolResult,
+		} {
+			if !hasFineTuningCode(issues, code) {
+				t.Errorf("expected issue %s in %v", code, issues)
+			}
+		}
+	})
+}
+
+func TestFineTuningMessageWeightRoundTrip(t *testing.T) {
+	weight := 0
+	example := openai.FineTuningExample{Messages: []openai.FineTuningMessage{{
+		ChatCompletionMessage: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Hi"},
+		Weight:                &weight,
+	}, {
+		ChatCompletionMessage: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: "Hey"},
+	}}}
+
+	data, err := json.Marshal(example)
+	checks.NoError(t, err, "Marshal error")
+	expected := `{"messages":[{"role":"assistant","content":"Hi","weight":0},{"role":"user","content":"Hey"}]}`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+
+	var decoded openai.FineTuningExample
+	checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+	if decoded.Messages[0].Weight == nil || *decoded.Messages[0].Weight != 0 || decoded.Messages[1].Weight != nil {
+		t.Fatalf("unexpected weights after round trip: %+v", decoded.Messages)
+	}
+	if decoded.Messages[0].Content != "Hi" {
+		t.Fatalf("unexpected content %q", decoded.Messages[0].Content)
+	}
+}
+
+func TestFineTuningDefaultEpochs(t *testing.T) {
+	for examples, epochs := range map[int]int{0: 3, 1: 25, 10: 10, 50: 3, 5000: 3, 10000: 2, 100000: 1} {
+		if got := openai.FineTuningDefaultEpochs(examples); got != epochs {
+			t.Errorf("%d examples: expected %d epochs, got %d", examples, epochs, got)
+		}
+	}
+}
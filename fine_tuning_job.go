@@ -2,68 +2,325 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
+)
+
+const fineTuningJobsSuffix = "/fine_tuning/jobs"
+
+// Fine-tuning job statuses.
+const (
+	FineTuningJobStatusValidatingFiles = "validating_files"
+	FineTuningJobStatusQueued          = "queued"
+	FineTuningJobStatusRunning         = "running"
+	FineTuningJobStatusPaused          = "paused"
+	FineTuningJobStatusSucceeded       = "succeeded"
+	FineTuningJobStatusFailed          = "failed"
+	FineTuningJobStatusCancelled       = "cancelled"
+)
+
+var (
+	ErrInvalidHyperparameterValue   = errors.New(`hyperparameter value must be "auto" or a number`)
+	ErrFineTuningJobEventNotMetrics = errors.New("fine-tuning job event is not a metrics event")
 )
 
 type FineTuningJob struct {
-	ID              string          `json:"id"`
-	Object          string          `json:"object"`
-	CreatedAt       int64           `json:"created_at"`
-	FinishedAt      int64           `json:"finished_at"`
-	Model           string          `json:"model"`
-	FineTunedModel  string          `json:"fine_tuned_model,omitempty"`
-	OrganizationID  string          `json:"organization_id"`
-	Status          string          `json:"status"`
-	Hyperparameters Hyperparameters `json:"hyperparameters"`
-	TrainingFile    string          `json:"training_file"`
-	ValidationFile  string          `json:"validation_file,omitempty"`
-	ResultFiles     []string        `json:"result_files"`
-	TrainedTokens   int             `json:"trained_tokens"`
+	ID                 string                  `json:"id"`
+	Object             string                  `json:"object"`
+	CreatedAt          int64                   `json:"created_at"`
+	FinishedAt         int64                   `json:"finished_at"`
+	Model              string                  `json:"model"`
+	FineTunedModel     string                  `json:"fine_tuned_model,omitempty"`
+	OrganizationID     string                  `json:"organization_id"`
+	Status             string                  `json:"status"`
+	Hyperparameters    Hyperparameters         `json:"hyperparameters"`
+	TrainingFile       string                  `json:"training_file"`
+	ValidationFile     string                  `json:"validation_file,omitempty"`
+	ResultFiles        []string                `json:"result_files"`
+	TrainedTokens      int                     `json:"trained_tokens"`
+	Error              *FineTuningJobError     `json:"error,omitempty"`
+	Method             *FineTuningMethod       `json:"method,omitempty"`
+	Seed               int                     `json:"seed,omitempty"`
+	Metadata           map[string]string       `json:"metadata,omitempty"`
+	Integrations       []FineTuningIntegration `json:"integrations,omitempty"`
+	EstimatedFinish    int64                   `json:"estimated_finish,omitempty"`
+	UserProvidedSuffix string                  `json:"user_provided_suffix,omitempty"`
 
 	httpHeader
 }
 
+// IsFinished reports whether the job has reached a terminal status.
+func (j FineTuningJob) IsFinished() bool {
+	switch j.Status {
+	case FineTuningJobStatusSucceeded, FineTuningJobStatusFailed, FineTuningJobStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// FineTuningJobError describes why a fine-tuning job failed.
+type FineTuningJobError struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param,omitempty"`
+}
+
+func (e *FineTuningJobError) Error() string {
+	return fmt.Sprintf("fine-tuning job failed, code: %s, message: %s", e.Code, e.Message)
+}
+
+// HyperparameterValue is a hyperparameter which is either "auto" or a number.
+// The zero value is omitted from requests so the API default is used.
+type HyperparameterValue string
+
+// HyperparameterAuto lets the API choose the value of a hyperparameter.
+const HyperparameterAuto HyperparameterValue = "auto"
+
+// HyperparameterInt returns a hyperparameter with an integer value.
+func HyperparameterInt(v int) HyperparameterValue {
+	return HyperparameterValue(strconv.Itoa(v))
+}
+
+// HyperparameterFloat returns a hyperparameter with a floating point value.
+func HyperparameterFloat(v float64) HyperparameterValue {
+	return HyperparameterValue(strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// IsAuto reports whether the value is "auto".
+func (v HyperparameterValue) IsAuto() bool {
+	return v == HyperparameterAuto
+}
+
+// Int returns the value as an integer. ok is false for "auto" and non-integer values.
+func (v HyperparameterValue) Int() (n int, ok bool) {
+	n, err := strconv.Atoi(string(v))
+	return n, err == nil
+}
+
+// Float returns the numeric value. ok is false for "auto".
+func (v HyperparameterValue) Float() (f float64, ok bool) {
+	f, err := strconv.ParseFloat(string(v), 64)
+	return f, err == nil
+}
+
+// jsonNumber matches the JSON number grammar, which is stricter than strconv.ParseFloat:
+// it rejects NaN, Inf, a leading +, hexadecimal and underscores.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func (v HyperparameterValue) MarshalJSON() ([]byte, error) {
+	if v == "" || v.IsAuto() {
+		return json.Marshal(string(v))
+	}
+	if !jsonNumber.MatchString(string(v)) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidHyperparameterValue, string(v))
+	}
+	return []byte(v), nil
+}
+
+func (v *HyperparameterValue) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*v = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != string(HyperparameterAuto) {
+			return fmt.Errorf("%w: %q", ErrInvalidHyperparameterValue, s)
+		}
+		*v = HyperparameterAuto
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidHyperparameterValue, data)
+	}
+	*v = HyperparameterValue(n.String())
+	return nil
+}
+
 type Hyperparameters struct {
-	Epochs                 any `json:"n_epochs,omitempty"`
-	LearningRateMultiplier any `json:"learning_rate_multiplier,omitempty"`
-	BatchSize              any `json:"batch_size,omitempty"`
+	Epochs                 HyperparameterValue `json:"n_epochs,omitempty"`
+	LearningRateMultiplier HyperparameterValue `json:"learning_rate_multiplier,omitempty"`
+	BatchSize              HyperparameterValue `json:"batch_size,omitempty"`
 }
 
-type FineTuningJobRequest struct {
-	TrainingFile    string           `json:"training_file"`
-	ValidationFile  string           `json:"validation_file,omitempty"`
-	Model           string           `json:"model,omitempty"`
+// DPOHyperparameters are the hyperparameters of the DPO fine-tuning method.
+type DPOHyperparameters struct {
+	Hyperparameters
+	// Beta weights the penalty between the policy and reference model.
+	Beta HyperparameterValue `json:"beta,omitempty"`
+}
+
+// ReinforcementHyperparameters are the hyperparameters of the reinforcement fine-tuning method.
+type ReinforcementHyperparameters struct {
+	Hyperparameters
+	ReasoningEffort   string              `json:"reasoning_effort,omitempty"`
+	ComputeMultiplier HyperparameterValue `json:"compute_multiplier,omitempty"`
+	EvalInterval      HyperparameterValue `json:"eval_interval,omitempty"`
+	EvalSamples       HyperparameterValue `json:"eval_samples,omitempty"`
+}
+
+type FineTuningMethodType string
+
+const (
+	FineTuningMethodSupervised    FineTuningMethodType = "supervised"
+	FineTuningMethodDPO           FineTuningMethodType = "dpo"
+	FineTuningMethodReinforcement FineTuningMethodType = "reinforcement"
+)
+
+// FineTuningMethod is the method used for fine-tuning. Only the configuration
+// matching Type should be set.
+type FineTuningMethod struct {
+	Type          FineTuningMethodType           `json:"type"`
+	Supervised    *FineTuningSupervisedMethod    `json:"supervised,omitempty"`
+	DPO           *FineTuningDPOMethod           `json:"dpo,omitempty"`
+	Reinforcement *FineTuningReinforcementMethod `json:"reinforcement,omitempty"`
+}
+
+type FineTuningSupervisedMethod struct {
 	Hyperparameters *Hyperparameters `json:"hyperparameters,omitempty"`
-	Suffix          string           `json:"suffix,omitempty"`
 }
 
-type FineTuningJobEventList struct {
+type FineTuningDPOMethod struct {
+	Hyperparameters *DPOHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+type FineTuningReinforcementMethod struct {
+	// Grader is the grader used for the fine-tuning job, such as a string_check,
+	// text_similarity, python, score_model or multi grader object.
+	Grader          any                           `json:"grader"`
+	Hyperparameters *ReinforcementHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+const FineTuningIntegrationTypeWandb = "wandb"
+
+// FineTuningIntegration enables an integration for a fine-tuning job.
+type FineTuningIntegration struct {
+	Type  string                 `json:"type"`
+	Wandb *FineTuningWandbConfig `json:"wandb,omitempty"`
+}
+
+// FineTuningWandbConfig configures the Weights and Biases integration.
+type FineTuningWandbConfig struct {
+	Project string   `json:"project"`
+	Name    string   `json:"name,omitempty"`
+	Entity  string   `json:"entity,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+type FineTuningJobRequest struct {
+	TrainingFile   string `json:"training_file"`
+	ValidationFile string `json:"validation_file,omitempty"`
+	Model          string `json:"model,omitempty"`
+	// Deprecated: use Method instead.
+	Hyperparameters *Hyperparameters        `json:"hyperparameters,omitempty"`
+	Suffix          string                  `json:"suffix,omitempty"`
+	Method          *FineTuningMethod       `json:"method,omitempty"`
+	Seed            *int                    `json:"seed,omitempty"`
+	Metadata        map[string]string       `json:"metadata,omitempty"`
+	Integrations    []FineTuningIntegration `json:"integrations,omitempty"`
+}
+
+type FineTuningJobList struct {
 	Object  string          `json:"object"`
-	Data    []FineTuneEvent `json:"data"`
+	Data    []FineTuningJob `json:"data"`
 	HasMore bool            `json:"has_more"`
 
 	httpHeader
 }
 
+// FineTuningJobCheckpointMetrics are the metrics at the step number of a checkpoint.
+type FineTuningJobCheckpointMetrics struct {
+	Step                       float64 `json:"step"`
+	TrainLoss                  float64 `json:"train_loss"`
+	TrainMeanTokenAccuracy     float64 `json:"train_mean_token_accuracy"`
+	ValidLoss                  float64 `json:"valid_loss"`
+	ValidMeanTokenAccuracy     float64 `json:"valid_mean_token_accuracy"`
+	FullValidLoss              float64 `json:"full_valid_loss"`
+	FullValidMeanTokenAccuracy float64 `json:"full_valid_mean_token_accuracy"`
+}
+
+// FineTuningJobCheckpoint is a model checkpoint created during a fine-tuning job.
+type FineTuningJobCheckpoint struct {
+	ID                       string                         `json:"id"`
+	Object                   string                         `json:"object"`
+	CreatedAt                int64                          `json:"created_at"`
+	FineTunedModelCheckpoint string                         `json:"fine_tuned_model_checkpoint"`
+	FineTuningJobID          string                         `json:"fine_tuning_job_id"`
+	StepNumber               int                            `json:"step_number"`
+	Metrics                  FineTuningJobCheckpointMetrics `json:"metrics"`
+}
+
+type FineTuningJobCheckpointList struct {
+	Object  string                    `json:"object"`
+	Data    []FineTuningJobCheckpoint `json:"data"`
+	FirstID string                    `json:"first_id"`
+	LastID  string                    `json:"last_id"`
+	HasMore bool                      `json:"has_more"`
+
+	httpHeader
+}
+
+type FineTuningJobEventList struct {
+	Object  string               `json:"object"`
+	Data    []FineTuningJobEvent `json:"data"`
+	HasMore bool                 `json:"has_more"`
+
+	httpHeader
+}
+
+// Fine-tuning job event types.
+const (
+	FineTuningJobEventTypeMessage = "message"
+	FineTuningJobEventTypeMetrics = "metrics"
+)
+
 type FineTuningJobEvent struct {
-	Object    string `json:"object"`
-	ID        string `json:"id"`
-	CreatedAt int    `json:"created_at"`
-	Level     string `json:"level"`
-	Message   string `json:"message"`
-	Data      any    `json:"data"`
-	Type      string `json:"type"`
+	Object    string          `json:"object"`
+	ID        string          `json:"id"`
+	CreatedAt int64           `json:"created_at"`
+	Level     string          `json:"level"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Type      string          `json:"type"`
+}
+
+// FineTuningJobMetrics are the training metrics reported by a metrics event.
+// Validation metrics are only reported when the job has a validation file.
+type FineTuningJobMetrics struct {
+	Step                       int      `json:"step"`
+	TotalSteps                 int      `json:"total_steps,omitempty"`
+	TrainLoss                  float64  `json:"train_loss"`
+	TrainMeanTokenAccuracy     float64  `json:"train_mean_token_accuracy"`
+	ValidLoss                  *float64 `json:"valid_loss,omitempty"`
+	ValidMeanTokenAccuracy     *float64 `json:"valid_mean_token_accuracy,omitempty"`
+	FullValidLoss              *float64 `json:"full_valid_loss,omitempty"`
+	FullValidMeanTokenAccuracy *float64 `json:"full_valid_mean_token_accuracy,omitempty"`
+}
+
+// Metrics decodes the data of a metrics event.
+// It returns ErrFineTuningJobEventNotMetrics if the event is of another type.
+func (e FineTuningJobEvent) Metrics() (metrics FineTuningJobMetrics, err error) {
+	if e.Type != FineTuningJobEventTypeMetrics || len(e.Data) == 0 {
+		err = ErrFineTuningJobEventNotMetrics
+		return
+	}
+	err = json.Unmarshal(e.Data, &metrics)
+	return
 }
 
 // CreateFineTuningJob create a fine tuning job.
 func (c *Client) CreateFineTuningJob(
 	ctx context.Context,
 	request FineTuningJobRequest,
+	opts ...RequestOption,
 ) (response FineTuningJob, err error) {
-	urlSuffix := "/fine_tuning/jobs"
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(fineTuningJobsSuffix), withBody(request), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -73,8 +330,57 @@ func (c *Client) CreateFineTuningJob(
 }
 
 // CancelFineTuningJob cancel a fine tuning job.
-func (c *Client) CancelFineTuningJob(ctx context.Context, fineTuningJobID string) (response FineTuningJob, err error) {
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/fine_tuning/jobs/"+fineTuningJobID+"/cancel"))
+func (c *Client) CancelFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts ...RequestOption,
+) (response FineTuningJob, err error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(fineTuningJobsSuffix+"/"+fineTuningJobID+"/cancel"),
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// PauseFineTuningJob pause a running fine tuning job.
+func (c *Client) PauseFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts ...RequestOption,
+) (response FineTuningJob, err error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(fineTuningJobsSuffix+"/"+fineTuningJobID+"/pause"),
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// ResumeFineTuningJob resume a paused fine tuning job.
+func (c *Client) ResumeFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts ...RequestOption,
+) (response FineTuningJob, err error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(fineTuningJobsSuffix+"/"+fineTuningJobID+"/resume"),
+		withOptions(opts),
+	)
 	if err != nil {
 		return
 	}
@@ -87,9 +393,10 @@ func (c *Client) CancelFineTuningJob(ctx context.Context, fineTuningJobID string
 func (c *Client) RetrieveFineTuningJob(
 	ctx context.Context,
 	fineTuningJobID string,
+	opts ...RequestOption,
 ) (response FineTuningJob, err error) {
-	urlSuffix := fmt.Sprintf("/fine_tuning/jobs/%s", fineTuningJobID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	urlSuffix := fmt.Sprintf("%s/%s", fineTuningJobsSuffix, fineTuningJobID)
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -101,10 +408,19 @@ func (c *Client) RetrieveFineTuningJob(
 type listFineTuningJobEventsParameters struct {
 	after *string
 	limit *int
+
+	requestOptions []RequestOption
 }
 
 type ListFineTuningJobEventsParameter func(*listFineTuningJobEventsParameters)
 
+// ListFineTuningJobEventsWithRequestOptions sets the options of the request listing the fine tuning job events.
+func ListFineTuningJobEventsWithRequestOptions(opts ...RequestOption) ListFineTuningJobEventsParameter {
+	return func(args *listFineTuningJobEventsParameters) {
+		args.requestOptions = append(args.requestOptions, opts...)
+	}
+}
+
 func ListFineTuningJobEventsWithAfter(after string) ListFineTuningJobEventsParameter {
 	return func(args *listFineTuningJobEventsParameters) {
 		args.after = &after
@@ -148,7 +464,153 @@ func (c *Client) ListFineTuningJobEvents(
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
-		c.fullURL("/fine_tuning/jobs/"+fineTuningJobID+"/events"+encodedValues),
+		c.fullURL(fineTuningJobsSuffix+"/"+fineTuningJobID+"/events"+encodedValues),
+		withOptions(parameters.requestOptions),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+type listFineTuningJobsParameters struct {
+	after    *string
+	limit    *int
+	metadata map[string]string
+
+	requestOptions []RequestOption
+}
+
+type ListFineTuningJobsParameter func(*listFineTuningJobsParameters)
+
+// ListFineTuningJobsWithRequestOptions sets the options of the request listing the fine tuning jobs.
+func ListFineTuningJobsWithRequestOptions(opts ...RequestOption) ListFineTuningJobsParameter {
+	return func(args *listFineTuningJobsParameters) {
+		args.requestOptions = append(args.requestOptions, opts...)
+	}
+}
+
+func ListFineTuningJobsWithAfter(after string) ListFineTuningJobsParameter {
+	return func(args *listFineTuningJobsParameters) {
+		args.after = &after
+	}
+}
+
+func ListFineTuningJobsWithLimit(limit int) ListFineTuningJobsParameter {
+	return func(args *listFineTuningJobsParameters) {
+		args.limit = &limit
+	}
+}
+
+// ListFineTuningJobsWithMetadata filters jobs by a metadata key and value.
+func ListFineTuningJobsWithMetadata(key, value string) ListFineTuningJobsParameter {
+	return func(args *listFineTuningJobsParameters) {
+		if args.metadata == nil {
+			args.metadata = make(map[string]string)
+		}
+		args.metadata[key] = value
+	}
+}
+
+// ListFineTuningJobs list fine tuning jobs of the organization.
+func (c *Client) ListFineTuningJobs(
+	ctx context.Context,
+	setters ...ListFineTuningJobsParameter,
+) (response FineTuningJobList, err error) {
+	parameters := &listFineTuningJobsParameters{}
+	for _, setter := range setters {
+		setter(parameters)
+	}
+
+	urlValues := url.Values{}
+	if parameters.after != nil {
+		urlValues.Add("after", *parameters.after)
+	}
+	if parameters.limit != nil {
+		urlValues.Add("limit", fmt.Sprintf("%d", *parameters.limit))
+	}
+	for key, value := range parameters.metadata {
+		urlValues.Add(fmt.Sprintf("metadata[%s]", key), value)
+	}
+
+	encodedValues := ""
+	if len(urlValues) > 0 {
+		encodedValues = "?" + urlValues.Encode()
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(fineTuningJobsSuffix+encodedValues),
+		withOptions(parameters.requestOptions),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+type listFineTuningJobCheckpointsParameters struct {
+	after *string
+	limit *int
+
+	requestOptions []RequestOption
+}
+
+type ListFineTuningJobCheckpointsParameter func(*listFineTuningJobCheckpointsParameters)
+
+// ListFineTuningJobCheckpointsWithRequestOptions sets the options of the request listing the checkpoints.
+func ListFineTuningJobCheckpointsWithRequestOptions(opts ...RequestOption) ListFineTuningJobCheckpointsParameter {
+	return func(args *listFineTuningJobCheckpointsParameters) {
+		args.requestOptions = append(args.requestOptions, opts...)
+	}
+}
+
+func ListFineTuningJobCheckpointsWithAfter(after string) ListFineTuningJobCheckpointsParameter {
+	return func(args *listFineTuningJobCheckpointsParameters) {
+		args.after = &after
+	}
+}
+
+func ListFineTuningJobCheckpointsWithLimit(limit int) ListFineTuningJobCheckpointsParameter {
+	return func(args *listFineTuningJobCheckpointsParameters) {
+		args.limit = &limit
+	}
+}
+
+// ListFineTuningJobCheckpoints list checkpoints of a fine tuning job.
+func (c *Client) ListFineTuningJobCheckpoints(
+	ctx context.Context,
+	fineTuningJobID string,
+	setters ...ListFineTuningJobCheckpointsParameter,
+) (response FineTuningJobCheckpointList, err error) {
+	parameters := &listFineTuningJobCheckpointsParameters{}
+	for _, setter := range setters {
+		setter(parameters)
+	}
+
+	urlValues := url.Values{}
+	if parameters.after != nil {
+		urlValues.Add("after", *parameters.after)
+	}
+	if parameters.limit != nil {
+		urlValues.Add("limit", fmt.Sprintf("%d", *parameters.limit))
+	}
+
+	encodedValues := ""
+	if len(urlValues) > 0 {
+		encodedValues = "?" + urlValues.Encode()
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(fineTuningJobsSuffix+"/"+fineTuningJobID+"/checkpoints"+encodedValues),
+		withOptions(parameters.requestOptions),
 	)
 	if err != nil {
 		return
@@ -104,3 +104,127 @@ func TestFineTuningJob(t *testing.T) {
 	)
 	checks.NoError(t, err, "ListFineTuningJobEvents error")
 }
+
+func TestFineTuningJobParity(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/fine_tuning/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var req map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			method, _ := req["method"].(map[string]any)
+			if method["type"] != "dpo" || req["seed"] != float64(42) {
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintln(w, `{"id":"ftjob-1","status":"queued","method":{"type":"dpo","dpo":{"hyperparameters":{"beta":0.1,"n_epochs":"auto"}}}}`) //nolint:lll
+			return
+		}
+		if r.URL.Query().Get("metadata[team]") != "search" || r.URL.Query().Get("limit") != "2" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"ftjob-1","status":"failed","error":{"code":"invalid_training_file","message":"bad file","param":"training_file"}}],"has_more":false}`) //nolint:lll
+	})
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-1/checkpoints", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"ftckpt-1","step_number":100,"fine_tuned_model_checkpoint":"ft:gpt-4o-mini:org::ckpt-step-100","metrics":{"step":100,"train_loss":0.5,"valid_loss":0.6}}],"first_id":"ftckpt-1","last_id":"ftckpt-1","has_more":false}`) //nolint:lll
+	})
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-1/pause", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"id":"ftjob-1","status":"paused"}`)
+	})
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-1/resume", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"id":"ftjob-1","status":"running"}`)
+	})
+
+	ctx := context.Background()
+	seed := 42
+	job, err := client.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{
+		TrainingFile: "file-abc",
+		Model:        "gpt-4o-mini-2024-07-18",
+		Seed:         &seed,
+		Metadata:     map[string]string{"team": "search"},
+		Method: &openai.FineTuningMethod{
+			Type: openai.FineTuningMethodDPO,
+			DPO: &openai.FineTuningDPOMethod{
+				Hyperparameters: &openai.DPOHyperparameters{
+					Hyperparameters: openai.Hyperparameters{Epochs: openai.HyperparameterAuto},
+					Beta:            openai.HyperparameterFloat(0.1),
+				},
+			},
+		},
+		Integrations: []openai.FineTuningIntegration{{
+			Type:  openai.FineTuningIntegrationTypeWandb,
+			Wandb: &openai.FineTuningWandbConfig{Project: "ft"},
+		}},
+	})
+	checks.NoError(t, err, "CreateFineTuningJob error")
+	if beta, ok := job.Method.DPO.Hyperparameters.Beta.Float(); !ok || beta != 0.1 {
+		t.Errorf("unexpected beta %q", job.Method.DPO.Hyperparameters.Beta)
+	}
+
+	jobs, err := client.ListFineTuningJobs(ctx,
+		openai.ListFineTuningJobsWithMetadata("team", "search"),
+		openai.ListFineTuningJobsWithLimit(2),
+		openai.ListFineTuningJobsWithAfter(""),
+	)
+	checks.NoError(t, err, "ListFineTuningJobs error")
+	if len(jobs.Data) != 1 || jobs.Data[0].Error == nil || jobs.Data[0].Error.Code != "invalid_training_file" {
+		t.Fatalf("unexpected jobs %+v", jobs.Data)
+	}
+	if !jobs.Data[0].IsFinished() {
+		t.Error("failed job should be finished")
+	}
+
+	checkpoints, err := client.ListFineTuningJobCheckpoints(ctx, "ftjob-1",
+		openai.ListFineTuningJobCheckpointsWithLimit(10),
+		openai.ListFineTuningJobCheckpointsWithAfter("ftckpt-0"),
+	)
+	checks.NoError(t, err, "ListFineTuningJobCheckpoints error")
+	if len(checkpoints.Data) != 1 || checkpoints.Data[0].Metrics.TrainLoss != 0.5 {
+		t.Errorf("unexpected checkpoints %+v", checkpoints.Data)
+	}
+
+	job, err = client.PauseFineTuningJob(ctx, "ftjob-1")
+	checks.NoError(t, err, "PauseFineTuningJob error")
+	if job.Status != openai.FineTuningJobStatusPaused {
+		t.Errorf("unexpected status %q", job.Status)
+	}
+	job, err = client.ResumeFineTuningJob(ctx, "ftjob-1")
+	checks.NoError(t, err, "ResumeFineTuningJob error")
+	if job.Status != openai.FineTuningJobStatusRunning {
+		t.Errorf("unexpected status %q", job.Status)
+	}
+}
+
+func TestHyperparameterValue(t *testing.T) {
+	data, err := json.Marshal(openai.Hyperparameters{
+		Epochs:                 openai.HyperparameterInt(3),
+		LearningRateMultiplier: openai.HyperparameterFloat(0.5),
+		BatchSize:              openai.HyperparameterAuto,
+	})
+	checks.NoError(t, err, "Marshal error")
+	want := `{"n_epochs":3,"learning_rate_multiplier":0.5,"batch_size":"auto"}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var params openai.Hyperparameters
+	err = json.Unmarshal([]byte(`{"n_epochs":4,"batch_size":"auto"}`), &params)
+	checks.NoError(t, err, "Unmarshal error")
+	if epochs, ok := params.Epochs.Int(); !ok || epochs != 4 {
+		t.Errorf("unexpected epochs %q", params.Epochs)
+	}
+	if !params.BatchSize.IsAuto() || params.LearningRateMultiplier != "" {
+		t.Errorf("unexpected hyperparameters %+v", params)
+	}
+
+	for _, value := range []openai.HyperparameterValue{"three", "NaN", "Inf", "+1", "0x1p-2", "1_0", "01", " 1"} {
+		_, err = json.Marshal(openai.Hyperparameters{Epochs: value})
+		checks.ErrorIs(t, err, openai.ErrInvalidHyperparameterValue, "Marshal should reject "+string(value))
+	}
+	_, err = json.Marshal(openai.Hyperparameters{LearningRateMultiplier: openai.HyperparameterFloat(1e-7)})
+	checks.NoError(t, err, "Marshal error")
+	err = json.Unmarshal([]byte(`{"n_epochs":"three"}`), &params)
+	checks.ErrorIs(t, err, openai.ErrInvalidHyperparameterValue, "Unmarshal should reject non-numeric values")
+}
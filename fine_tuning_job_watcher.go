@@ -0,0 +1,302 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFineTuningWatchPollInterval = 10 * time.Second
+	defaultFineTuningWatchPageSize     = 100
+)
+
+var ErrFineTuningResultsMissingStep = errors.New("fine-tuning results have no step column")
+
+// FineTuningJobWatchOptions configures WatchFineTuningJob.
+type FineTuningJobWatchOptions struct {
+	// PollInterval is the time between two polls of the job. Defaults to 10 seconds.
+	PollInterval time.Duration
+	// PageSize is the number of events requested per page. Defaults to 100.
+	PageSize int
+	// SkipResults disables downloading the result file once the job succeeded.
+	SkipResults bool
+}
+
+// FineTuningJobWatcher polls a fine-tuning job until it finishes and yields its events once each,
+// oldest first. It is not safe for concurrent use.
+type FineTuningJobWatcher struct {
+	client  *Client
+	ctx     context.Context
+	jobID   string
+	options FineTuningJobWatchOptions
+	opts    []RequestOption
+
+	seen    map[string]bool
+	pending []FineTuningJobEvent
+	polled  bool
+	done    bool
+	job     FineTuningJob
+	results []FineTuningResultRow
+}
+
+// WatchFineTuningJob returns a watcher of the fine-tuning job. Nothing is requested until Recv is called.
+func (c *Client) WatchFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	options FineTuningJobWatchOptions,
+	opts ...RequestOption,
+) *FineTuningJobWatcher {
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultFineTuningWatchPollInterval
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = defaultFineTuningWatchPageSize
+	}
+	return &FineTuningJobWatcher{
+		client:  c,
+		ctx:     ctx,
+		jobID:   fineTuningJobID,
+		options: options,
+		opts:    opts,
+		seen:    map[string]bool{},
+	}
+}
+
+// Recv returns the next new event of the job, polling until one is available.
+// It returns io.EOF once the job has finished and every event was returned;
+// Job and Results are then available. Use FineTuningJobEvent.Metrics to decode metrics events.
+func (w *FineTuningJobWatcher) Recv() (event FineTuningJobEvent, err error) {
+	for len(w.pending) == 0 {
+		if w.done {
+			err = io.EOF
+			return
+		}
+		if w.polled {
+			err = w.wait()
+			if err != nil {
+				return
+			}
+		}
+		err = w.poll()
+		if err != nil {
+			return
+		}
+	}
+
+	event = w.pending[0]
+	w.pending = w.pending[1:]
+	return
+}
+
+// Job returns the job as of the last poll. After Recv returned io.EOF it is the finished job,
+// whose Error explains a failure.
+func (w *FineTuningJobWatcher) Job() FineTuningJob {
+	return w.job
+}
+
+// Results returns the rows of the result file of a succeeded job, once Recv returned io.EOF.
+func (w *FineTuningJobWatcher) Results() []FineTuningResultRow {
+	return w.results
+}
+
+func (w *FineTuningJobWatcher) wait() error {
+	timer := time.NewTimer(w.options.PollInterval)
+	defer timer.Stop()
+	select {
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// poll retrieves the job before its events, so that the events of a finished job are complete.
+func (w *FineTuningJobWatcher) poll() (err error) {
+	w.polled = true
+	w.job, err = w.client.RetrieveFineTuningJob(w.ctx, w.jobID, w.opts...)
+	if err != nil {
+		return
+	}
+
+	err = w.fetchEvents()
+	if err != nil || !w.job.IsFinished() {
+		return
+	}
+
+	if w.job.Status == FineTuningJobStatusSucceeded && !w.options.SkipResults && len(w.job.ResultFiles) > 0 {
+		w.results, err = w.client.RetrieveFineTuningResults(w.ctx, w.job.ResultFiles[0], w.opts...)
+		if err != nil {
+			return
+		}
+	}
+	w.done = true
+	return
+}
+
+// fetchEvents pages through the events, which the API lists newest first,
+// until it reaches an event that was already seen.
+func (w *FineTuningJobWatcher) fetchEvents() error {
+	var fresh []FineTuningJobEvent
+	setters := []ListFineTuningJobEventsParameter{
+		ListFineTuningJobEventsWithLimit(w.options.PageSize),
+		ListFineTuningJobEventsWithRequestOptions(w.opts...),
+	}
+	for {
+		page, err := w.client.ListFineTuningJobEvents(w.ctx, w.jobID, setters...)
+		if err != nil {
+			return err
+		}
+
+		reachedSeen := false
+		for _, event := range page.Data {
+			if w.seen[event.ID] {
+				reachedSeen = true
+				break
+			}
+			fresh = append(fresh, event)
+		}
+		if reachedSeen || !page.HasMore || len(page.Data) == 0 {
+			break
+		}
+		setters = []ListFineTuningJobEventsParameter{
+			ListFineTuningJobEventsWithLimit(w.options.PageSize),
+			ListFineTuningJobEventsWithAfter(page.Data[len(page.Data)-1].ID),
+			ListFineTuningJobEventsWithRequestOptions(w.opts...),
+		}
+	}
+
+	for i := len(fresh) - 1; i >= 0; i-- {
+		if w.seen[fresh[i].ID] {
+			continue
+		}
+		w.seen[fresh[i].ID] = true
+		w.pending = append(w.pending, fresh[i])
+	}
+	return nil
+}
+
+// FineTuningResultRow is a row of the result file of a fine-tuning job.
+// Validation metrics are nil on steps where they were not computed.
+type FineTuningResultRow struct {
+	Step                       int
+	TrainLoss                  float64
+	TrainAccuracy              float64
+	ValidLoss                  *float64
+	ValidMeanTokenAccuracy     *float64
+	FullValidLoss              *float64
+	FullValidMeanTokenAccuracy *float64
+	// Columns holds every value of the row by column name, including columns not mapped above.
+	Columns map[string]string
+}
+
+// RetrieveFineTuningResults downloads the result file of a fine-tuning job and parses it.
+func (c *Client) RetrieveFineTuningResults(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) (rows []FineTuningResultRow, err error) {
+	content, err := c.GetFileContent(ctx, fileID, opts...)
+	if err != nil {
+		return
+	}
+	defer content.Close()
+
+	return ParseFineTuningResults(content)
+}
+
+// ParseFineTuningResults parses the CSV result file of a fine-tuning job.
+// The API serves the file base64 encoded, which is decoded transparently.
+func ParseFineTuningResults(r io.Reader) ([]FineTuningResultRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	// The base64 alphabet has no comma, while the CSV header always has several columns.
+	if !bytes.Contains(data, []byte(",")) {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if decodeErr == nil {
+			data = decoded
+		}
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	hasStep := false
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+		hasStep = hasStep || header[i] == "step"
+	}
+	if !hasStep {
+		return nil, ErrFineTuningResultsMissingStep
+	}
+
+	rows := make([]FineTuningResultRow, 0, len(records)-1)
+	for line, record := range records[1:] {
+		row, rowErr := parseFineTuningResultRow(header, record)
+		if rowErr != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, rowErr)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseFineTuningResultRow(header, record []string) (row FineTuningResultRow, err error) {
+	row.Columns = make(map[string]string, len(header))
+	for i, name := range header {
+		if i >= len(record) {
+			break
+		}
+		value := strings.TrimSpace(record[i])
+		row.Columns[name] = value
+		if value == "" {
+			continue
+		}
+
+		switch name {
+		case "step":
+			row.Step, err = strconv.Atoi(value)
+		case "train_loss":
+			row.TrainLoss, err = strconv.ParseFloat(value, 64)
+		case "train_accuracy", "train_mean_token_accuracy":
+			row.TrainAccuracy, err = strconv.ParseFloat(value, 64)
+		case "valid_loss":
+			row.ValidLoss, err = parseOptionalFloat(value)
+		case "valid_mean_token_accuracy", "valid_accuracy":
+			row.ValidMeanTokenAccuracy, err = parseOptionalFloat(value)
+		case "full_valid_loss":
+			row.FullValidLoss, err = parseOptionalFloat(value)
+		case "full_valid_mean_token_accuracy":
+			row.FullValidMeanTokenAccuracy, err = parseOptionalFloat(value)
+		}
+		if err != nil {
+			err = fmt.Errorf("column %s: %w", name, err)
+			return
+		}
+	}
+	return
+}
+
+func parseOptionalFloat(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,165 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+const testFineTuningResultsCSV = "step,train_loss,train_accuracy,valid_loss,valid_mean_token_accuracy\n" +
+	"1,1.5,0.5,,\n" +
+	"2,1.25,0.6,1.75,0.55\n"
+
+func TestWatchFineTuningJob(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	// events holds every event of the job, oldest first; each poll of the job reveals more of them.
+	events := []openai.FineTuningJobEvent{
+		{ID: "ev-1", Type: openai.FineTuningJobEventTypeMessage, Message: "Validating training file"},
+		{ID: "ev-2", Type: openai.FineTuningJobEventTypeMessage, Message: "Fine-tuning job started"},
+		{
+			ID:      "ev-3",
+			Type:    openai.FineTuningJobEventTypeMetrics,
+			Message: "Step 1/2: training loss=1.50",
+			Data:    json.RawMessage(`{"step":1,"total_steps":2,"train_loss":1.5,"train_mean_token_accuracy":0.5}`),
+		},
+		{
+			ID:      "ev-4",
+			Type:    openai.FineTuningJobEventTypeMetrics,
+			Message: "Step 2/2: training loss=1.25, validation loss=1.75",
+			Data: json.RawMessage(`{"step":2,"total_steps":2,"train_loss":1.25,` +
+				`"train_mean_token_accuracy":0.6,"valid_loss":1.75,"valid_mean_token_accuracy":0.55}`),
+		},
+		{ID: "ev-5", Type: openai.FineTuningJobEventTypeMessage, Message: "The job has successfully completed"},
+	}
+	visible := []int{2, 2, 5}
+	polls := 0
+
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-watch", func(w http.ResponseWriter, _ *http.Request) {
+		job := openai.FineTuningJob{ID: "ftjob-watch", Status: openai.FineTuningJobStatusRunning}
+		if polls >= len(visible)-1 {
+			job.Status = openai.FineTuningJobStatusSucceeded
+			job.ResultFiles = []string{"file-results"}
+		}
+		polls++
+		resBytes, _ := json.Marshal(job)
+		fmt.Fprintln(w, string(resBytes))
+	})
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-watch/events", func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		after := r.URL.Query().Get("after")
+
+		// The API lists events newest first and pages towards older ones.
+		var page openai.FineTuningJobEventList
+		started := after == ""
+		for i := visible[polls-1] - 1; i >= 0; i-- {
+			if !started {
+				started = events[i].ID == after
+				continue
+			}
+			if len(page.Data) == limit {
+				page.HasMore = true
+				break
+			}
+			page.Data = append(page.Data, events[i])
+		}
+		resBytes, _ := json.Marshal(page)
+		fmt.Fprintln(w, string(resBytes))
+	})
+	server.RegisterHandler("/v1/files/file-results/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, base64.StdEncoding.EncodeToString([]byte(testFineTuningResultsCSV)))
+	})
+
+	watcher := client.WatchFineTuningJob(context.Background(), "ftjob-watch", openai.FineTuningJobWatchOptions{
+		PollInterval: time.Millisecond,
+		PageSize:     2,
+	})
+
+	var ids []string
+	var metrics []openai.FineTuningJobMetrics
+	for {
+		event, err := watcher.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "Recv error")
+		ids = append(ids, event.ID)
+
+		m, err := event.Metrics()
+		if event.Type != openai.FineTuningJobEventTypeMetrics {
+			checks.ErrorIs(t, err, openai.ErrFineTuningJobEventNotMetrics, "Metrics of a message event")
+			continue
+		}
+		checks.NoError(t, err, "Metrics error")
+		metrics = append(metrics, m)
+	}
+
+	if strings.Join(ids, ",") != "ev-1,ev-2,ev-3,ev-4,ev-5" {
+		t.Fatalf("unexpected events %v", ids)
+	}
+	if len(metrics) != 2 || metrics[0].Step != 1 || metrics[0].ValidLoss != nil ||
+		metrics[1].ValidLoss == nil || *metrics[1].ValidLoss != 1.75 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	if watcher.Job().Status != openai.FineTuningJobStatusSucceeded {
+		t.Fatalf("unexpected final status %s", watcher.Job().Status)
+	}
+
+	rows := watcher.Results()
+	if len(rows) != 2 || rows[0].Step != 1 || rows[0].TrainLoss != 1.5 || rows[0].ValidLoss != nil {
+		t.Fatalf("unexpected result rows %+v", rows)
+	}
+	if rows[1].ValidMeanTokenAccuracy == nil || *rows[1].ValidMeanTokenAccuracy != 0.55 ||
+		rows[1].Columns["train_accuracy"] != "0.6" {
+		t.Fatalf("unexpected result row %+v", rows[1])
+	}
+
+	_, err := watcher.Recv()
+	checks.ErrorIs(t, err, io.EOF, "Recv after the job finished")
+}
+
+func TestWatchFineTuningJobContextCanceled(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-watch", func(w http.ResponseWriter, _ *http.Request) {
+		resBytes, _ := json.Marshal(openai.FineTuningJob{ID: "ftjob-watch", Status: openai.FineTuningJobStatusQueued})
+		fmt.Fprintln(w, string(resBytes))
+	})
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-watch/events", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"object":"list","data":[],"has_more":false}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	watcher := client.WatchFineTuningJob(ctx, "ftjob-watch", openai.FineTuningJobWatchOptions{
+		PollInterval: 5 * time.Millisecond,
+	})
+	_, err := watcher.Recv()
+	checks.ErrorIs(t, err, context.DeadlineExceeded, "Recv should stop when the context is done")
+}
+
+func TestParseFineTuningResults(t *testing.T) {
+	rows, err := openai.ParseFineTuningResults(strings.NewReader(testFineTuningResultsCSV))
+	checks.NoError(t, err, "ParseFineTuningResults error")
+	if len(rows) != 2 || rows[1].Step != 2 || rows[1].TrainAccuracy != 0.6 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	_, err = openai.ParseFineTuningResults(strings.NewReader("a,b\n1,2\n"))
+	checks.ErrorIs(t, err, openai.ErrFineTuningResultsMissingStep, "results without a step column")
+
+	_, err = openai.ParseFineTuningResults(strings.NewReader("step,train_loss\n1,abc\n"))
+	checks.HasError(t, err, "invalid number should fail")
+}
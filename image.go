@@ -1,11 +1,15 @@
 package openai
 
 import (
-	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
+
+	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
 )
 
 // Image sizes defined by the OpenAI API.
@@ -69,6 +73,27 @@ const (
 	CreateImageOutputFormatWEBP = "webp"
 )
 
+const (
+	// gpt-image-1 image edits only.
+	CreateImageInputFidelityHigh = "high"
+	CreateImageInputFidelityLow  = "low"
+)
+
+// Limits of the image edit parameters.
+const (
+	imageEditMaxImagesDallE2    = 1
+	imageEditMaxImagesGptImage1 = 16
+	imageMaxOutputCompression   = 100
+)
+
+var (
+	ErrImageEditUnsupportedModel  = errors.New("this model does not support image edits")
+	ErrImageEditTooManyImages     = errors.New("too many input images for this model")
+	ErrImageParameterUnsupported  = errors.New("this parameter is not supported by this model")
+	ErrImageOutputCompression     = errors.New("output_compression must be between 0 and 100 and requires the jpeg or webp output format") //nolint:lll
+	ErrImageTransparentBackground = errors.New("a transparent background requires the png or webp output format")
+)
+
 // ImageRequest represents the request structure for the image API.
 type ImageRequest struct {
 	Prompt            string `json:"prompt,omitempty"`
@@ -83,6 +108,10 @@ type ImageRequest struct {
 	Moderation        string `json:"moderation,omitempty"`
 	OutputCompression int    `json:"output_compression,omitempty"`
 	OutputFormat      string `json:"output_format,omitempty"`
+	// Stream is set by CreateImageStream.
+	Stream bool `json:"stream,omitempty"`
+	// PartialImages is the number of previews streamed before the final image, between 0 and 3.
+	PartialImages int `json:"partial_images,omitempty"`
 }
 
 // ImageResponse represents a response structure for image API.
@@ -116,13 +145,23 @@ type ImageResponseDataInner struct {
 }
 
 // CreateImage - API call to create an image. This is the main endpoint of the DALL-E API.
-func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (response ImageResponse, err error) {
+func (c *Client) CreateImage(
+	ctx context.Context,
+	request ImageRequest,
+	opts ...RequestOption,
+) (response ImageResponse, err error) {
+	if request.Stream {
+		err = ErrImageStreamNotSupported
+		return
+	}
+
 	urlSuffix := "/images/generations"
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
 		withBody(request),
+		withOptions(opts),
 	)
 	if err != nil {
 		return
@@ -132,70 +171,150 @@ func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (respons
 	return
 }
 
+// ImageEditFile is an input image of an image edit. The extension of Name determines
+// the content type the image is uploaded with, so it should match the image format.
+type ImageEditFile struct {
+	Reader io.Reader
+	Name   string
+}
+
 // ImageEditRequest represents the request structure for the image API.
 type ImageEditRequest struct {
-	Image          io.Reader `json:"image,omitempty"`
-	Mask           io.Reader `json:"mask,omitempty"`
-	Prompt         string    `json:"prompt,omitempty"`
-	Model          string    `json:"model,omitempty"`
-	N              int       `json:"n,omitempty"`
-	Size           string    `json:"size,omitempty"`
-	ResponseFormat string    `json:"response_format,omitempty"`
-	Quality        string    `json:"quality,omitempty"`
-	User           string    `json:"user,omitempty"`
+	// Image is a single input image. If it is an *os.File, its name is sent as the filename.
+	Image io.Reader `json:"image,omitempty"`
+	// Images are input images with explicit filenames, sent after Image.
+	// gpt-image-1 accepts up to 16 images, dall-e-2 a single one.
+	Images            []ImageEditFile `json:"-"`
+	Mask              io.Reader       `json:"mask,omitempty"`
+	Prompt            string          `json:"prompt,omitempty"`
+	Model             string          `json:"model,omitempty"`
+	N                 int             `json:"n,omitempty"`
+	Size              string          `json:"size,omitempty"`
+	ResponseFormat    string          `json:"response_format,omitempty"`
+	Quality           string          `json:"quality,omitempty"`
+	User              string          `json:"user,omitempty"`
+	Background        string          `json:"background,omitempty"`
+	OutputFormat      string          `json:"output_format,omitempty"`
+	OutputCompression *int            `json:"output_compression,omitempty"`
+	InputFidelity     string          `json:"input_fidelity,omitempty"`
+	// Stream is set by CreateEditImageStream.
+	Stream bool `json:"stream,omitempty"`
+	// PartialImages is the number of previews streamed before the final image, between 0 and 3.
+	PartialImages int `json:"partial_images,omitempty"`
+
+	// Progress is optionally called as the images are uploaded.
+	Progress UploadProgressFunc `json:"-"`
 }
 
-// CreateEditImage - API call to create an image. This is the main endpoint of the DALL-E API.
-func (c *Client) CreateEditImage(ctx context.Context, request ImageEditRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
-
-	// image, filename is not required
-	err = builder.CreateFormFileReader("image", request.Image, "")
-	if err != nil {
-		return
+// imageFiles returns every input image of the request with its filename.
+func (r ImageEditRequest) imageFiles() []ImageEditFile {
+	files := make([]ImageEditFile, 0, len(r.Images)+1)
+	if r.Image != nil || len(r.Images) == 0 {
+		files = append(files, ImageEditFile{Reader: r.Image, Name: readerFilename(r.Image)})
 	}
+	return append(files, r.Images...)
+}
 
-	// mask, it is optional
-	if request.Mask != nil {
-		// mask, filename is not required
-		err = builder.CreateFormFileReader("mask", request.Mask, "")
-		if err != nil {
-			return
+// readerFilename returns the base name of files opened from disk, or an empty string.
+func readerFilename(r io.Reader) (name string) {
+	f, ok := r.(interface{ Name() string })
+	if !ok {
+		return ""
+	}
+	// Name panics on a zero os.File, which has no name.
+	defer func() {
+		if recover() != nil {
+			name = ""
 		}
+	}()
+	if name = f.Name(); name == "" {
+		return ""
 	}
+	return filepath.Base(name)
+}
 
-	err = builder.WriteField("prompt", request.Prompt)
-	if err != nil {
-		return
+// Validate checks the request against the parameters supported by its model.
+// Models that are not known to this library are not checked.
+func (r ImageEditRequest) Validate() error {
+	images := len(r.imageFiles())
+	model := r.Model
+	if model == "" && r.gptImage1Parameter() != "" {
+		// The API uses gpt-image-1 when the request has parameters only gpt-image-1 supports.
+		model = CreateImageModelGptImage1
 	}
-
-	err = builder.WriteField("n", strconv.Itoa(request.N))
-	if err != nil {
-		return
+	switch model {
+	case "", CreateImageModelDallE2:
+		if images > imageEditMaxImagesDallE2 {
+			return fmt.Errorf("%w: %s accepts %d image, got %d",
+				ErrImageEditTooManyImages, CreateImageModelDallE2, imageEditMaxImagesDallE2, images)
+		}
+		if param := r.gptImage1Parameter(); param != "" {
+			return fmt.Errorf("%w: %s with %s", ErrImageParameterUnsupported, param, CreateImageModelDallE2)
+		}
+	case CreateImageModelDallE3:
+		return fmt.Errorf("%w: %s", ErrImageEditUnsupportedModel, r.Model)
+	case CreateImageModelGptImage1:
+		if images > imageEditMaxImagesGptImage1 {
+			return fmt.Errorf("%w: %s accepts %d images, got %d",
+				ErrImageEditTooManyImages, model, imageEditMaxImagesGptImage1, images)
+		}
+		if r.ResponseFormat != "" {
+			return fmt.Errorf("%w: response_format with %s, which always returns b64_json",
+				ErrImageParameterUnsupported, model)
+		}
+		if r.OutputCompression != nil && (*r.OutputCompression < 0 ||
+			*r.OutputCompression > imageMaxOutputCompression ||
+			(r.OutputFormat != CreateImageOutputFormatJPEG && r.OutputFormat != CreateImageOutputFormatWEBP)) {
+			return ErrImageOutputCompression
+		}
+		if r.Background == CreateImageBackgroundTransparent && r.OutputFormat == CreateImageOutputFormatJPEG {
+			return ErrImageTransparentBackground
+		}
 	}
+	return nil
+}
 
-	err = builder.WriteField("size", request.Size)
-	if err != nil {
-		return
+// gptImage1Parameter returns the name of the first parameter of the request which only gpt-image-1 supports,
+// or an empty string.
+func (r ImageEditRequest) gptImage1Parameter() string {
+	switch {
+	case r.Background != "":
+		return "background"
+	case r.OutputFormat != "":
+		return "output_format"
+	case r.OutputCompression != nil:
+		return "output_compression"
+	case r.InputFidelity != "":
+		return "input_fidelity"
+	case r.Quality != "" && r.Quality != CreateImageQualityStandard:
+		return fmt.Sprintf("quality %q", r.Quality)
 	}
+	return ""
+}
 
-	err = builder.WriteField("response_format", request.ResponseFormat)
-	if err != nil {
+// CreateEditImage - API call to create an image. This is the main endpoint of the DALL-E API.
+func (c *Client) CreateEditImage(
+	ctx context.Context,
+	request ImageEditRequest,
+	opts ...RequestOption,
+) (response ImageResponse, err error) {
+	if request.Stream {
+		err = ErrImageStreamNotSupported
 		return
 	}
 
-	err = builder.Close()
+	err = request.Validate()
 	if err != nil {
 		return
 	}
 
-	req, err := c.newRequest(
+	req, err := c.newMultipartRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL("/images/edits", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
+		request.multipartForm,
+		request.Progress,
+		withOptions(opts),
 	)
 	if err != nil {
 		return
@@ -205,6 +324,77 @@ func (c *Client) CreateEditImage(ctx context.Context, request ImageEditRequest)
 	return
 }
 
+func (r ImageEditRequest) multipartForm(builder utils.FormBuilder) error {
+	// a single image is sent as "image", several images as "image[]"
+	images := r.imageFiles()
+	fieldname := "image"
+	if len(images) > 1 {
+		fieldname = "image[]"
+	}
+	for _, image := range images {
+		err := builder.CreateFormFileReader(fieldname, image.Reader, image.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// mask, it is optional
+	if r.Mask != nil {
+		err := builder.CreateFormFileReader("mask", r.Mask, readerFilename(r.Mask))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := builder.WriteField("prompt", r.Prompt)
+	if err != nil {
+		return err
+	}
+
+	err = builder.WriteField("n", strconv.Itoa(r.N))
+	if err != nil {
+		return err
+	}
+
+	err = builder.WriteField("size", r.Size)
+	if err != nil {
+		return err
+	}
+
+	err = builder.WriteField("response_format", r.ResponseFormat)
+	if err != nil {
+		return err
+	}
+
+	optional := []struct{ name, value string }{
+		{"model", r.Model},
+		{"quality", r.Quality},
+		{"user", r.User},
+		{"background", r.Background},
+		{"output_format", r.OutputFormat},
+		{"input_fidelity", r.InputFidelity},
+	}
+	if r.OutputCompression != nil {
+		optional = append(optional, struct{ name, value string }{"output_compression", strconv.Itoa(*r.OutputCompression)})
+	}
+	if r.Stream {
+		optional = append(optional,
+			struct{ name, value string }{"stream", "true"},
+			struct{ name, value string }{"partial_images", strconv.Itoa(r.PartialImages)})
+	}
+	for _, field := range optional {
+		if field.value == "" {
+			continue
+		}
+		err = builder.WriteField(field.name, field.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return builder.Close()
+}
+
 // ImageVariRequest represents the request structure for the image API.
 type ImageVariRequest struct {
 	Image          io.Reader `json:"image,omitempty"`
@@ -213,51 +403,55 @@ type ImageVariRequest struct {
 	Size           string    `json:"size,omitempty"`
 	ResponseFormat string    `json:"response_format,omitempty"`
 	User           string    `json:"user,omitempty"`
+
+	// Progress is optionally called as the image is uploaded.
+	Progress UploadProgressFunc `json:"-"`
 }
 
 // CreateVariImage - API call to create an image variation. This is the main endpoint of the DALL-E API.
 // Use abbreviations(vari for variation) because ci-lint has a single-line length limit ...
-func (c *Client) CreateVariImage(ctx context.Context, request ImageVariRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
-
-	// image, filename is not required
-	err = builder.CreateFormFileReader("image", request.Image, "")
+func (c *Client) CreateVariImage(
+	ctx context.Context,
+	request ImageVariRequest,
+	opts ...RequestOption,
+) (response ImageResponse, err error) {
+	req, err := c.newMultipartRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/variations", withModel(request.Model)),
+		request.multipartForm,
+		request.Progress,
+		withOptions(opts),
+	)
 	if err != nil {
 		return
 	}
 
-	err = builder.WriteField("n", strconv.Itoa(request.N))
-	if err != nil {
-		return
-	}
+	err = c.sendRequest(req, &response)
+	return
+}
 
-	err = builder.WriteField("size", request.Size)
+func (r ImageVariRequest) multipartForm(builder utils.FormBuilder) error {
+	// image, filename is not required
+	err := builder.CreateFormFileReader("image", r.Image, "")
 	if err != nil {
-		return
+		return err
 	}
 
-	err = builder.WriteField("response_format", request.ResponseFormat)
+	err = builder.WriteField("n", strconv.Itoa(r.N))
 	if err != nil {
-		return
+		return err
 	}
 
-	err = builder.Close()
+	err = builder.WriteField("size", r.Size)
 	if err != nil {
-		return
+		return err
 	}
 
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL("/images/variations", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
+	err = builder.WriteField("response_format", r.ResponseFormat)
 	if err != nil {
-		return
+		return err
 	}
 
-	err = c.sendRequest(req, &response)
-	return
+	return builder.Close()
 }
@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"gitlab.forensix.cn/ai/service/go-openai"
+	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
 	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
 )
 
@@ -131,6 +133,146 @@ func TestImageEditWithoutMask(t *testing.T) {
 	checks.NoError(t, err, "CreateImage error")
 }
 
+func TestImageEditMultipleImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+
+		images := r.MultipartForm.File["image[]"]
+		if len(images) != 3 {
+			t.Fatalf("expected 3 images, got %d", len(images))
+		}
+		expected := []struct{ name, contentType string }{
+			{"base.png", "image/png"},
+			{"lotion.jpg", "image/jpeg"},
+			{"soap.webp", "image/webp"},
+		}
+		for i, image := range images {
+			if image.Filename != expected[i].name || image.Header.Get("Content-Type") != expected[i].contentType {
+				t.Errorf("image %d: unexpected filename %q and content type %q",
+					i, image.Filename, image.Header.Get("Content-Type"))
+			}
+		}
+		for field, value := range map[string]string{
+			"model":              openai.CreateImageModelGptImage1,
+			"quality":            openai.CreateImageQualityHigh,
+			"user":               "user-1",
+			"background":         openai.CreateImageBackgroundTransparent,
+			"output_format":      openai.CreateImageOutputFormatWEBP,
+			"output_compression": "80",
+			"input_fidelity":     openai.CreateImageInputFidelityHigh,
+		} {
+			if got := r.FormValue(field); got != value {
+				t.Errorf("field %s: expected %q, got %q", field, value, got)
+			}
+		}
+		fmt.Fprintln(w, `{"created":1,"data":[{"b64_json":"aW1hZ2U="}]}`)
+	})
+
+	base, err := os.Create(filepath.Join(t.TempDir(), "base.png"))
+	checks.NoError(t, err, "create image file error")
+	defer base.Close()
+
+	compression := 80
+	_, err = client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image: base,
+		Images: []openai.ImageEditFile{
+			{Reader: strings.NewReader("jpeg"), Name: "lotion.jpg"},
+			{Reader: strings.NewReader("webp"), Name: "soap.webp"},
+		},
+		Prompt:            "A gift basket with these items",
+		Model:             openai.CreateImageModelGptImage1,
+		Quality:           openai.CreateImageQualityHigh,
+		User:              "user-1",
+		Background:        openai.CreateImageBackgroundTransparent,
+		OutputFormat:      openai.CreateImageOutputFormatWEBP,
+		OutputCompression: &compression,
+		InputFidelity:     openai.CreateImageInputFidelityHigh,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+}
+
+func TestImageEditValidation(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	two := []openai.ImageEditFile{
+		{Reader: strings.NewReader("a"), Name: "a.png"},
+		{Reader: strings.NewReader("b"), Name: "b.png"},
+	}
+	compression := 50
+	tooHigh := 101
+	testCases := []struct {
+		name    string
+		request openai.ImageEditRequest
+		err     error
+	}{
+		{"dall-e-2 multiple images", openai.ImageEditRequest{Images: two}, openai.ErrImageEditTooManyImages},
+		{"dall-e-2 output format", openai.ImageEditRequest{
+			Model:        openai.CreateImageModelDallE2,
+			OutputFormat: openai.CreateImageOutputFormatPNG,
+		}, openai.ErrImageParameterUnsupported},
+		{"dall-e-2 quality", openai.ImageEditRequest{
+			Model:   openai.CreateImageModelDallE2,
+			Quality: openai.CreateImageQualityHigh,
+		}, openai.ErrImageParameterUnsupported},
+		{"default model with gpt-image-1 parameters", openai.ImageEditRequest{
+			Background:   openai.CreateImageBackgroundTransparent,
+			OutputFormat: openai.CreateImageOutputFormatJPEG,
+		}, openai.ErrImageTransparentBackground},
+		{"dall-e-3", openai.ImageEditRequest{Model: openai.CreateImageModelDallE3},
+			openai.ErrImageEditUnsupportedModel},
+		{"gpt-image-1 too many images", openai.ImageEditRequest{
+			Model:  openai.CreateImageModelGptImage1,
+			Images: make([]openai.ImageEditFile, 17),
+		}, openai.ErrImageEditTooManyImages},
+		{"gpt-image-1 response format", openai.ImageEditRequest{
+			Model:          openai.CreateImageModelGptImage1,
+			ResponseFormat: openai.CreateImageResponseFormatURL,
+		}, openai.ErrImageParameterUnsupported},
+		{"gpt-image-1 compression with png", openai.ImageEditRequest{
+			Model:             openai.CreateImageModelGptImage1,
+			OutputCompression: &compression,
+		}, openai.ErrImageOutputCompression},
+		{"gpt-image-1 compression out of range", openai.ImageEditRequest{
+			Model:             openai.CreateImageModelGptImage1,
+			OutputFormat:      openai.CreateImageOutputFormatJPEG,
+			OutputCompression: &tooHigh,
+		}, openai.ErrImageOutputCompression},
+		{"gpt-image-1 transparent jpeg", openai.ImageEditRequest{
+			Model:        openai.CreateImageModelGptImage1,
+			Background:   openai.CreateImageBackgroundTransparent,
+			OutputFormat: openai.CreateImageOutputFormatJPEG,
+		}, openai.ErrImageTransparentBackground},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CreateEditImage(context.Background(), tc.request)
+			checks.ErrorIs(t, err, tc.err, "CreateEditImage should fail validation")
+		})
+	}
+
+	checks.NoError(t, openai.ImageEditRequest{Model: openai.CreateImageModelGptImage1, Images: two}.Validate(),
+		"gpt-image-1 accepts multiple images")
+	checks.NoError(t, openai.ImageEditRequest{
+		Quality:       openai.CreateImageQualityHigh,
+		InputFidelity: openai.CreateImageInputFidelityHigh,
+		OutputFormat:  openai.CreateImageOutputFormatWEBP,
+	}.Validate(), "the API uses gpt-image-1 for its parameters when the model is not set")
+}
+
+func TestImageEditNilImage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", handleEditImageEndpoint)
+
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{Prompt: "test"})
+	checks.ErrorIs(t, err, utils.ErrFormSourceNil, "CreateEditImage should fail for a nil image")
+	_, err = client.CreateVariImage(context.Background(), openai.ImageVariRequest{})
+	checks.ErrorIs(t, err, utils.ErrFormSourceNil, "CreateVariImage should fail for a nil image")
+}
+
 // handleEditImageEndpoint Handles the images endpoint by the test server.
 func handleEditImageEndpoint(w http.ResponseWriter, r *http.Request) {
 	var resBytes []byte
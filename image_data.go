@@ -0,0 +1,179 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF decoder for DecodeImage
+	_ "image/jpeg" // register the JPEG decoder for DecodeImage
+	_ "image/png"  // register the PNG decoder for DecodeImage
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Image formats detected from the content of an image.
+const (
+	ImageFormatPNG  = "png"
+	ImageFormatJPEG = "jpeg"
+	ImageFormatWEBP = "webp"
+	ImageFormatGIF  = "gif"
+)
+
+var (
+	ErrImageDataEmpty         = errors.New("image data has neither b64_json nor url")
+	ErrImageFormatUnknown     = errors.New("image format could not be detected")
+	ErrImageDecodeUnsupported = errors.New("decoding this image format is not supported")
+)
+
+// imageFormatSignatures are the magic bytes at the start of each image format.
+// WEBP files are RIFF containers and are checked separately.
+var imageFormatSignatures = []struct {
+	format    string
+	signature []byte
+}{
+	{ImageFormatPNG, []byte("\x89PNG\r\n\x1a\n")},
+	{ImageFormatJPEG, []byte{0xFF, 0xD8, 0xFF}},
+	{ImageFormatGIF, []byte("GIF87a")},
+	{ImageFormatGIF, []byte("GIF89a")},
+}
+
+// DetectImageFormat returns the format of an image from its magic bytes,
+// or an empty string if it is not a PNG, JPEG, WEBP or GIF image.
+func DetectImageFormat(data []byte) string {
+	for _, f := range imageFormatSignatures {
+		if bytes.HasPrefix(data, f.signature) {
+			return f.format
+		}
+	}
+	if len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")) {
+		return ImageFormatWEBP
+	}
+	return ""
+}
+
+// ImageFormatMIMEType returns the MIME type of an image format.
+func ImageFormatMIMEType(format string) string {
+	return "image/" + format
+}
+
+// ImageFormatExtension returns the file extension of an image format, including the dot.
+func ImageFormatExtension(format string) string {
+	if format == ImageFormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// DecodeB64 returns the bytes of an image returned as b64_json.
+func (d ImageResponseDataInner) DecodeB64() ([]byte, error) {
+	if d.B64JSON == "" {
+		return nil, ErrImageDataEmpty
+	}
+	return base64.StdEncoding.DecodeString(d.B64JSON)
+}
+
+// ImageBytes returns the bytes of a generated image. Images returned as b64_json are decoded,
+// and images returned as a URL are downloaded through the HTTPClient of the client configuration.
+func (c *Client) ImageBytes(ctx context.Context, data ImageResponseDataInner, opts ...RequestOption) ([]byte, error) {
+	if data.B64JSON != "" || data.URL == "" {
+		return data.DecodeB64()
+	}
+
+	// The URL is pre-signed, so the request must not carry the API credentials and its host is kept.
+	// Only the headers, query parameters and timeout of the options apply.
+	args := newRequestOptions(opts)
+	imageURL, err := args.withQuery(data.URL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := args.context(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range args.Header {
+		req.Header[key] = values
+	}
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if isFailureStatusCode(resp) {
+		return nil, c.handleErrorResp(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// DecodeImage returns a generated image decoded as an image.Image, along with its format.
+// PNG, JPEG and GIF images can be decoded; WEBP images are detected but
+// return ErrImageDecodeUnsupported unless a WEBP decoder is registered with the image package.
+func (c *Client) DecodeImage(
+	ctx context.Context,
+	data ImageResponseDataInner,
+	opts ...RequestOption,
+) (image.Image, string, error) {
+	content, err := c.ImageBytes(ctx, data, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(content))
+	if errors.Is(err, image.ErrFormat) {
+		detected := DetectImageFormat(content)
+		if detected == "" {
+			return nil, "", ErrImageFormatUnknown
+		}
+		return nil, detected, fmt.Errorf("%w: %s", ErrImageDecodeUnsupported, detected)
+	}
+	return img, format, err
+}
+
+// SaveImage writes a generated image to path, with the extension of the detected format
+// appended, and returns the name of the written file.
+func (c *Client) SaveImage(
+	ctx context.Context,
+	data ImageResponseDataInner,
+	path string,
+	opts ...RequestOption,
+) (string, error) {
+	content, err := c.ImageBytes(ctx, data, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	format := DetectImageFormat(content)
+	if format == "" {
+		return "", ErrImageFormatUnknown
+	}
+	name := path + ImageFormatExtension(format)
+	return name, os.WriteFile(name, content, 0o644) //nolint:gosec // generated images are not sensitive
+}
+
+// ImageURLFromBytes returns an image part of a chat message holding the image inline as a data URL.
+func ImageURLFromBytes(data []byte, detail ImageURLDetail) (*ChatMessageImageURL, error) {
+	format := DetectImageFormat(data)
+	if format == "" {
+		return nil, ErrImageFormatUnknown
+	}
+	return &ChatMessageImageURL{
+		URL:    "data:" + ImageFormatMIMEType(format) + ";base64," + base64.StdEncoding.EncodeToString(data),
+		Detail: detail,
+	}, nil
+}
+
+// ImageURLFromFile reads a local image and returns it as a data URL for chat vision requests.
+func ImageURLFromFile(path string, detail ImageURLDetail) (*ChatMessageImageURL, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	return ImageURLFromBytes(data, detail)
+}
@@ -0,0 +1,154 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+type stubImageDoer struct {
+	requests []*http.Request
+	status   int
+	body     []byte
+}
+
+func (d *stubImageDoer) Do(req *http.Request) (*http.Response, error) {
+	d.requests = append(d.requests, req)
+	return &http.Response{
+		StatusCode: d.status,
+		Status:     http.StatusText(d.status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(d.body)),
+	}, nil
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	checks.NoError(t, png.Encode(&buf, img), "png.Encode error")
+	return buf.Bytes()
+}
+
+func TestDetectImageFormat(t *testing.T) {
+	for format, data := range map[string][]byte{
+		openai.ImageFormatPNG:  testPNG(t),
+		openai.ImageFormatJPEG: {0xFF, 0xD8, 0xFF, 0xE0},
+		openai.ImageFormatWEBP: []byte("RIFF\x00\x00\x00\x00WEBPVP8 "),
+		openai.ImageFormatGIF:  []byte("GIF89a..."),
+		"":                     []byte("not an image"),
+	} {
+		if got := openai.DetectImageFormat(data); got != format {
+			t.Errorf("expected %q, got %q", format, got)
+		}
+	}
+	if openai.ImageFormatExtension(openai.ImageFormatJPEG) != ".jpg" ||
+		openai.ImageFormatMIMEType(openai.ImageFormatWEBP) != "image/webp" {
+		t.Error("unexpected extension or MIME type")
+	}
+}
+
+func TestImageBytesAndDecode(t *testing.T) {
+	pngData := testPNG(t)
+	doer := &stubImageDoer{status: http.StatusOK, body: pngData}
+	config := openai.DefaultConfig("secret")
+	config.HTTPClient = doer
+	client := openai.NewClientWithConfig(config)
+	ctx := context.Background()
+
+	b64 := openai.ImageResponseDataInner{B64JSON: base64.StdEncoding.EncodeToString(pngData)}
+	data, err := client.ImageBytes(ctx, b64)
+	checks.NoError(t, err, "ImageBytes b64 error")
+	if !bytes.Equal(data, pngData) || len(doer.requests) != 0 {
+		t.Fatal("b64 images should be decoded without any request")
+	}
+
+	url := openai.ImageResponseDataInner{URL: "https://images.example.com/img.png?sig=1"}
+	img, format, err := client.DecodeImage(ctx, url)
+	checks.NoError(t, err, "DecodeImage error")
+	if format != openai.ImageFormatPNG || img.Bounds().Dx() != 2 || img.Bounds().Dy() != 3 {
+		t.Fatalf("unexpected image %s %v", format, img.Bounds())
+	}
+	if len(doer.requests) != 1 || doer.requests[0].Header.Get("Authorization") != "" {
+		t.Fatal("URL images should be fetched once without credentials")
+	}
+
+	_, err = client.ImageBytes(ctx, url,
+		openai.WithBaseURL("https://proxy.example.com/v1"), openai.WithQueryParam("trace", "1"))
+	checks.NoError(t, err, "ImageBytes error")
+	if got := doer.requests[1].URL.String(); got != "https://images.example.com/img.png?sig=1&trace=1" {
+		t.Errorf("the pre-signed URL should only get the query parameters of the options, got %s", got)
+	}
+
+	doer.body = []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+	_, format, err = client.DecodeImage(ctx, url)
+	checks.ErrorIs(t, err, openai.ErrImageDecodeUnsupported, "WEBP cannot be decoded")
+	if format != openai.ImageFormatWEBP {
+		t.Fatalf("expected webp, got %q", format)
+	}
+
+	doer.status = http.StatusForbidden
+	doer.body = []byte("<Error>AuthenticationFailed</Error>")
+	_, err = client.ImageBytes(ctx, url)
+	var reqErr *openai.RequestError
+	if !errors.As(err, &reqErr) || reqErr.HTTPStatusCode != http.StatusForbidden {
+		t.Fatalf("expected a RequestError, got %v", err)
+	}
+
+	_, err = client.ImageBytes(ctx, openai.ImageResponseDataInner{})
+	checks.ErrorIs(t, err, openai.ErrImageDataEmpty, "empty image data")
+}
+
+func TestSaveImage(t *testing.T) {
+	client := openai.NewClient("")
+	pngData := testPNG(t)
+	data := openai.ImageResponseDataInner{B64JSON: base64.StdEncoding.EncodeToString(pngData)}
+
+	name, err := client.SaveImage(context.Background(), data, filepath.Join(t.TempDir(), "result"))
+	checks.NoError(t, err, "SaveImage error")
+	if !strings.HasSuffix(name, "result.png") {
+		t.Fatalf("unexpected file name %s", name)
+	}
+	written, err := os.ReadFile(name)
+	checks.NoError(t, err, "ReadFile error")
+	if !bytes.Equal(written, pngData) {
+		t.Fatal("unexpected file content")
+	}
+
+	data.B64JSON = base64.StdEncoding.EncodeToString([]byte("text"))
+	_, err = client.SaveImage(context.Background(), data, filepath.Join(t.TempDir(), "result"))
+	checks.ErrorIs(t, err, openai.ErrImageFormatUnknown, "unknown formats are not written")
+}
+
+func TestImageURLFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo")
+	pngData := testPNG(t)
+	checks.NoError(t, os.WriteFile(path, pngData, 0o600), "WriteFile error")
+
+	imageURL, err := openai.ImageURLFromFile(path, openai.ImageURLDetailLow)
+	checks.NoError(t, err, "ImageURLFromFile error")
+	expected := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	if imageURL.URL != expected || imageURL.Detail != openai.ImageURLDetailLow {
+		t.Fatalf("unexpected image URL %+v", imageURL)
+	}
+
+	_, err = openai.ImageURLFromBytes([]byte("text"), openai.ImageURLDetailAuto)
+	checks.ErrorIs(t, err, openai.ErrImageFormatUnknown, "unknown formats are rejected")
+
+	_, err = openai.ImageURLFromFile(filepath.Join(t.TempDir(), "missing.png"), openai.ImageURLDetailAuto)
+	checks.HasError(t, err, "missing files fail")
+}
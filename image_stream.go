@@ -0,0 +1,153 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+)
+
+// Image stream event types.
+const (
+	ImageStreamEventGenerationPartialImage = "image_generation.partial_image"
+	ImageStreamEventGenerationCompleted    = "image_generation.completed"
+	ImageStreamEventEditPartialImage       = "image_edit.partial_image"
+	ImageStreamEventEditCompleted          = "image_edit.completed"
+)
+
+const imageMaxPartialImages = 3
+
+var (
+	ErrImageStreamNotSupported     = errors.New("streaming is not supported with this method, please use CreateImageStream or CreateEditImageStream") //nolint:lll
+	ErrImageStreamUnsupportedModel = errors.New("image streaming is only supported by gpt-image-1")
+	ErrImagePartialImagesInvalid   = errors.New("partial_images must be between 0 and 3")
+)
+
+// ImageStreamEvent is an event of a streamed image generation or edit.
+// Partial image events carry a preview of the image being generated;
+// the completed event carries the final image and the token usage.
+type ImageStreamEvent struct {
+	Type              string              `json:"type"`
+	B64JSON           string              `json:"b64_json"`
+	CreatedAt         int64               `json:"created_at"`
+	Size              string              `json:"size"`
+	Quality           string              `json:"quality"`
+	Background        string              `json:"background"`
+	OutputFormat      string              `json:"output_format"`
+	PartialImageIndex int                 `json:"partial_image_index"`
+	Usage             *ImageResponseUsage `json:"usage,omitempty"`
+
+	// Image holds the decoded bytes of B64JSON, in OutputFormat.
+	Image []byte `json:"-"`
+}
+
+// IsPartial reports whether the event is a partial image preview.
+func (e ImageStreamEvent) IsPartial() bool {
+	return e.Type == ImageStreamEventGenerationPartialImage || e.Type == ImageStreamEventEditPartialImage
+}
+
+// IsCompleted reports whether the event carries the final image.
+func (e ImageStreamEvent) IsCompleted() bool {
+	return e.Type == ImageStreamEventGenerationCompleted || e.Type == ImageStreamEventEditCompleted
+}
+
+// ImageStream is a stream of image events. Recv returns io.EOF after the completed event.
+type ImageStream struct {
+	*streamReader[ImageStreamEvent]
+}
+
+// Recv returns the next event with its image decoded.
+func (stream *ImageStream) Recv() (event ImageStreamEvent, err error) {
+	event, err = stream.streamReader.Recv()
+	if err != nil {
+		return
+	}
+	if event.B64JSON != "" {
+		event.Image, err = base64.StdEncoding.DecodeString(event.B64JSON)
+	}
+	return
+}
+
+func validateImageStream(model string, partialImages int) error {
+	if model != CreateImageModelGptImage1 {
+		return ErrImageStreamUnsupportedModel
+	}
+	if partialImages < 0 || partialImages > imageMaxPartialImages {
+		return ErrImagePartialImagesInvalid
+	}
+	return nil
+}
+
+// CreateImageStream - API call to generate an image with gpt-image-1, streaming
+// up to PartialImages previews before the final image.
+func (c *Client) CreateImageStream(
+	ctx context.Context,
+	request ImageRequest,
+	opts ...RequestOption,
+) (stream *ImageStream, err error) {
+	err = validateImageStream(request.Model, request.PartialImages)
+	if err != nil {
+		return
+	}
+
+	request.Stream = true
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/generations", withModel(request.Model)),
+		withBody(request),
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[ImageStreamEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &ImageStream{
+		streamReader: resp,
+	}
+	return
+}
+
+// CreateEditImageStream - API call to edit images with gpt-image-1, streaming
+// up to PartialImages previews before the final image.
+func (c *Client) CreateEditImageStream(
+	ctx context.Context,
+	request ImageEditRequest,
+	opts ...RequestOption,
+) (stream *ImageStream, err error) {
+	err = validateImageStream(request.Model, request.PartialImages)
+	if err != nil {
+		return
+	}
+
+	request.Stream = true
+	err = request.Validate()
+	if err != nil {
+		return
+	}
+
+	req, err := c.newMultipartRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/edits", withModel(request.Model)),
+		request.multipartForm,
+		request.Progress,
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[ImageStreamEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &ImageStream{
+		streamReader: resp,
+	}
+	return
+}
@@ -0,0 +1,140 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func writeImageStreamEvents(t *testing.T, w http.ResponseWriter, prefix string, partials int) {
+	t.Helper()
+	w.Header().Set("Content-Type", "text/event-stream")
+
+	var body strings.Builder
+	for i := 0; i < partials; i++ {
+		event := openai.ImageStreamEvent{
+			Type:              prefix + ".partial_image",
+			B64JSON:           base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("partial-%d", i))),
+			OutputFormat:      openai.CreateImageOutputFormatPNG,
+			PartialImageIndex: i,
+		}
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(&body, "event: %s\ndata: %s\n\n", event.Type, data)
+	}
+	completed := openai.ImageStreamEvent{
+		Type:         prefix + ".completed",
+		B64JSON:      base64.StdEncoding.EncodeToString([]byte("final")),
+		OutputFormat: openai.CreateImageOutputFormatPNG,
+		Usage:        &openai.ImageResponseUsage{TotalTokens: 100},
+	}
+	data, _ := json.Marshal(completed)
+	fmt.Fprintf(&body, "event: %s\ndata: %s\n\n", completed.Type, data)
+
+	_, err := w.Write([]byte(body.String()))
+	checks.NoError(t, err, "Write error")
+}
+
+func readImageStream(t *testing.T, stream *openai.ImageStream) []openai.ImageStreamEvent {
+	t.Helper()
+	defer stream.Close()
+
+	var events []openai.ImageStreamEvent
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		checks.NoError(t, err, "Recv error")
+		events = append(events, event)
+	}
+}
+
+func TestCreateImageStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ImageRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if !request.Stream || request.PartialImages != 2 {
+			t.Errorf("unexpected stream %v and partial images %d", request.Stream, request.PartialImages)
+		}
+		writeImageStreamEvents(t, w, "image_generation", request.PartialImages)
+	})
+
+	stream, err := client.CreateImageStream(context.Background(), openai.ImageRequest{
+		Prompt:        "A lighthouse at dawn",
+		Model:         openai.CreateImageModelGptImage1,
+		PartialImages: 2,
+	})
+	checks.NoError(t, err, "CreateImageStream error")
+
+	events := readImageStream(t, stream)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for i, event := range events[:2] {
+		if !event.IsPartial() || event.PartialImageIndex != i || string(event.Image) != fmt.Sprintf("partial-%d", i) {
+			t.Errorf("unexpected partial event %+v", event)
+		}
+	}
+	final := events[2]
+	if !final.IsCompleted() || string(final.Image) != "final" || final.Usage == nil || final.Usage.TotalTokens != 100 {
+		t.Errorf("unexpected completed event %+v", final)
+	}
+}
+
+func TestCreateEditImageStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		if r.FormValue("stream") != "true" || r.FormValue("partial_images") != "1" {
+			t.Errorf("unexpected stream %q and partial images %q", r.FormValue("stream"), r.FormValue("partial_images"))
+		}
+		writeImageStreamEvents(t, w, "image_edit", 1)
+	})
+
+	stream, err := client.CreateEditImageStream(context.Background(), openai.ImageEditRequest{
+		Images:        []openai.ImageEditFile{{Reader: strings.NewReader("png"), Name: "room.png"}},
+		Prompt:        "Add a plant",
+		Model:         openai.CreateImageModelGptImage1,
+		PartialImages: 1,
+	})
+	checks.NoError(t, err, "CreateEditImageStream error")
+
+	events := readImageStream(t, stream)
+	if len(events) != 2 || events[0].Type != openai.ImageStreamEventEditPartialImage ||
+		events[1].Type != openai.ImageStreamEventEditCompleted {
+		t.Fatalf("unexpected events %+v", events)
+	}
+}
+
+func TestImageStreamValidation(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+	ctx := context.Background()
+
+	_, err := client.CreateImageStream(ctx, openai.ImageRequest{Model: openai.CreateImageModelDallE3})
+	checks.ErrorIs(t, err, openai.ErrImageStreamUnsupportedModel, "dall-e-3 cannot stream")
+
+	_, err = client.CreateImageStream(ctx, openai.ImageRequest{Model: openai.CreateImageModelGptImage1, PartialImages: 4})
+	checks.ErrorIs(t, err, openai.ErrImagePartialImagesInvalid, "at most 3 partial images")
+
+	_, err = client.CreateEditImageStream(ctx, openai.ImageEditRequest{Model: openai.CreateImageModelDallE2})
+	checks.ErrorIs(t, err, openai.ErrImageStreamUnsupportedModel, "dall-e-2 cannot stream")
+
+	_, err = client.CreateImage(ctx, openai.ImageRequest{Stream: true})
+	checks.ErrorIs(t, err, openai.ErrImageStreamNotSupported, "CreateImage cannot stream")
+
+	_, err = client.CreateEditImage(ctx, openai.ImageEditRequest{Stream: true})
+	checks.ErrorIs(t, err, openai.ErrImageStreamNotSupported, "CreateEditImage cannot stream")
+}
@@ -3,6 +3,7 @@ package openai
 import (
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/textproto"
 	"os"
@@ -38,9 +39,43 @@ func escapeQuotes(s string) string {
 	return quoteEscaper.Replace(s)
 }
 
+// ContentTypeByFilename returns the content type of a file based on its extension,
+// or an empty string if the extension is unknown.
+func ContentTypeByFilename(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch ext {
+	case "":
+		return ""
+	case ".jsonl":
+		return "application/jsonl"
+	case ".json":
+		return "application/json"
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".webp":
+		return "image/webp"
+	case ".mp3", ".mpga", ".mpeg":
+		return "audio/mpeg"
+	case ".wav":
+		return "audio/wav"
+	case ".m4a":
+		return "audio/mp4"
+	case ".webm":
+		return "audio/webm"
+	case ".flac":
+		return "audio/flac"
+	case ".ogg":
+		return "audio/ogg"
+	}
+	return mime.TypeByExtension(ext)
+}
+
 // CreateFormFileReader creates a form field with a file reader.
 // The filename in parameters can be an empty string.
 // The filename in Content-Disposition is required, But it can be an empty string.
+// The Content-Type of the part is derived from the extension of the filename when it is known.
 func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
 	h := make(textproto.MIMEHeader)
 	h.Set(
@@ -51,6 +86,9 @@ func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader
 			escapeQuotes(filepath.Base(filename)),
 		),
 	)
+	if contentType := ContentTypeByFilename(filename); contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
 
 	fieldWriter, err := fb.writer.CreatePart(h)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"errors"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -72,3 +73,29 @@ func TestFormBuilderWithReader(t *testing.T) {
 	err = builder.CreateFormFileReader("file", successReader, "")
 	checks.NoError(t, err, "formbuilder should not return error")
 }
+
+func TestFormBuilderContentType(t *testing.T) {
+	body := &bytes.Buffer{}
+	builder := NewFormBuilder(body)
+	checks.NoError(t, builder.CreateFormFileReader("image", bytes.NewReader(nil), "dir/photo.JPG"), "image part")
+	checks.NoError(t, builder.CreateFormFileReader("file", bytes.NewReader(nil), ""), "unnamed part")
+	checks.NoError(t, builder.Close(), "close")
+
+	if !strings.Contains(body.String(), `filename="photo.JPG"`+"\r\nContent-Type: image/jpeg\r\n") {
+		t.Fatalf("expected an image/jpeg part, got %q", body.String())
+	}
+	if strings.Count(body.String(), "Content-Type") != 1 {
+		t.Fatalf("expected no content type for an unnamed part, got %q", body.String())
+	}
+
+	for filename, contentType := range map[string]string{
+		"a.jsonl": "application/jsonl",
+		"b.wav":   "audio/wav",
+		"c":       "",
+		"d.webp":  "image/webp",
+	} {
+		if got := ContentTypeByFilename(filename); got != contentType {
+			t.Errorf("%s: expected %q, got %q", filename, contentType, got)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	ErrFormSourceNotReplayable = errors.New("multipart form source cannot be read more than once")
+	ErrFormSourceNil           = errors.New("multipart form source cannot be nil")
+)
+
+// ReaderSize returns the number of bytes that remain to be read from r,
+// or -1 if it cannot be determined without consuming the reader.
+func ReaderSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	case io.Seeker:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err = v.Seek(offset, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - offset
+	}
+	return -1
+}
+
+// FormBodyFunc writes the fields and files of a multipart form, including the final Close.
+// It is called once per body that is produced, so sources that can be re-opened
+// (such as files on disk) should be opened inside the function.
+type FormBodyFunc func(FormBuilder) error
+
+// MultipartBody streams a multipart form through a pipe instead of buffering it in memory.
+//
+// NewMultipartBody performs a dry run of the form which does not consume any file
+// source. The dry run reports builder errors and nil sources synchronously, computes the content
+// length when every source has a known size, and records the position of every
+// source so the body can be produced again for retries.
+type MultipartBody struct {
+	newBuilder  func(io.Writer) FormBuilder
+	build       FormBodyFunc
+	progress    func(written, total int64)
+	contentType string
+	boundary    string
+	length      int64
+	offsets     []int64
+	replayable  bool
+}
+
+// NewMultipartBody prepares a streamed multipart body. progress may be nil.
+func NewMultipartBody(
+	newBuilder func(io.Writer) FormBuilder,
+	build FormBodyFunc,
+	progress func(written, total int64),
+) (*MultipartBody, error) {
+	counter := &countingWriter{}
+	builder := newBuilder(counter)
+	sizer := &sizingFormBuilder{inner: builder, replayable: true}
+	sizer.def, _ = builder.(*DefaultFormBuilder)
+	if err := build(sizer); err != nil {
+		return nil, err
+	}
+
+	body := &MultipartBody{
+		newBuilder:  newBuilder,
+		build:       build,
+		progress:    progress,
+		contentType: builder.FormDataContentType(),
+		length:      -1,
+		offsets:     sizer.offsets,
+		replayable:  sizer.replayable,
+	}
+	if sizer.def != nil {
+		body.boundary = sizer.def.writer.Boundary()
+		if !sizer.unknownSize {
+			body.length = counter.n + sizer.fileBytes
+		}
+	}
+	return body, nil
+}
+
+// FormDataContentType returns the Content-Type of the body, including the boundary.
+func (m *MultipartBody) FormDataContentType() string {
+	return m.contentType
+}
+
+// ContentLength returns the exact length of the body, or -1 if it is unknown.
+func (m *MultipartBody) ContentLength() int64 {
+	return m.length
+}
+
+// Replayable reports whether GetBody can produce the body again.
+func (m *MultipartBody) Replayable() bool {
+	return m.replayable
+}
+
+// Reader returns a new reader of the body. The form is written on demand by a
+// goroutine which starts on the first Read and stops when the reader is closed.
+func (m *MultipartBody) Reader() io.ReadCloser {
+	pr, pw := io.Pipe()
+	return &lazyPipeReader{
+		pr: pr,
+		start: func() {
+			go func() {
+				pw.CloseWithError(m.write(pw))
+			}()
+		},
+	}
+}
+
+// GetBody returns a new reader of the body, suitable for http.Request.GetBody.
+func (m *MultipartBody) GetBody() (io.ReadCloser, error) {
+	if !m.replayable {
+		return nil, ErrFormSourceNotReplayable
+	}
+	return m.Reader(), nil
+}
+
+func (m *MultipartBody) write(w io.Writer) error {
+	if m.progress != nil {
+		w = &progressWriter{w: w, total: m.length, progress: m.progress}
+	}
+	builder := m.newBuilder(w)
+	if def, ok := builder.(*DefaultFormBuilder); ok && m.boundary != "" {
+		if err := def.writer.SetBoundary(m.boundary); err != nil {
+			return err
+		}
+	}
+	return m.build(&rewindingFormBuilder{FormBuilder: builder, offsets: m.offsets})
+}
+
+type lazyPipeReader struct {
+	pr    *io.PipeReader
+	once  sync.Once
+	start func()
+}
+
+func (r *lazyPipeReader) Read(p []byte) (int, error) {
+	r.once.Do(r.start)
+	return r.pr.Read(p)
+}
+
+func (r *lazyPipeReader) Close() error {
+	return r.pr.Close()
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	w.progress(w.written, w.total)
+	return n, err
+}
+
+// sizingFormBuilder is used for the dry run: it forwards every call to the real
+// builder but replaces file contents with an empty reader and only records their size.
+type sizingFormBuilder struct {
+	inner       FormBuilder
+	def         *DefaultFormBuilder
+	fileBytes   int64
+	unknownSize bool
+	offsets     []int64
+	replayable  bool
+}
+
+func (b *sizingFormBuilder) record(r io.Reader) {
+	if b.def == nil {
+		// Sizes are only meaningful for the default builder and other
+		// builders may be handed placeholder sources.
+		b.unknownSize, b.replayable = true, false
+		b.offsets = append(b.offsets, -1)
+		return
+	}
+
+	size := ReaderSize(r)
+	if size < 0 {
+		b.unknownSize = true
+	} else {
+		b.fileBytes += size
+	}
+
+	offset := int64(-1)
+	if seeker, ok := r.(io.Seeker); ok {
+		if current, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			offset = current
+		}
+	}
+	if offset < 0 {
+		b.replayable = false
+	}
+	b.offsets = append(b.offsets, offset)
+}
+
+func (b *sizingFormBuilder) CreateFormFile(fieldname string, file *os.File) error {
+	b.record(file)
+	if b.def != nil {
+		if file == nil {
+			return fmt.Errorf("%w: %s", ErrFormSourceNil, fieldname)
+		}
+		return b.def.createFormFile(fieldname, eofReader{}, file.Name())
+	}
+	return b.inner.CreateFormFile(fieldname, file)
+}
+
+func (b *sizingFormBuilder) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
+	b.record(r)
+	if b.def != nil {
+		if r == nil {
+			return fmt.Errorf("%w: %s", ErrFormSourceNil, fieldname)
+		}
+		return b.def.CreateFormFileReader(fieldname, eofReader{}, filename)
+	}
+	return b.inner.CreateFormFileReader(fieldname, r, filename)
+}
+
+func (b *sizingFormBuilder) WriteField(fieldname, value string) error {
+	return b.inner.WriteField(fieldname, value)
+}
+
+func (b *sizingFormBuilder) Close() error {
+	return b.inner.Close()
+}
+
+func (b *sizingFormBuilder) FormDataContentType() string {
+	return b.inner.FormDataContentType()
+}
+
+// rewindingFormBuilder moves every seekable source back to the position it had
+// during the dry run before its contents are copied.
+type rewindingFormBuilder struct {
+	FormBuilder
+	offsets []int64
+	index   int
+}
+
+func (b *rewindingFormBuilder) rewind(r io.Reader) error {
+	if b.index >= len(b.offsets) {
+		return ErrFormSourceNotReplayable
+	}
+	offset := b.offsets[b.index]
+	b.index++
+	if offset < 0 {
+		return nil
+	}
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return ErrFormSourceNotReplayable
+	}
+	_, err := seeker.Seek(offset, io.SeekStart)
+	return err
+}
+
+func (b *rewindingFormBuilder) CreateFormFile(fieldname string, file *os.File) error {
+	if err := b.rewind(file); err != nil {
+		return err
+	}
+	return b.FormBuilder.CreateFormFile(fieldname, file)
+}
+
+func (b *rewindingFormBuilder) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
+	if err := b.rewind(r); err != nil {
+		return err
+	}
+	return b.FormBuilder.CreateFormFileReader(fieldname, r, filename)
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
@@ -0,0 +1,154 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func newDefaultFormBuilder(w io.Writer) FormBuilder {
+	return NewFormBuilder(w)
+}
+
+func TestMultipartBodyFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	err := os.WriteFile(path, bytes.Repeat([]byte("hello world\n"), 1000), 0600)
+	checks.NoErrorF(t, err, "failed to write file")
+
+	build := func(b FormBuilder) error {
+		if err := b.WriteField("purpose", "batch"); err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err = b.CreateFormFile("file", f); err != nil {
+			return err
+		}
+		return b.Close()
+	}
+
+	var lastWritten, lastTotal int64
+	body, err := NewMultipartBody(newDefaultFormBuilder, build, func(written, total int64) {
+		lastWritten, lastTotal = written, total
+	})
+	checks.NoErrorF(t, err, "NewMultipartBody error")
+	if !body.Replayable() {
+		t.Fatal("a body built from a file should be replayable")
+	}
+
+	for i := 0; i < 2; i++ {
+		r, getErr := body.GetBody()
+		checks.NoErrorF(t, getErr, "GetBody error")
+		data, readErr := io.ReadAll(r)
+		checks.NoErrorF(t, readErr, "reading body")
+		r.Close()
+
+		if int64(len(data)) != body.ContentLength() {
+			t.Fatalf("body length %d does not match content length %d", len(data), body.ContentLength())
+		}
+		if !strings.Contains(body.FormDataContentType(), "boundary=") ||
+			!bytes.Contains(data, []byte(strings.Split(body.FormDataContentType(), "boundary=")[1])) {
+			t.Fatal("body does not use the advertised boundary")
+		}
+		if lastWritten != lastTotal || lastTotal != body.ContentLength() {
+			t.Fatalf("unexpected progress %d/%d", lastWritten, lastTotal)
+		}
+	}
+}
+
+func TestMultipartBodyFromReader(t *testing.T) {
+	t.Run("seekable reader is rewound", func(t *testing.T) {
+		reader := strings.NewReader("prefix-content")
+		_, _ = reader.Seek(int64(len("prefix-")), io.SeekStart)
+		body, err := NewMultipartBody(newDefaultFormBuilder, func(b FormBuilder) error {
+			if err := b.CreateFormFileReader("file", reader, "a.txt"); err != nil {
+				return err
+			}
+			return b.Close()
+		}, nil)
+		checks.NoErrorF(t, err, "NewMultipartBody error")
+
+		for i := 0; i < 2; i++ {
+			r, getErr := body.GetBody()
+			checks.NoErrorF(t, getErr, "GetBody error")
+			data, _ := io.ReadAll(r)
+			if int64(len(data)) != body.ContentLength() {
+				t.Fatalf("body length %d does not match content length %d", len(data), body.ContentLength())
+			}
+			if !bytes.Contains(data, []byte("\r\n\r\ncontent\r\n")) || bytes.Contains(data, []byte("prefix")) {
+				t.Fatalf("unexpected body %q", data)
+			}
+		}
+	})
+
+	t.Run("unknown size reader is not replayable", func(t *testing.T) {
+		reader := io.MultiReader(strings.NewReader("content"))
+		body, err := NewMultipartBody(newDefaultFormBuilder, func(b FormBuilder) error {
+			if err := b.CreateFormFileReader("file", reader, "a.txt"); err != nil {
+				return err
+			}
+			return b.Close()
+		}, nil)
+		checks.NoErrorF(t, err, "NewMultipartBody error")
+		if body.ContentLength() != -1 {
+			t.Fatalf("expected unknown content length, got %d", body.ContentLength())
+		}
+		if body.Replayable() {
+			t.Fatal("a non-seekable reader should not be replayable")
+		}
+		data, err := io.ReadAll(body.Reader())
+		checks.NoError(t, err, "reading body")
+		if !bytes.Contains(data, []byte("content")) {
+			t.Fatalf("unexpected body %q", data)
+		}
+		_, err = body.GetBody()
+		checks.ErrorIs(t, err, ErrFormSourceNotReplayable, "GetBody should fail for a consumed reader")
+	})
+}
+
+func TestMultipartBodyErrors(t *testing.T) {
+	errBuild := errors.New("build failed")
+	_, err := NewMultipartBody(newDefaultFormBuilder, func(FormBuilder) error {
+		return errBuild
+	}, nil)
+	checks.ErrorIs(t, err, errBuild, "NewMultipartBody should return dry run errors")
+
+	calls := 0
+	body, err := NewMultipartBody(newDefaultFormBuilder, func(b FormBuilder) error {
+		calls++
+		if calls > 1 {
+			return errBuild
+		}
+		return b.Close()
+	}, nil)
+	checks.NoErrorF(t, err, "NewMultipartBody error")
+	_, err = io.ReadAll(body.Reader())
+	checks.ErrorIs(t, err, errBuild, "reading the body should return streaming errors")
+
+	_, err = NewMultipartBody(newDefaultFormBuilder, func(b FormBuilder) error {
+		return b.CreateFormFileReader("image", nil, "image.png")
+	}, nil)
+	checks.ErrorIs(t, err, ErrFormSourceNil, "NewMultipartBody should reject nil readers")
+	_, err = NewMultipartBody(newDefaultFormBuilder, func(b FormBuilder) error {
+		return b.CreateFormFile("file", nil)
+	}, nil)
+	checks.ErrorIs(t, err, ErrFormSourceNil, "NewMultipartBody should reject nil files")
+}
+
+func TestReaderSize(t *testing.T) {
+	if n := ReaderSize(bytes.NewReader([]byte("abc"))); n != 3 {
+		t.Errorf("expected 3, got %d", n)
+	}
+	if n := ReaderSize(io.MultiReader()); n != -1 {
+		t.Errorf("expected -1, got %d", n)
+	}
+}
@@ -89,10 +89,15 @@ type MessageDeletionStatus struct {
 }
 
 // CreateMessage creates a new message.
-func (c *Client) CreateMessage(ctx context.Context, threadID string, request MessageRequest) (msg Message, err error) {
+func (c *Client) CreateMessage(
+	ctx context.Context,
+	threadID string,
+	request MessageRequest,
+	opts ...RequestOption,
+) (msg Message, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/%s", threadID, messagesSuffix)
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -108,6 +113,7 @@ func (c *Client) ListMessage(ctx context.Context, threadID string,
 	after *string,
 	before *string,
 	runID *string,
+	opts ...RequestOption,
 ) (messages MessagesList, err error) {
 	urlValues := url.Values{}
 	if limit != nil {
@@ -133,7 +139,7 @@ func (c *Client) ListMessage(ctx context.Context, threadID string,
 
 	urlSuffix := fmt.Sprintf("/threads/%s/%s%s", threadID, messagesSuffix, encodedValues)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -146,10 +152,11 @@ func (c *Client) ListMessage(ctx context.Context, threadID string,
 func (c *Client) RetrieveMessage(
 	ctx context.Context,
 	threadID, messageID string,
+	opts ...RequestOption,
 ) (msg Message, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", threadID, messagesSuffix, messageID)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -163,10 +170,12 @@ func (c *Client) ModifyMessage(
 	ctx context.Context,
 	threadID, messageID string,
 	metadata map[string]string,
+	opts ...RequestOption,
 ) (msg Message, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", threadID, messagesSuffix, messageID)
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
-		withBody(map[string]any{"metadata": metadata}), withBetaAssistantVersion(c.config.AssistantVersion))
+		withBody(map[string]any{"metadata": metadata}), withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -179,10 +188,11 @@ func (c *Client) ModifyMessage(
 func (c *Client) RetrieveMessageFile(
 	ctx context.Context,
 	threadID, messageID, fileID string,
+	opts ...RequestOption,
 ) (file MessageFile, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s/files/%s", threadID, messagesSuffix, messageID, fileID)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -195,10 +205,11 @@ func (c *Client) RetrieveMessageFile(
 func (c *Client) ListMessageFiles(
 	ctx context.Context,
 	threadID, messageID string,
+	opts ...RequestOption,
 ) (files MessageFilesList, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s/files", threadID, messagesSuffix, messageID)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -211,10 +222,11 @@ func (c *Client) ListMessageFiles(
 func (c *Client) DeleteMessage(
 	ctx context.Context,
 	threadID, messageID string,
+	opts ...RequestOption,
 ) (status MessageDeletionStatus, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", threadID, messagesSuffix, messageID)
 	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
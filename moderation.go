@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 )
 
@@ -23,7 +24,10 @@ const (
 )
 
 var (
-	ErrModerationInvalidModel = errors.New("this model is not supported with moderation, please use text-moderation-stable or text-moderation-latest instead") //nolint:lll
+	ErrModerationInvalidModel       = errors.New("this model is not supported with moderation, please use text-moderation-stable or text-moderation-latest instead") //nolint:lll
+	ErrModerationInputTypeInvalid   = errors.New("moderation input must be a string, []string or []ModerationInputPart")                                             //nolint:lll
+	ErrModerationImageNotSupported  = errors.New("image inputs are only supported by the omni moderation models")
+	ErrModerationResultCountInvalid = errors.New("number of moderation results does not match the input")
 )
 
 var validModerationModel = map[string]struct{}{
@@ -33,17 +37,77 @@ var validModerationModel = map[string]struct{}{
 	ModerationTextLatest:   {},
 }
 
+// Moderation categories, as named in the results of the moderation API.
+const (
+	ModerationCategoryHate                  = "hate"
+	ModerationCategoryHateThreatening       = "hate/threatening"
+	ModerationCategoryHarassment            = "harassment"
+	ModerationCategoryHarassmentThreatening = "harassment/threatening"
+	ModerationCategorySelfHarm              = "self-harm"
+	ModerationCategorySelfHarmIntent        = "self-harm/intent"
+	ModerationCategorySelfHarmInstructions  = "self-harm/instructions"
+	ModerationCategorySexual                = "sexual"
+	ModerationCategorySexualMinors          = "sexual/minors"
+	ModerationCategoryViolence              = "violence"
+	ModerationCategoryViolenceGraphic       = "violence/graphic"
+	ModerationCategoryIllicit               = "illicit"
+	ModerationCategoryIllicitViolent        = "illicit/violent"
+)
+
+// ModerationInputType is the type of a part of a multimodal moderation input.
+type ModerationInputType string
+
+const (
+	ModerationInputTypeText     ModerationInputType = "text"
+	ModerationInputTypeImageURL ModerationInputType = "image_url"
+)
+
+// ModerationInputPart is a text or an image of a multimodal moderation input.
+type ModerationInputPart struct {
+	Type     ModerationInputType `json:"type"`
+	Text     string              `json:"text,omitempty"`
+	ImageURL *ModerationImageURL `json:"image_url,omitempty"`
+}
+
+// ModerationImageURL is an image to moderate, either a URL or a base64 data URL.
+type ModerationImageURL struct {
+	URL string `json:"url"`
+}
+
 // ModerationRequest represents a request structure for moderation API.
 type ModerationRequest struct {
-	Input string `json:"input,omitempty"`
+	// Input is a string, a []string of texts moderated separately,
+	// or a []ModerationInputPart of texts and images moderated together.
+	Input any    `json:"input,omitempty"`
 	Model string `json:"model,omitempty"`
 }
 
+// validateInput checks the type of Input and that images are only sent to the omni models.
+func (r ModerationRequest) validateInput() error {
+	switch input := r.Input.(type) {
+	case nil, string, []string:
+		return nil
+	case []ModerationInputPart:
+		for _, part := range input {
+			if part.Type == ModerationInputTypeImageURL &&
+				(r.Model == ModerationTextStable || r.Model == ModerationTextLatest) {
+				return ErrModerationImageNotSupported
+			}
+		}
+		return nil
+	}
+	return ErrModerationInputTypeInvalid
+}
+
 // Result represents one of possible moderation results.
 type Result struct {
 	Categories     ResultCategories     `json:"categories"`
 	CategoryScores ResultCategoryScores `json:"category_scores"`
 	Flagged        bool                 `json:"flagged"`
+
+	// CategoryAppliedInputTypes lists the input types, "text" or "image", which were
+	// considered for every category. It is only returned by the omni moderation models.
+	CategoryAppliedInputTypes *ResultCategoryAppliedInputTypes `json:"category_applied_input_types,omitempty"`
 }
 
 // ResultCategories represents Categories of Result.
@@ -59,6 +123,8 @@ type ResultCategories struct {
 	SexualMinors          bool `json:"sexual/minors"`
 	Violence              bool `json:"violence"`
 	ViolenceGraphic       bool `json:"violence/graphic"`
+	Illicit               bool `json:"illicit"`
+	IllicitViolent        bool `json:"illicit/violent"`
 }
 
 // ResultCategoryScores represents CategoryScores of Result.
@@ -74,6 +140,63 @@ type ResultCategoryScores struct {
 	SexualMinors          float32 `json:"sexual/minors"`
 	Violence              float32 `json:"violence"`
 	ViolenceGraphic       float32 `json:"violence/graphic"`
+	Illicit               float32 `json:"illicit"`
+	IllicitViolent        float32 `json:"illicit/violent"`
+}
+
+// ByCategory returns the flags of Categories keyed by category name.
+func (c ResultCategories) ByCategory() map[string]bool {
+	return map[string]bool{
+		ModerationCategoryHate:                  c.Hate,
+		ModerationCategoryHateThreatening:       c.HateThreatening,
+		ModerationCategoryHarassment:            c.Harassment,
+		ModerationCategoryHarassmentThreatening: c.HarassmentThreatening,
+		ModerationCategorySelfHarm:              c.SelfHarm,
+		ModerationCategorySelfHarmIntent:        c.SelfHarmIntent,
+		ModerationCategorySelfHarmInstructions:  c.SelfHarmInstructions,
+		ModerationCategorySexual:                c.Sexual,
+		ModerationCategorySexualMinors:          c.SexualMinors,
+		ModerationCategoryViolence:              c.Violence,
+		ModerationCategoryViolenceGraphic:       c.ViolenceGraphic,
+		ModerationCategoryIllicit:               c.Illicit,
+		ModerationCategoryIllicitViolent:        c.IllicitViolent,
+	}
+}
+
+// ByCategory returns the scores of CategoryScores keyed by category name.
+func (s ResultCategoryScores) ByCategory() map[string]float32 {
+	return map[string]float32{
+		ModerationCategoryHate:                  s.Hate,
+		ModerationCategoryHateThreatening:       s.HateThreatening,
+		ModerationCategoryHarassment:            s.Harassment,
+		ModerationCategoryHarassmentThreatening: s.HarassmentThreatening,
+		ModerationCategorySelfHarm:              s.SelfHarm,
+		ModerationCategorySelfHarmIntent:        s.SelfHarmIntent,
+		ModerationCategorySelfHarmInstructions:  s.SelfHarmInstructions,
+		ModerationCategorySexual:                s.Sexual,
+		ModerationCategorySexualMinors:          s.SexualMinors,
+		ModerationCategoryViolence:              s.Violence,
+		ModerationCategoryViolenceGraphic:       s.ViolenceGraphic,
+		ModerationCategoryIllicit:               s.Illicit,
+		ModerationCategoryIllicitViolent:        s.IllicitViolent,
+	}
+}
+
+// ResultCategoryAppliedInputTypes represents CategoryAppliedInputTypes of Result.
+type ResultCategoryAppliedInputTypes struct {
+	Hate                  []string `json:"hate"`
+	HateThreatening       []string `json:"hate/threatening"`
+	Harassment            []string `json:"harassment"`
+	HarassmentThreatening []string `json:"harassment/threatening"`
+	SelfHarm              []string `json:"self-harm"`
+	SelfHarmIntent        []string `json:"self-harm/intent"`
+	SelfHarmInstructions  []string `json:"self-harm/instructions"`
+	Sexual                []string `json:"sexual"`
+	SexualMinors          []string `json:"sexual/minors"`
+	Violence              []string `json:"violence"`
+	ViolenceGraphic       []string `json:"violence/graphic"`
+	Illicit               []string `json:"illicit"`
+	IllicitViolent        []string `json:"illicit/violent"`
 }
 
 // ModerationResponse represents a response structure for moderation API.
@@ -85,18 +208,59 @@ type ModerationResponse struct {
 	httpHeader
 }
 
-// Moderations — perform a moderation api call over a string.
-// Input can be an array or slice but a string will reduce the complexity.
-func (c *Client) Moderations(ctx context.Context, request ModerationRequest) (response ModerationResponse, err error) {
+// ModerationInputResult is the moderation result of one input of a request.
+type ModerationInputResult struct {
+	// Index is the position of the input in a []string input, and 0 otherwise.
+	Index int
+	// Input is the moderated string, or the []ModerationInputPart moderated together.
+	Input  any
+	Result Result
+}
+
+// InputResults maps every result of the response back to the input of request it moderates:
+// one result per string of a []string input, and a single result for a string or a multimodal input.
+func (r ModerationResponse) InputResults(request ModerationRequest) ([]ModerationInputResult, error) {
+	var inputs []any
+	switch input := request.Input.(type) {
+	case []string:
+		for _, text := range input {
+			inputs = append(inputs, text)
+		}
+	case string, []ModerationInputPart:
+		inputs = []any{input}
+	default:
+		return nil, ErrModerationInputTypeInvalid
+	}
+	if len(inputs) != len(r.Results) {
+		return nil, fmt.Errorf("%w: %d inputs and %d results", ErrModerationResultCountInvalid, len(inputs), len(r.Results))
+	}
+
+	results := make([]ModerationInputResult, len(inputs))
+	for i, input := range inputs {
+		results[i] = ModerationInputResult{Index: i, Input: input, Result: r.Results[i]}
+	}
+	return results, nil
+}
+
+// Moderations — perform a moderation api call over a string, a slice of strings, or texts and images.
+func (c *Client) Moderations(
+	ctx context.Context,
+	request ModerationRequest,
+	opts ...RequestOption,
+) (response ModerationResponse, err error) {
 	if _, ok := validModerationModel[request.Model]; len(request.Model) > 0 && !ok {
 		err = ErrModerationInvalidModel
 		return
 	}
+	if err = request.validateInput(); err != nil {
+		return
+	}
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL("/moderations", withModel(request.Model)),
 		withBody(&request),
+		withOptions(opts),
 	)
 	if err != nil {
 		return
@@ -0,0 +1,415 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultModerationStreamWindow  = 1000
+	defaultModerationStreamOverlap = 100
+)
+
+var ErrModerationBlocked = errors.New("content blocked by moderation")
+
+// ModerationStage is the part of a chat completion that is moderated.
+type ModerationStage string
+
+const (
+	ModerationStageInput  ModerationStage = "input"
+	ModerationStageOutput ModerationStage = "output"
+)
+
+// ModerationCheck is the outcome of the moderation of the input or output of a chat completion.
+type ModerationCheck struct {
+	Stage ModerationStage
+	// Input is what was sent to the moderation API: a []string of output texts,
+	// or a []ModerationInputPart of the new user messages.
+	Input  any
+	Result Result
+	// Flagged are the categories over their threshold, sorted by name.
+	Flagged []string
+}
+
+// ModerationBlockedError is returned when the input or output of a chat completion is blocked.
+type ModerationBlockedError struct {
+	Stage      ModerationStage
+	Categories []string
+	Scores     map[string]float32
+}
+
+func (e *ModerationBlockedError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Stage, ErrModerationBlocked, strings.Join(e.Categories, ", "))
+}
+
+func (e *ModerationBlockedError) Unwrap() error {
+	return ErrModerationBlocked
+}
+
+// ModerationGuardConfig configures a ModerationGuard.
+type ModerationGuardConfig struct {
+	// Model is the moderation model, omni-moderation-latest by default.
+	Model string
+	// Thresholds are scores from which a category is flagged, keyed by category name such as
+	// ModerationCategoryViolence. Categories without a threshold use the flags of the API.
+	Thresholds map[string]float32
+	// SkipInput and SkipOutput disable the moderation of the input or of the output.
+	SkipInput  bool
+	SkipOutput bool
+	// StreamWindow is the number of characters of streamed output moderated at once, 1000 by default.
+	// Streamed chunks are held back until the window they belong to is moderated.
+	StreamWindow int
+	// StreamOverlap is the number of characters of the previous window moderated again with the
+	// next one, so that content split between two windows is seen whole. 100 by default.
+	StreamOverlap int
+	// Policy optionally decides whether a check blocks the completion. It returns nil to let
+	// the completion through, or the error returned to the caller. By default, a check blocks
+	// with a *ModerationBlockedError when any category is flagged.
+	Policy func(ctx context.Context, check ModerationCheck) error
+}
+
+// ModerationGuard wraps chat completions with the moderation of the new user messages
+// before they are sent and of the assistant output once it is generated.
+type ModerationGuard struct {
+	client *Client
+	config ModerationGuardConfig
+}
+
+// NewModerationGuard returns a guard of the chat completions of client.
+func NewModerationGuard(client *Client, config ModerationGuardConfig) *ModerationGuard {
+	if config.Model == "" {
+		config.Model = ModerationOmniLatest
+	}
+	if config.StreamWindow <= 0 {
+		config.StreamWindow = defaultModerationStreamWindow
+	}
+	if config.StreamOverlap < 0 || config.StreamOverlap >= config.StreamWindow {
+		config.StreamOverlap = 0
+	} else if config.StreamOverlap == 0 {
+		config.StreamOverlap = defaultModerationStreamOverlap
+	}
+	return &ModerationGuard{client: client, config: config}
+}
+
+// CreateChatCompletion moderates the new user messages, creates the chat completion,
+// and moderates the content of its choices. The headers and timeout of the options apply
+// to the moderations as well.
+func (g *ModerationGuard) CreateChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (response ChatCompletionResponse, err error) {
+	moderationOpts := moderationOptions(opts)
+	if err = g.checkInput(ctx, request, moderationOpts); err != nil {
+		return
+	}
+	response, err = g.client.CreateChatCompletion(ctx, request, opts...)
+	if err != nil || g.config.SkipOutput {
+		return
+	}
+
+	outputs := make([]string, 0, len(response.Choices))
+	for _, choice := range response.Choices {
+		if choice.Message.Content != "" {
+			outputs = append(outputs, choice.Message.Content)
+		}
+	}
+	if err = g.check(ctx, ModerationStageOutput, outputs, moderationOpts); err != nil {
+		response = ChatCompletionResponse{}
+	}
+	return
+}
+
+// CreateChatCompletionStream moderates the new user messages and creates a chat completion
+// stream whose output is moderated in windows of StreamWindow characters of every choice.
+// The headers and timeout of the options apply to the moderations as well.
+func (g *ModerationGuard) CreateChatCompletionStream(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (stream *GuardedChatCompletionStream, err error) {
+	moderationOpts := moderationOptions(opts)
+	if err = g.checkInput(ctx, request, moderationOpts); err != nil {
+		return
+	}
+	inner, err := g.client.CreateChatCompletionStream(ctx, request, opts...)
+	if err != nil {
+		return
+	}
+	stream = &GuardedChatCompletionStream{
+		ctx:     ctx,
+		opts:    moderationOpts,
+		guard:   g,
+		stream:  inner,
+		windows: map[int]*strings.Builder{},
+		checked: map[int]string{},
+	}
+	return
+}
+
+// moderationOptions keeps the headers and the timeout of the options of a chat completion.
+// Its query parameters, extra body and base URL are meant for the chat completions endpoint only.
+func moderationOptions(opts []RequestOption) []RequestOption {
+	if len(opts) == 0 {
+		return nil
+	}
+	args := newRequestOptions(opts)
+	return []RequestOption{func(o *RequestOptions) {
+		for key, values := range args.Header {
+			o.Header[key] = append([]string(nil), values...)
+		}
+		o.Timeout = args.Timeout
+	}}
+}
+
+// checkInput moderates the user messages after the last assistant message.
+func (g *ModerationGuard) checkInput(ctx context.Context, request ChatCompletionRequest, opts []RequestOption) error {
+	if g.config.SkipInput {
+		return nil
+	}
+
+	var parts []ModerationInputPart
+	for i := len(request.Messages) - 1; i >= 0; i-- {
+		message := request.Messages[i]
+		if message.Role == ChatMessageRoleAssistant {
+			break
+		}
+		if message.Role != ChatMessageRoleUser {
+			continue
+		}
+		parts = append(moderationParts(message), parts...)
+	}
+	for _, input := range splitModerationImages(parts) {
+		if err := g.check(ctx, ModerationStageInput, input, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitModerationImages splits parts into inputs with at most one image each, which is all
+// omni-moderation accepts. The texts are moderated together with the first image.
+func splitModerationImages(parts []ModerationInputPart) [][]ModerationInputPart {
+	var texts, images []ModerationInputPart
+	for _, part := range parts {
+		if part.Type == ModerationInputTypeImageURL {
+			images = append(images, part)
+		} else {
+			texts = append(texts, part)
+		}
+	}
+	if len(images) == 0 {
+		if len(texts) == 0 {
+			return nil
+		}
+		return [][]ModerationInputPart{texts}
+	}
+	inputs := [][]ModerationInputPart{append(texts, images[0])}
+	for _, image := range images[1:] {
+		inputs = append(inputs, []ModerationInputPart{image})
+	}
+	return inputs
+}
+
+func moderationParts(message ChatCompletionMessage) []ModerationInputPart {
+	if message.Content != "" {
+		return []ModerationInputPart{{Type: ModerationInputTypeText, Text: message.Content}}
+	}
+	var parts []ModerationInputPart
+	for _, part := range message.MultiContent {
+		switch {
+		case part.Type == ChatMessagePartTypeText && part.Text != "":
+			parts = append(parts, ModerationInputPart{Type: ModerationInputTypeText, Text: part.Text})
+		case part.Type == ChatMessagePartTypeImageURL && part.ImageURL != nil:
+			parts = append(parts, ModerationInputPart{
+				Type:     ModerationInputTypeImageURL,
+				ImageURL: &ModerationImageURL{URL: part.ImageURL.URL},
+			})
+		}
+	}
+	return parts
+}
+
+// check moderates input, a []string or []ModerationInputPart, and applies the policy to every result.
+func (g *ModerationGuard) check(ctx context.Context, stage ModerationStage, input any, opts []RequestOption) error {
+	if texts, ok := input.([]string); ok && len(texts) == 0 {
+		return nil
+	}
+
+	request := ModerationRequest{Model: g.config.Model, Input: input}
+	response, err := g.client.Moderations(ctx, request, opts...)
+	if err != nil {
+		return err
+	}
+	results, err := response.InputResults(request)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		check := ModerationCheck{
+			Stage:   stage,
+			Input:   input,
+			Result:  result.Result,
+			Flagged: g.flaggedCategories(result.Result),
+		}
+		if g.config.Policy != nil {
+			err = g.config.Policy(ctx, check)
+		} else if len(check.Flagged) > 0 {
+			err = &ModerationBlockedError{
+				Stage:      stage,
+				Categories: check.Flagged,
+				Scores:     result.Result.CategoryScores.ByCategory(),
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *ModerationGuard) flaggedCategories(result Result) []string {
+	var flagged []string
+	scores := result.CategoryScores.ByCategory()
+	for category, isFlagged := range result.Categories.ByCategory() {
+		if threshold, ok := g.config.Thresholds[category]; ok {
+			isFlagged = scores[category] >= threshold
+		}
+		if isFlagged {
+			flagged = append(flagged, category)
+		}
+	}
+	sort.Strings(flagged)
+	return flagged
+}
+
+// GuardedChatCompletionStream is a chat completion stream whose output is moderated.
+//
+// The output of every choice is moderated in its own windows. Responses are held back until
+// the windows of output they belong to are moderated, so Recv only returns content which passed
+// the moderation. If a window is blocked, Recv returns the error of the check and the held
+// responses are discarded.
+type GuardedChatCompletionStream struct {
+	ctx    context.Context
+	opts   []RequestOption
+	guard  *ModerationGuard
+	stream *ChatCompletionStream
+
+	held     []ChatCompletionStreamResponse
+	released []ChatCompletionStreamResponse
+	// windows and checked hold the pending output and the end of the moderated output of every choice,
+	// keyed by choice index.
+	windows   map[int]*strings.Builder
+	checked   map[int]string
+	err       error
+	streamEOF bool
+}
+
+// Recv returns the next response which passed the moderation, or io.EOF at the end of the stream.
+func (s *GuardedChatCompletionStream) Recv() (response ChatCompletionStreamResponse, err error) {
+	for len(s.released) == 0 {
+		if s.err != nil {
+			err = s.err
+			return
+		}
+		if s.streamEOF {
+			err = io.EOF
+			return
+		}
+		s.receive()
+	}
+	response = s.released[0]
+	s.released = s.released[1:]
+	return
+}
+
+// receive reads the next response of the underlying stream and moderates the window once it is full.
+func (s *GuardedChatCompletionStream) receive() {
+	response, err := s.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		s.streamEOF = true
+		s.flush()
+		return
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	s.held = append(s.held, response)
+	if s.guard.config.SkipOutput {
+		s.release()
+		return
+	}
+	full := false
+	for _, choice := range response.Choices {
+		window := s.windows[choice.Index]
+		if window == nil {
+			window = &strings.Builder{}
+			s.windows[choice.Index] = window
+		}
+		window.WriteString(choice.Delta.Content)
+		full = full || utf8.RuneCountInString(window.String()) >= s.guard.config.StreamWindow
+	}
+	if full {
+		s.flush()
+	}
+}
+
+// flush moderates the pending window of every choice, preceded by the end of its previous one,
+// and releases the held responses.
+func (s *GuardedChatCompletionStream) flush() {
+	indexes := make([]int, 0, len(s.windows))
+	for index, window := range s.windows {
+		if window.Len() > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	if len(indexes) > 0 && !s.guard.config.SkipOutput {
+		texts := make([]string, len(indexes))
+		for i, index := range indexes {
+			texts[i] = s.checked[index] + s.windows[index].String()
+		}
+		if err := s.guard.check(s.ctx, ModerationStageOutput, texts, s.opts); err != nil {
+			s.err = err
+			s.held = nil
+			return
+		}
+		for _, index := range indexes {
+			s.checked[index] = lastRunes(s.windows[index].String(), s.guard.config.StreamOverlap)
+		}
+	}
+	s.release()
+}
+
+func (s *GuardedChatCompletionStream) release() {
+	s.released = append(s.released, s.held...)
+	s.held = nil
+	for _, window := range s.windows {
+		window.Reset()
+	}
+}
+
+// Close closes the underlying stream.
+func (s *GuardedChatCompletionStream) Close() error {
+	return s.stream.Close()
+}
+
+func lastRunes(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[len(runes)-n:])
+}
@@ -0,0 +1,328 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+// registerGuardModerations answers moderations with one result per text of a batch,
+// or a single result for a string or multimodal input, and records the moderated texts.
+func registerGuardModerations(t *testing.T, server *test.ServerTest) *[]string {
+	t.Helper()
+	var moderated []string
+	server.RegisterHandler("/v1/moderations", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ModerationRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.Model != openai.ModerationOmniLatest {
+			t.Errorf("unexpected moderation model %q", request.Model)
+		}
+
+		var texts []string
+		switch input := request.Input.(type) {
+		case string:
+			texts = []string{input}
+		case []any:
+			var combined []string
+			multimodal, images := false, 0
+			for _, item := range input {
+				switch item := item.(type) {
+				case string:
+					texts = append(texts, item)
+				case map[string]any:
+					multimodal = true
+					switch item["type"] {
+					case string(openai.ModerationInputTypeText):
+						combined = append(combined, fmt.Sprint(item["text"]))
+					case string(openai.ModerationInputTypeImageURL):
+						images++
+					}
+				}
+			}
+			if images > 1 {
+				t.Errorf("a moderation input accepts at most one image, got %d", images)
+			}
+			if multimodal {
+				texts = []string{strings.Join(combined, " ")}
+			}
+		}
+
+		response := openai.ModerationResponse{Model: request.Model}
+		for _, text := range texts {
+			moderated = append(moderated, text)
+			response.Results = append(response.Results, moderationResult(text))
+		}
+		checks.NoError(t, json.NewEncoder(w).Encode(response), "Encode error")
+	})
+	return &moderated
+}
+
+func registerGuardChat(t *testing.T, server *test.ServerTest, content string) *int {
+	t.Helper()
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		response := openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: content,
+			}}},
+		}
+		checks.NoError(t, json.NewEncoder(w).Encode(response), "Encode error")
+	})
+	return &calls
+}
+
+func TestModerationGuardInput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	moderated := registerGuardModerations(t, server)
+	calls := registerGuardChat(t, server, "Hello.")
+	guard := openai.NewModerationGuard(client, openai.ModerationGuardConfig{})
+
+	_, err := guard.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "An old message about hate"},
+			{Role: openai.ChatMessageRoleAssistant, Content: "Let's talk about something else."},
+			{Role: openai.ChatMessageRoleSystem, Content: "Be nice."},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "I hate"},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://a.b/c.png"}},
+			}},
+		},
+	})
+
+	var blocked *openai.ModerationBlockedError
+	if !errors.As(err, &blocked) || !errors.Is(err, openai.ErrModerationBlocked) {
+		t.Fatalf("expected a blocked error, got %v", err)
+	}
+	if blocked.Stage != openai.ModerationStageInput || len(blocked.Categories) != 1 ||
+		blocked.Categories[0] != openai.ModerationCategoryHate || blocked.Scores[openai.ModerationCategoryHate] != 1 {
+		t.Errorf("unexpected blocked error %+v", blocked)
+	}
+	if *calls != 0 || len(*moderated) != 1 || (*moderated)[0] != "I hate" {
+		t.Errorf("only the new user message should be moderated, got %q and %d calls", *moderated, *calls)
+	}
+}
+
+func TestModerationGuardInputImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	moderated := registerGuardModerations(t, server)
+	calls := registerGuardChat(t, server, "Two cats.")
+	guard := openai.NewModerationGuard(client, openai.ModerationGuardConfig{})
+
+	_, err := guard.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "What is in"},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://a.b/c.png"}},
+			}},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "these images?"},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://a.b/d.png"}},
+			}},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if *calls != 1 || strings.Join(*moderated, "|") != "What is in these images?||Two cats." {
+		t.Errorf("every image should be moderated in its own input, got %q", *moderated)
+	}
+}
+
+func TestModerationGuardOutput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	registerGuardModerations(t, server)
+	registerGuardChat(t, server, "I will kill it.")
+	request := openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "What about the bug?"}},
+	}
+	ctx := context.Background()
+
+	response, err := openai.NewModerationGuard(client, openai.ModerationGuardConfig{}).
+		CreateChatCompletion(ctx, request)
+	var blocked *openai.ModerationBlockedError
+	if !errors.As(err, &blocked) || blocked.Stage != openai.ModerationStageOutput || len(response.Choices) != 0 {
+		t.Fatalf("expected the output to be blocked, got %v", err)
+	}
+
+	// A threshold above the score lets the output through.
+	response, err = openai.NewModerationGuard(client, openai.ModerationGuardConfig{
+		Thresholds: map[string]float32{openai.ModerationCategoryViolence: 1.5},
+	}).CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.Choices[0].Message.Content != "I will kill it." {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	// A policy decides on every check.
+	errPolicy := errors.New("policy")
+	var checked []openai.ModerationCheck
+	_, err = openai.NewModerationGuard(client, openai.ModerationGuardConfig{
+		Thresholds: map[string]float32{openai.ModerationCategoryHarassment: 0},
+		Policy: func(_ context.Context, check openai.ModerationCheck) error {
+			checked = append(checked, check)
+			if check.Stage == openai.ModerationStageOutput {
+				return errPolicy
+			}
+			return nil
+		},
+	}).CreateChatCompletion(ctx, request)
+	checks.ErrorIs(t, err, errPolicy, "the policy error should be returned")
+	if len(checked) != 2 || len(checked[0].Flagged) != 1 || checked[0].Flagged[0] != openai.ModerationCategoryHarassment ||
+		len(checked[1].Flagged) != 2 {
+		t.Errorf("unexpected checks %+v", checked)
+	}
+}
+
+func TestModerationGuardStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	moderated := registerGuardModerations(t, server)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"Hello ", "there ", "friend, ", "I will ", "kill ", "you"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	guard := openai.NewModerationGuard(client, openai.ModerationGuardConfig{
+		SkipInput:     true,
+		StreamWindow:  10,
+		StreamOverlap: 3,
+	})
+	stream, err := guard.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		Stream:   true,
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var received string
+	for {
+		var response openai.ChatCompletionStreamResponse
+		response, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		received += response.Choices[0].Delta.Content
+	}
+	checks.ErrorIs(t, err, openai.ErrModerationBlocked, "the last window should be blocked")
+	if received != "Hello there friend, I will " {
+		t.Errorf("only moderated windows should be received, got %q", received)
+	}
+
+	expected := []string{"Hello there ", "re friend, I will ", "ll kill you"}
+	if strings.Join(*moderated, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected windows %q", *moderated)
+	}
+	if _, err = stream.Recv(); !errors.Is(err, openai.ErrModerationBlocked) {
+		t.Errorf("a blocked stream should stay blocked, got %v", err)
+	}
+}
+
+func TestModerationGuardStreamSkipOutput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	moderated := registerGuardModerations(t, server)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"kill\"}}]}\n\ndata: [DONE]\n\n")
+	})
+
+	stream, err := openai.NewModerationGuard(client, openai.ModerationGuardConfig{SkipOutput: true}).
+		CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+			Model:    openai.GPT4o,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+			Stream:   true,
+		})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "the stream should end")
+	if len(*moderated) != 1 || (*moderated)[0] != "Hi" {
+		t.Errorf("only the input should be moderated, got %q", *moderated)
+	}
+}
+
+func TestModerationGuardStreamChoices(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	moderated := registerGuardModerations(t, server)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range [][2]string{{"Hello ", "Good "}, {"there", "day"}} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}},"+
+				"{\"index\":1,\"delta\":{\"content\":%q}}]}\n\n", delta[0], delta[1])
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := openai.NewModerationGuard(client, openai.ModerationGuardConfig{SkipInput: true}).
+		CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+			Model:    openai.GPT4o,
+			N:        2,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+			Stream:   true,
+		})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	checks.ErrorIs(t, err, io.EOF, "the stream should end")
+
+	if strings.Join(*moderated, "|") != "Hello there|Good day" {
+		t.Errorf("every choice should be moderated on its own, got %q", *moderated)
+	}
+}
+
+func TestModerationGuardRequestOptions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var traces []string
+	server.RegisterHandler("/v1/moderations", func(w http.ResponseWriter, r *http.Request) {
+		traces = append(traces, r.Header.Get("X-Trace-Id"))
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "Decode error")
+		if _, ok := body["store"]; ok || r.URL.Query().Has("api-version") {
+			t.Errorf("the chat only options should not apply to the moderations")
+		}
+		response := openai.ModerationResponse{Results: []openai.Result{moderationResult("Hi")}}
+		checks.NoError(t, json.NewEncoder(w).Encode(response), "Encode error")
+	})
+	registerGuardChat(t, server, "Hello")
+
+	_, err := openai.NewModerationGuard(client, openai.ModerationGuardConfig{}).CreateChatCompletion(
+		context.Background(),
+		openai.ChatCompletionRequest{
+			Model:    openai.GPT4o,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		},
+		openai.WithHeader("X-Trace-Id", "trace-1"),
+		openai.WithExtraBody(map[string]any{"store": true}),
+		openai.WithQueryParam("api-version", "2024-10-21"),
+	)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if strings.Join(traces, "|") != "trace-1|trace-1" {
+		t.Errorf("the headers should apply to the input and output moderations, got %q", traces)
+	}
+}
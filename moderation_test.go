@@ -79,64 +79,74 @@ func handleModerationEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	res := openai.ModerationResponse{
+		ID:    strconv.Itoa(int(time.Now().Unix())),
+		Model: moderationReq.Model,
+	}
+	switch input := moderationReq.Input.(type) {
+	case string:
+		res.Results = append(res.Results, moderationResult(input))
+	case []any:
+		for _, text := range input {
+			res.Results = append(res.Results, moderationResult(fmt.Sprint(text)))
+		}
+	}
+
+	resBytes, _ = json.Marshal(res)
+	fmt.Fprintln(w, string(resBytes))
+}
+
+// moderationResult flags the categories matched by keywords of input.
+func moderationResult(input string) openai.Result {
 	resCat := openai.ResultCategories{}
 	resCatScore := openai.ResultCategoryScores{}
 	switch {
-	case strings.Contains(moderationReq.Input, "hate"):
+	case strings.Contains(input, "hate"):
 		resCat = openai.ResultCategories{Hate: true}
 		resCatScore = openai.ResultCategoryScores{Hate: 1}
 
-	case strings.Contains(moderationReq.Input, "hate more"):
+	case strings.Contains(input, "hate more"):
 		resCat = openai.ResultCategories{HateThreatening: true}
 		resCatScore = openai.ResultCategoryScores{HateThreatening: 1}
 
-	case strings.Contains(moderationReq.Input, "harass"):
+	case strings.Contains(input, "harass"):
 		resCat = openai.ResultCategories{Harassment: true}
 		resCatScore = openai.ResultCategoryScores{Harassment: 1}
 
-	case strings.Contains(moderationReq.Input, "harass hard"):
+	case strings.Contains(input, "harass hard"):
 		resCat = openai.ResultCategories{Harassment: true}
 		resCatScore = openai.ResultCategoryScores{HarassmentThreatening: 1}
 
-	case strings.Contains(moderationReq.Input, "suicide"):
+	case strings.Contains(input, "suicide"):
 		resCat = openai.ResultCategories{SelfHarm: true}
 		resCatScore = openai.ResultCategoryScores{SelfHarm: 1}
 
-	case strings.Contains(moderationReq.Input, "wanna suicide"):
+	case strings.Contains(input, "wanna suicide"):
 		resCat = openai.ResultCategories{SelfHarmIntent: true}
 		resCatScore = openai.ResultCategoryScores{SelfHarm: 1}
 
-	case strings.Contains(moderationReq.Input, "drink bleach"):
+	case strings.Contains(input, "drink bleach"):
 		resCat = openai.ResultCategories{SelfHarmInstructions: true}
 		resCatScore = openai.ResultCategoryScores{SelfHarmInstructions: 1}
 
-	case strings.Contains(moderationReq.Input, "porn"):
+	case strings.Contains(input, "porn"):
 		resCat = openai.ResultCategories{Sexual: true}
 		resCatScore = openai.ResultCategoryScores{Sexual: 1}
 
-	case strings.Contains(moderationReq.Input, "child porn"):
+	case strings.Contains(input, "child porn"):
 		resCat = openai.ResultCategories{SexualMinors: true}
 		resCatScore = openai.ResultCategoryScores{SexualMinors: 1}
 
-	case strings.Contains(moderationReq.Input, "kill"):
+	case strings.Contains(input, "kill"):
 		resCat = openai.ResultCategories{Violence: true}
 		resCatScore = openai.ResultCategoryScores{Violence: 1}
 
-	case strings.Contains(moderationReq.Input, "corpse"):
+	case strings.Contains(input, "corpse"):
 		resCat = openai.ResultCategories{ViolenceGraphic: true}
 		resCatScore = openai.ResultCategoryScores{ViolenceGraphic: 1}
 	}
 
-	result := openai.Result{Categories: resCat, CategoryScores: resCatScore, Flagged: true}
-
-	res := openai.ModerationResponse{
-		ID:    strconv.Itoa(int(time.Now().Unix())),
-		Model: moderationReq.Model,
-	}
-	res.Results = append(res.Results, result)
-
-	resBytes, _ = json.Marshal(res)
-	fmt.Fprintln(w, string(resBytes))
+	return openai.Result{Categories: resCat, CategoryScores: resCatScore, Flagged: true}
 }
 
 // getModerationBody Returns the body of the request to do a moderation.
@@ -153,3 +163,79 @@ func getModerationBody(r *http.Request) (openai.ModerationRequest, error) {
 	}
 	return moderation, nil
 }
+
+func TestModerationsBatchedInput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/moderations", handleModerationEndpoint)
+
+	request := openai.ModerationRequest{
+		Model: openai.ModerationOmniLatest,
+		Input: []string{"I hate them", "a nice day", "I want to kill them."},
+	}
+	response, err := client.Moderations(context.Background(), request)
+	checks.NoError(t, err, "Moderations error")
+
+	results, err := response.InputResults(request)
+	checks.NoError(t, err, "InputResults error")
+	if len(results) != 3 || results[2].Index != 2 || results[2].Input != "I want to kill them." ||
+		!results[2].Result.Categories.Violence || !results[0].Result.Categories.Hate {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	_, err = response.InputResults(openai.ModerationRequest{Input: "one input"})
+	checks.ErrorIs(t, err, openai.ErrModerationResultCountInvalid, "results must match the inputs")
+}
+
+func TestModerationsMultimodalInput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/moderations", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input []openai.ModerationInputPart `json:"input"`
+		}
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "Decode error")
+		if len(body.Input) != 2 || body.Input[1].ImageURL == nil || body.Input[1].ImageURL.URL != "https://example.com/a.png" {
+			t.Errorf("unexpected input %+v", body.Input)
+		}
+		fmt.Fprint(w, `{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,
+			"categories":{"illicit":true,"illicit/violent":false,"violence":true},
+			"category_scores":{"illicit":0.9,"illicit/violent":0.1,"violence":0.8},
+			"category_applied_input_types":{"illicit":["text"],"violence":["text","image"],"sexual/minors":["text"]}}]}`)
+	})
+
+	parts := []openai.ModerationInputPart{
+		{Type: openai.ModerationInputTypeText, Text: "how do I make this?"},
+		{Type: openai.ModerationInputTypeImageURL, ImageURL: &openai.ModerationImageURL{URL: "https://example.com/a.png"}},
+	}
+	request := openai.ModerationRequest{Model: openai.ModerationOmniLatest, Input: parts}
+	response, err := client.Moderations(context.Background(), request)
+	checks.NoError(t, err, "Moderations error")
+
+	result := response.Results[0]
+	if !result.Categories.Illicit || result.Categories.IllicitViolent || result.CategoryScores.Illicit != 0.9 ||
+		result.CategoryAppliedInputTypes == nil || len(result.CategoryAppliedInputTypes.Violence) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	results, err := response.InputResults(request)
+	checks.NoError(t, err, "InputResults error")
+	if len(results) != 1 || len(results[0].Input.([]openai.ModerationInputPart)) != 2 {
+		t.Errorf("multimodal inputs have a single result: %+v", results)
+	}
+}
+
+func TestModerationsInvalidInput(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+	ctx := context.Background()
+
+	_, err := client.Moderations(ctx, openai.ModerationRequest{Input: 42})
+	checks.ErrorIs(t, err, openai.ErrModerationInputTypeInvalid, "unsupported input type")
+
+	_, err = client.Moderations(ctx, openai.ModerationRequest{
+		Model: openai.ModerationTextLatest,
+		Input: []openai.ModerationInputPart{{Type: openai.ModerationInputTypeImageURL}},
+	})
+	checks.ErrorIs(t, err, openai.ErrModerationImageNotSupported, "text models cannot moderate images")
+}
@@ -0,0 +1,116 @@
+package openai
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+const (
+	id3v2HeaderSize   = 10
+	id3v2FooterFlag   = 0x10
+	mp3HeaderSize     = 4
+	mp3LayerIII       = 1
+	mp3VersionMPEG1   = 3
+	mp3VersionMPEG25  = 0
+	mp3VBRHeaderScope = 64 // the Xing, Info and VBRI headers are within the first bytes of their frame
+)
+
+var ErrMP3Invalid = errors.New("no MPEG layer III audio frames found")
+
+var (
+	mp3BitratesMPEG1 = [...]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesMPEG2 = [...]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3SampleRates   = [...]int{44100, 48000, 32000}
+)
+
+// mp3Frame is the position and duration of an MPEG layer III frame.
+type mp3Frame struct {
+	offset     int
+	length     int
+	samples    int
+	sampleRate int
+}
+
+// parseMP3FrameHeader decodes the 4 byte header of an MPEG layer III frame.
+func parseMP3FrameHeader(header []byte) (frame mp3Frame, ok bool) {
+	if len(header) < mp3HeaderSize || header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return
+	}
+	version := int(header[1]>>3) & 3
+	layer := int(header[1]>>1) & 3
+	bitrateIndex := int(header[2] >> 4)
+	sampleRateIndex := int(header[2]>>2) & 3
+	padding := int(header[2]>>1) & 1
+	if version == 1 || layer != mp3LayerIII || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return
+	}
+
+	frame.sampleRate = mp3SampleRates[sampleRateIndex]
+	bitrate := mp3BitratesMPEG1[bitrateIndex]
+	coefficient := 144
+	frame.samples = 1152
+	if version != mp3VersionMPEG1 {
+		bitrate = mp3BitratesMPEG2[bitrateIndex]
+		coefficient = 72
+		frame.samples = 576
+		frame.sampleRate /= 2
+		if version == mp3VersionMPEG25 {
+			frame.sampleRate /= 2
+		}
+	}
+	frame.length = coefficient*bitrate*1000/frame.sampleRate + padding
+	return frame, true
+}
+
+// mp3AudioFrames returns the audio frames of an MP3 file, skipping ID3 tags,
+// Xing, Info and VBRI header frames and any data between frames.
+func mp3AudioFrames(data []byte) ([]mp3Frame, error) {
+	offset := 0
+	if len(data) >= id3v2HeaderSize && bytes.HasPrefix(data, []byte("ID3")) {
+		// the tag size is a syncsafe integer which excludes the header and footer
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		offset = id3v2HeaderSize + size
+		if data[5]&id3v2FooterFlag != 0 {
+			offset += id3v2HeaderSize
+		}
+	}
+
+	var frames []mp3Frame
+	for offset+mp3HeaderSize <= len(data) {
+		frame, ok := parseMP3FrameHeader(data[offset:])
+		if !ok || offset+frame.length > len(data) {
+			// resynchronize on the next frame header
+			offset++
+			continue
+		}
+		frame.offset = offset
+		offset += frame.length
+
+		if len(frames) == 0 && isMP3VBRHeader(data[frame.offset:offset]) {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) == 0 {
+		return nil, ErrMP3Invalid
+	}
+	return frames, nil
+}
+
+func isMP3VBRHeader(frame []byte) bool {
+	if len(frame) > mp3VBRHeaderScope {
+		frame = frame[:mp3VBRHeaderScope]
+	}
+	return bytes.Contains(frame, []byte("Xing")) || bytes.Contains(frame, []byte("Info")) ||
+		bytes.Contains(frame, []byte("VBRI"))
+}
+
+// mp3FramesDuration returns the duration of the frames.
+func mp3FramesDuration(frames []mp3Frame) time.Duration {
+	var duration time.Duration
+	for _, frame := range frames {
+		duration += time.Duration(frame.samples) * time.Second / time.Duration(frame.sampleRate)
+	}
+	return duration
+}
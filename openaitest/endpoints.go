@@ -0,0 +1,523 @@
+package openaitest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+)
+
+const defaultEmbeddingDimensions = 8
+
+type handler func(request Request, params map[string]string) Response
+
+type route struct {
+	method  string
+	pattern string
+	handle  handler
+}
+
+func (s *Server) defaultRoutes() []*route {
+	routes := []*route{
+		{http.MethodPost, "/chat/completions", s.chatCompletion},
+		{http.MethodPost, "/completions", s.completion},
+		{http.MethodPost, "/embeddings", s.embeddings},
+		{http.MethodPost, "/audio/transcriptions", s.transcription},
+		{http.MethodPost, "/audio/translations", s.transcription},
+		{http.MethodPost, "/audio/speech", s.speech},
+		{http.MethodPost, "/files", s.createFile},
+		{http.MethodGet, "/files/{id}/content", s.fileContent},
+		{http.MethodDelete, "/files/{id}", s.deleteFile},
+		{http.MethodPost, "/batches/{id}/cancel", s.cancelBatch},
+	}
+
+	collections := []collection{
+		{
+			path:        "/files",
+			object:      "file",
+			prefix:      "file-",
+			placeholder: map[string]any{"status": "processed", "bytes": 0, "filename": "", "purpose": ""},
+			filters:     []string{"purpose"},
+		},
+		{
+			path:        "/batches",
+			object:      "batch",
+			prefix:      "batch_",
+			placeholder: map[string]any{"status": "completed"},
+			prepare: func(object map[string]any, _ map[string]string) {
+				object["status"] = "validating"
+				object["request_counts"] = map[string]int{"total": 0, "completed": 0, "failed": 0}
+			},
+		},
+		{
+			path:   "/assistants",
+			object: "assistant",
+			prefix: "asst_",
+			prepare: func(object map[string]any, _ map[string]string) {
+				setDefault(object, "tools", []any{})
+				setDefault(object, "metadata", map[string]any{})
+			},
+		},
+		{
+			path:   "/threads",
+			object: "thread",
+			prefix: "thread_",
+			prepare: func(object map[string]any, _ map[string]string) {
+				setDefault(object, "metadata", map[string]any{})
+				messages, _ := object["messages"].([]any)
+				delete(object, "messages")
+				threadID, _ := object["id"].(string)
+				for _, message := range messages {
+					if message, ok := message.(map[string]any); ok {
+						s.newObject(messageCollection, message, map[string]string{"thread_id": threadID})
+						s.store("/threads/"+threadID+"/messages", message)
+					}
+				}
+			},
+		},
+		messageCollection,
+		{
+			path:        "/vector_stores",
+			object:      "vector_store",
+			prefix:      "vs_",
+			placeholder: map[string]any{"status": "completed"},
+			prepare: func(object map[string]any, _ map[string]string) {
+				fileIDs, _ := object["file_ids"].([]any)
+				delete(object, "file_ids")
+				object["status"] = "completed"
+				object["file_counts"] = map[string]int{"completed": len(fileIDs), "total": len(fileIDs)}
+				setDefault(object, "metadata", map[string]any{})
+				storeID, _ := object["id"].(string)
+				for _, fileID := range fileIDs {
+					file := map[string]any{"file_id": fileID}
+					s.newObject(vectorStoreFileCollection, file, map[string]string{"vector_store_id": storeID})
+					s.store("/vector_stores/"+storeID+"/files", file)
+				}
+			},
+		},
+		vectorStoreFileCollection,
+	}
+	for _, c := range collections {
+		routes = append(routes, s.collectionRoutes(c)...)
+	}
+	return routes
+}
+
+var (
+	messageCollection = collection{
+		path:   "/threads/{thread_id}/messages",
+		object: "thread.message",
+		prefix: "msg_",
+		prepare: func(object map[string]any, params map[string]string) {
+			object["thread_id"] = params["thread_id"]
+			setDefault(object, "role", "user")
+			setDefault(object, "metadata", map[string]any{})
+			if content, ok := object["content"].(string); ok {
+				object["content"] = []any{map[string]any{
+					"type": "text",
+					"text": map[string]any{"value": content, "annotations": []any{}},
+				}}
+			}
+		},
+	}
+	vectorStoreFileCollection = collection{
+		path:        "/vector_stores/{vector_store_id}/files",
+		object:      "vector_store.file",
+		prefix:      "file-",
+		placeholder: map[string]any{"status": "completed"},
+		prepare: func(object map[string]any, params map[string]string) {
+			if fileID, ok := object["file_id"].(string); ok {
+				object["id"] = fileID
+			}
+			delete(object, "file_id")
+			object["vector_store_id"] = params["vector_store_id"]
+			object["status"] = "completed"
+		},
+	}
+)
+
+func setDefault(object map[string]any, key string, value any) {
+	if _, ok := object[key]; !ok {
+		object[key] = value
+	}
+}
+
+// defaultResponse answers request with the default route which matches it.
+func (s *Server) defaultResponse(request Request) Response {
+	for _, route := range s.defaults {
+		if route.method != request.Method {
+			continue
+		}
+		if params, ok := matchPath(route.pattern, request.Path); ok {
+			return route.handle(request, params)
+		}
+	}
+	return APIError(http.StatusNotFound, "invalid_request_error",
+		fmt.Sprintf("Unknown request URL: %s %s%s.", request.Method, apiPrefix, request.Path))
+}
+
+// reply is the content of the default chat and completion responses: it echoes the prompt.
+func reply(prompt string) string {
+	if prompt == "" {
+		return "OK"
+	}
+	return "You said: " + prompt
+}
+
+// chatCompletion answers with the last message of the request, streamed word by word if requested.
+func (s *Server) chatCompletion(request Request, _ map[string]string) Response {
+	var chatRequest openai.ChatCompletionRequest
+	if err := request.DecodeJSON(&chatRequest); err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	var prompt string
+	promptTokens := 0
+	for _, message := range chatRequest.Messages {
+		text := message.Content
+		for _, part := range message.MultiContent {
+			text += part.Text
+		}
+		prompt = text
+		promptTokens += openai.ApproximateTokenCount(text)
+	}
+	content := reply(prompt)
+	usage := openai.Usage{PromptTokens: promptTokens, CompletionTokens: openai.ApproximateTokenCount(content)}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	id := s.newID("chatcmpl-")
+	created := time.Now().Unix()
+
+	if !chatRequest.Stream {
+		return Response{Body: openai.ChatCompletionResponse{
+			ID:      id,
+			Object:  "chat.completion",
+			Created: created,
+			Model:   chatRequest.Model,
+			Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+				FinishReason: openai.FinishReasonStop,
+			}},
+			Usage: usage,
+		}}
+	}
+
+	chunk := func(delta openai.ChatCompletionStreamChoiceDelta, reason openai.FinishReason) any {
+		return openai.ChatCompletionStreamResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   chatRequest.Model,
+			Choices: []openai.ChatCompletionStreamChoice{{Delta: delta, FinishReason: reason}},
+		}
+	}
+	stream := []any{chunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, "")}
+	for _, word := range strings.SplitAfter(content, " ") {
+		stream = append(stream, chunk(openai.ChatCompletionStreamChoiceDelta{Content: word}, ""))
+	}
+	stream = append(stream, chunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop))
+	if chatRequest.StreamOptions != nil && chatRequest.StreamOptions.IncludeUsage {
+		stream = append(stream, openai.ChatCompletionStreamResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   chatRequest.Model,
+			Choices: []openai.ChatCompletionStreamChoice{},
+			Usage:   &usage,
+		})
+	}
+	return Response{Stream: stream}
+}
+
+// completion answers with the prompt of the request, streamed word by word if requested.
+func (s *Server) completion(request Request, _ map[string]string) Response {
+	var completionRequest openai.CompletionRequest
+	if err := request.DecodeJSON(&completionRequest); err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	var prompt string
+	switch p := completionRequest.Prompt.(type) {
+	case string:
+		prompt = p
+	case []any:
+		if len(p) > 0 {
+			prompt = fmt.Sprint(p[0])
+		}
+	}
+	text := reply(prompt)
+	response := openai.CompletionResponse{
+		ID:      s.newID("cmpl-"),
+		Object:  "text_completion",
+		Created: time.Now().Unix(),
+		Model:   completionRequest.Model,
+	}
+
+	if !completionRequest.Stream {
+		response.Choices = []openai.CompletionChoice{{Text: text, FinishReason: "stop"}}
+		response.Usage.PromptTokens = openai.ApproximateTokenCount(prompt)
+		response.Usage.CompletionTokens = openai.ApproximateTokenCount(text)
+		response.Usage.TotalTokens = response.Usage.PromptTokens + response.Usage.CompletionTokens
+		return Response{Body: response}
+	}
+
+	var stream []any
+	words := strings.SplitAfter(text, " ")
+	for i, word := range words {
+		chunk := response
+		chunk.Choices = []openai.CompletionChoice{{Text: word}}
+		if i == len(words)-1 {
+			chunk.Choices[0].FinishReason = "stop"
+		}
+		stream = append(stream, chunk)
+	}
+	return Response{Stream: stream}
+}
+
+// embeddings answers with a deterministic unit vector for every input.
+func (s *Server) embeddings(request Request, _ map[string]string) Response {
+	var embeddingRequest openai.EmbeddingRequest
+	if err := request.DecodeJSON(&embeddingRequest); err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	var inputs []string
+	switch input := embeddingRequest.Input.(type) {
+	case string:
+		inputs = []string{input}
+	case []any:
+		if len(input) == 0 {
+			return invalidRequest("input must not be empty")
+		}
+		if _, tokens := input[0].(float64); tokens {
+			inputs = []string{fmt.Sprint(input)}
+			break
+		}
+		for _, item := range input {
+			inputs = append(inputs, fmt.Sprint(item))
+		}
+	default:
+		return invalidRequest("input must be a string, an array of strings or an array of tokens")
+	}
+
+	dimensions := embeddingRequest.Dimensions
+	if dimensions <= 0 {
+		dimensions = defaultEmbeddingDimensions
+	}
+	data := make([]map[string]any, len(inputs))
+	tokens := 0
+	for i, input := range inputs {
+		vector := Embedding(input, dimensions)
+		var embedding any = vector
+		if embeddingRequest.EncodingFormat == openai.EmbeddingEncodingFormatBase64 {
+			buf := new(bytes.Buffer)
+			_ = binary.Write(buf, binary.LittleEndian, vector)
+			embedding = base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+		data[i] = map[string]any{"object": "embedding", "index": i, "embedding": embedding}
+		tokens += openai.ApproximateTokenCount(input)
+	}
+	return Response{Body: map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  embeddingRequest.Model,
+		"usage":  map[string]int{"prompt_tokens": tokens, "total_tokens": tokens},
+	}}
+}
+
+// Embedding returns the vector of input in the default embeddings responses: a unit vector
+// derived from a hash of input, so that equal inputs have equal embeddings.
+func Embedding(input string, dimensions int) []float32 {
+	vector := make([]float32, dimensions)
+	var norm float64
+	for i := range vector {
+		hash := fnv.New64a()
+		fmt.Fprintf(hash, "%d:%s", i, input)
+		value := float64(hash.Sum64())/math.MaxUint64*2 - 1
+		vector[i] = float32(value)
+		norm += value * value
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
+
+// transcription answers with a transcription naming the uploaded file, in the requested format.
+func (s *Server) transcription(request Request, _ map[string]string) Response {
+	form, err := request.MultipartForm()
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+	filename := "audio"
+	if files := form.File["file"]; len(files) > 0 {
+		filename = files[0].Filename
+	}
+	text := "This is a transcription of " + filename + "."
+	value := func(key string) string {
+		if values := form.Value[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	if value("stream") == "true" {
+		var stream []any
+		for _, word := range strings.SplitAfter(text, " ") {
+			stream = append(stream, openai.TranscriptionStreamEvent{
+				Type:  openai.TranscriptionStreamEventTextDelta,
+				Delta: word,
+			})
+		}
+		stream = append(stream, openai.TranscriptionStreamEvent{Type: openai.TranscriptionStreamEventTextDone, Text: text})
+		return Response{Stream: stream}
+	}
+
+	plainText := http.Header{"Content-Type": {"text/plain"}}
+	switch openai.AudioResponseFormat(value("response_format")) {
+	case openai.AudioResponseFormatText:
+		return Response{Body: text, Header: plainText}
+	case openai.AudioResponseFormatSRT:
+		return Response{Body: "1\n00:00:00,000 --> 00:00:01,000\n" + text + "\n", Header: plainText}
+	case openai.AudioResponseFormatVTT:
+		return Response{Body: "WEBVTT\n\n00:00:00.000 --> 00:00:01.000\n" + text + "\n", Header: plainText}
+	case openai.AudioResponseFormatVerboseJSON:
+		task := "transcribe"
+		if strings.HasSuffix(request.Path, "/translations") {
+			task = "translate"
+		}
+		return Response{Body: openai.AudioResponse{
+			Task:     task,
+			Language: "english",
+			Duration: 1,
+			Segments: []openai.TranscriptionSegment{{End: 1, Text: text}},
+			Text:     text,
+		}}
+	default:
+		return Response{Body: map[string]string{"text": text}}
+	}
+}
+
+// speech answers with placeholder audio bytes, streamed as events if requested.
+func (s *Server) speech(request Request, _ map[string]string) Response {
+	var speechRequest openai.CreateSpeechRequest
+	if err := request.DecodeJSON(&speechRequest); err != nil {
+		return invalidRequest(err.Error())
+	}
+	audio := []byte("fake audio: " + speechRequest.Input)
+
+	if speechRequest.StreamFormat == openai.SpeechStreamFormatSSE {
+		return Response{Stream: []any{
+			openai.SpeechStreamEvent{
+				Type:  openai.SpeechStreamEventAudioDelta,
+				Audio: base64.StdEncoding.EncodeToString(audio),
+			},
+			openai.SpeechStreamEvent{Type: openai.SpeechStreamEventAudioDone},
+		}}
+	}
+
+	contentType := "audio/mpeg"
+	switch speechRequest.ResponseFormat {
+	case openai.SpeechResponseFormatOpus:
+		contentType = "audio/opus"
+	case openai.SpeechResponseFormatAac:
+		contentType = "audio/aac"
+	case openai.SpeechResponseFormatFlac:
+		contentType = "audio/flac"
+	case openai.SpeechResponseFormatWav:
+		contentType = "audio/wav"
+	case openai.SpeechResponseFormatPcm:
+		contentType = "audio/pcm"
+	}
+	return Response{Body: audio, Header: http.Header{"Content-Type": {contentType}}}
+}
+
+// createFile stores an uploaded file and its content.
+func (s *Server) createFile(request Request, _ map[string]string) Response {
+	form, err := request.MultipartForm()
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return invalidRequest("'file' is a required property")
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	object := map[string]any{
+		"id":         s.newID("file-"),
+		"object":     "file",
+		"bytes":      len(content),
+		"created_at": time.Now().Unix(),
+		"filename":   files[0].Filename,
+		"purpose":    "",
+		"status":     "processed",
+	}
+	if purposes := form.Value["purpose"]; len(purposes) > 0 {
+		object["purpose"] = purposes[0]
+	}
+	if s.stateful {
+		s.store("/files", object)
+		s.mu.Lock()
+		s.state.contents[object["id"].(string)] = content
+		s.mu.Unlock()
+	}
+	return Response{Body: object}
+}
+
+// fileContent returns the content of a stored file. Without state, the content is empty.
+func (s *Server) fileContent(_ Request, params map[string]string) Response {
+	if !s.stateful {
+		return Response{Body: []byte{}}
+	}
+	s.mu.Lock()
+	content, ok := s.state.contents[params["id"]]
+	s.mu.Unlock()
+	if !ok {
+		return notFound("file", params["id"])
+	}
+	return Response{Body: content, Header: http.Header{"Content-Type": {"application/octet-stream"}}}
+}
+
+func (s *Server) deleteFile(_ Request, params map[string]string) Response {
+	if s.stateful {
+		if !s.remove("/files", params["id"]) {
+			return notFound("file", params["id"])
+		}
+		s.mu.Lock()
+		delete(s.state.contents, params["id"])
+		s.mu.Unlock()
+	}
+	return Response{Body: map[string]any{"id": params["id"], "object": "file", "deleted": true}}
+}
+
+func (s *Server) cancelBatch(request Request, params map[string]string) Response {
+	batches := collection{object: "batch", placeholder: map[string]any{}}
+	batch, ok := s.objectOrPlaceholder(batches, parentPath(request.Path), params["id"])
+	if !ok {
+		return notFound("batch", params["id"])
+	}
+	cancelled := make(map[string]any, len(batch))
+	for key, value := range batch {
+		cancelled[key] = value
+	}
+	cancelled["status"] = "cancelling"
+	s.store("/batches", cancelled)
+	return Response{Body: cancelled}
+}
@@ -0,0 +1,604 @@
+package openaitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+)
+
+const (
+	// Redacted replaces the values of the redacted headers in cassettes.
+	Redacted = "REDACTED"
+
+	encodingBase64 = "base64"
+)
+
+var (
+	ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
+	ErrRecorderReplaying   = errors.New("recorder is replaying")
+)
+
+// RecorderMode selects whether a Recorder records or replays.
+type RecorderMode int
+
+const (
+	// ModeReplayOrRecord replays the cassette if its file exists, and records it otherwise.
+	ModeReplayOrRecord RecorderMode = iota
+	// ModeReplay replays the cassette, whose file must exist. Requests never reach the network.
+	ModeReplay
+	// ModeRecord sends the requests and records them, replacing the cassette.
+	ModeRecord
+)
+
+// Cassette is the content of a cassette file: the interactions a Recorder recorded, in order.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request. Multipart bodies, such as file uploads, are recorded as Parts,
+// so that they can be matched regardless of their random boundary.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// Encoding is "base64" if Body is base64 encoded, because it is not valid UTF-8.
+	Encoding string         `json:"encoding,omitempty"`
+	Parts    []RecordedPart `json:"parts,omitempty"`
+}
+
+// RecordedPart is a part of a recorded multipart request body.
+type RecordedPart struct {
+	Name        string `json:"name"`
+	FileName    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+	Encoding    string `json:"encoding,omitempty"`
+}
+
+// RecordedResponse is a recorded response. Streamed bodies of server-sent events are recorded as Chunks,
+// in the pieces they were read, with the delay before each of them.
+type RecordedResponse struct {
+	Status   int             `json:"status"`
+	Header   http.Header     `json:"header,omitempty"`
+	Body     string          `json:"body,omitempty"`
+	Encoding string          `json:"encoding,omitempty"`
+	Chunks   []RecordedChunk `json:"chunks,omitempty"`
+	// Truncated reports that reading the body failed before its end, as when the connection is lost.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RecordedChunk is a piece of a streamed response body.
+type RecordedChunk struct {
+	// Delay is the time, in nanoseconds, between the previous chunk, or the response headers, and the chunk.
+	Delay    time.Duration `json:"delay"`
+	Data     string        `json:"data"`
+	Encoding string        `json:"encoding,omitempty"`
+}
+
+// Matcher reports whether a request matches a recorded request.
+type Matcher func(request, recorded RecordedRequest) bool
+
+// MatchMethod matches requests with the same method.
+func MatchMethod(request, recorded RecordedRequest) bool {
+	return request.Method == recorded.Method
+}
+
+// MatchPath matches requests with the same URL path, regardless of the host.
+func MatchPath(request, recorded RecordedRequest) bool {
+	return urlPart(request.URL, (*url.URL).EscapedPath) == urlPart(recorded.URL, (*url.URL).EscapedPath)
+}
+
+// MatchQuery matches requests with the same query parameters, in any order.
+func MatchQuery(request, recorded RecordedRequest) bool {
+	return urlPart(request.URL, queryString) == urlPart(recorded.URL, queryString)
+}
+
+// MatchBody matches requests with the same body. JSON bodies are compared regardless of their formatting
+// and of the order of their fields, and multipart bodies regardless of their boundary.
+func MatchBody(request, recorded RecordedRequest) bool {
+	if request.Parts != nil || recorded.Parts != nil {
+		if len(request.Parts) != len(recorded.Parts) {
+			return false
+		}
+		for i := range request.Parts {
+			if request.Parts[i] != recorded.Parts[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return normalizeJSON(request.Body) == normalizeJSON(recorded.Body) && request.Encoding == recorded.Encoding
+}
+
+// Recorder is an openai.HTTPDoer which records the requests of a client and their responses to a cassette file,
+// and replays them offline. It is safe for concurrent use.
+//
+//	recorder, err := openaitest.NewRecorder("testdata/chat.json")
+//	...
+//	defer recorder.Save()
+//	config := openai.DefaultConfig(os.Getenv("OPENAI_API_KEY"))
+//	config.HTTPClient = recorder
+//
+// Recorded Authorization and api-key headers are redacted.
+type Recorder struct {
+	path          string
+	mode          RecorderMode
+	client        openai.HTTPDoer
+	matchers      []Matcher
+	redacted      []string
+	replayTimings bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     map[*Interaction]bool
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithMode sets the mode of the recorder. Defaults to ModeReplayOrRecord.
+func WithMode(mode RecorderMode) RecorderOption {
+	return func(r *Recorder) {
+		r.mode = mode
+	}
+}
+
+// WithHTTPClient sets the client which sends the requests when recording. Defaults to http.DefaultClient.
+func WithHTTPClient(client openai.HTTPDoer) RecorderOption {
+	return func(r *Recorder) {
+		r.client = client
+	}
+}
+
+// WithMatchers sets how replayed requests are matched with the recorded ones.
+// Defaults to MatchMethod, MatchPath and MatchBody.
+func WithMatchers(matchers ...Matcher) RecorderOption {
+	return func(r *Recorder) {
+		r.matchers = matchers
+	}
+}
+
+// WithRedactedHeaders redacts more headers in the cassette, in addition to Authorization and api-key.
+func WithRedactedHeaders(headers ...string) RecorderOption {
+	return func(r *Recorder) {
+		r.redacted = append(r.redacted, headers...)
+	}
+}
+
+// WithStreamTimings makes the recorder replay the chunks of streamed responses with their recorded delays.
+// By default, they are replayed without delay.
+func WithStreamTimings() RecorderOption {
+	return func(r *Recorder) {
+		r.replayTimings = true
+	}
+}
+
+// NewRecorder returns a recorder of the cassette file at path.
+func NewRecorder(path string, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		client:   http.DefaultClient,
+		matchers: []Matcher{MatchMethod, MatchPath, MatchBody},
+		redacted: []string{"Authorization", openai.AzureAPIKeyHeader},
+		used:     map[*Interaction]bool{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && r.mode == ModeReplayOrRecord {
+		r.mode = ModeRecord
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	r.mode = ModeReplay
+	return r, nil
+}
+
+// Recording reports whether the recorder records, rather than replays.
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// Interactions returns the recorded or replayed interactions.
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file. Streamed response bodies should be closed first.
+// It returns ErrRecorderReplaying if the recorder is replaying.
+func (r *Recorder) Save() error {
+	if !r.Recording() {
+		return ErrRecorderReplaying
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(r.path)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(r.path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), r.path)
+}
+
+// Do records or replays req.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := r.recordRequest(req, body)
+
+	if r.Recording() {
+		return r.record(req, body, recorded)
+	}
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, recorded RecordedRequest) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			Status: res.StatusCode,
+			Header: r.redact(res.Header),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	if isEventStream(res.Header) {
+		res.Body = &chunkRecorder{body: res.Body, recorder: r, interaction: interaction, last: time.Now()}
+		return res, nil
+	}
+
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	r.mu.Lock()
+	interaction.Response.Body, interaction.Response.Encoding = encodeBody(data)
+	interaction.Response.Truncated = err != nil
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	interaction := r.match(recorded)
+	if interaction == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, req.URL)
+	}
+	response := interaction.Response
+
+	res := &http.Response{
+		Status:     fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode: response.Status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     response.Header.Clone(),
+		Request:    req,
+	}
+	if res.Header == nil {
+		res.Header = http.Header{}
+	}
+	if response.Chunks != nil {
+		res.ContentLength = -1
+		res.Body = &chunkReplayer{
+			ctx:       req.Context().Done(),
+			chunks:    response.Chunks,
+			timings:   r.replayTimings,
+			truncated: response.Truncated,
+		}
+		return res, nil
+	}
+
+	data, err := decodeBody(response.Body, response.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	res.ContentLength = int64(len(data))
+	var reader io.Reader = bytes.NewReader(data)
+	if response.Truncated {
+		reader = io.MultiReader(reader, errorReader{io.ErrUnexpectedEOF})
+	}
+	res.Body = io.NopCloser(reader)
+	return res, nil
+}
+
+// match returns the first recorded interaction which matches request and was not replayed yet, or nil.
+func (r *Recorder) match(request RecordedRequest) *Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, interaction := range r.cassette.Interactions {
+		if r.used[interaction] || !r.matches(request, interaction.Request) {
+			continue
+		}
+		r.used[interaction] = true
+		return interaction
+	}
+	return nil
+}
+
+func (r *Recorder) matches(request, recorded RecordedRequest) bool {
+	for _, matcher := range r.matchers {
+		if !matcher(request, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: r.redact(req.Header),
+	}
+	if parts, ok := recordParts(req.Header, body); ok {
+		recorded.Parts = parts
+	} else {
+		recorded.Body, recorded.Encoding = encodeBody(body)
+	}
+	return recorded
+}
+
+func (r *Recorder) redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range r.redacted {
+		if header.Get(name) != "" {
+			header.Set(name, Redacted)
+		}
+	}
+	return header
+}
+
+// recordParts records the parts of a multipart body, and reports false if the body is not multipart.
+func recordParts(header http.Header, body []byte) ([]RecordedPart, bool) {
+	request := Request{Header: header, Body: body}
+	form, err := request.MultipartForm()
+	if err != nil {
+		return nil, false
+	}
+	defer form.RemoveAll() //nolint:errcheck // the form is in memory unless it is very large.
+
+	// The parts are recorded in sorted order, as the order of the fields of a form is not kept.
+	parts := []RecordedPart{}
+	for _, name := range sortedKeys(form.Value) {
+		for _, value := range form.Value[name] {
+			part := RecordedPart{Name: name}
+			part.Body, part.Encoding = encodeBody([]byte(value))
+			parts = append(parts, part)
+		}
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, fileHeader := range form.File[name] {
+			file, openErr := fileHeader.Open()
+			if openErr != nil {
+				return nil, false
+			}
+			data, readErr := io.ReadAll(file)
+			file.Close()
+			if readErr != nil {
+				return nil, false
+			}
+			part := RecordedPart{
+				Name:        name,
+				FileName:    fileHeader.Filename,
+				ContentType: fileHeader.Header.Get("Content-Type"),
+			}
+			part.Body, part.Encoding = encodeBody(data)
+			parts = append(parts, part)
+		}
+	}
+	return parts, true
+}
+
+// chunkRecorder records the chunks of a streamed response body as they are read.
+type chunkRecorder struct {
+	body        io.ReadCloser
+	recorder    *Recorder
+	interaction *Interaction
+	last        time.Time
+}
+
+func (c *chunkRecorder) Read(p []byte) (int, error) {
+	n, err := c.body.Read(p)
+	c.recorder.mu.Lock()
+	defer c.recorder.mu.Unlock()
+	response := &c.interaction.Response
+	if n > 0 {
+		now := time.Now()
+		chunk := RecordedChunk{Delay: now.Sub(c.last)}
+		chunk.Data, chunk.Encoding = encodeBody(p[:n])
+		response.Chunks = append(response.Chunks, chunk)
+		c.last = now
+	}
+	if response.Chunks == nil {
+		response.Chunks = []RecordedChunk{}
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		response.Truncated = true
+	}
+	return n, err
+}
+
+func (c *chunkRecorder) Close() error {
+	return c.body.Close()
+}
+
+// chunkReplayer replays the chunks of a streamed response body.
+type chunkReplayer struct {
+	ctx       <-chan struct{}
+	chunks    []RecordedChunk
+	timings   bool
+	truncated bool
+
+	data []byte
+}
+
+func (c *chunkReplayer) Read(p []byte) (int, error) {
+	for len(c.data) == 0 {
+		if len(c.chunks) == 0 {
+			if c.truncated {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, io.EOF
+		}
+		chunk := c.chunks[0]
+		c.chunks = c.chunks[1:]
+		if c.timings && chunk.Delay > 0 {
+			timer := time.NewTimer(chunk.Delay)
+			select {
+			case <-c.ctx:
+				timer.Stop()
+				return 0, context.Canceled
+			case <-timer.C:
+			}
+		}
+		data, err := decodeBody(chunk.Data, chunk.Encoding)
+		if err != nil {
+			return 0, err
+		}
+		c.data = data
+	}
+	n := copy(p, c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
+
+func (c *chunkReplayer) Close() error {
+	return nil
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func isEventStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// encodeBody returns data as a string, base64 encoded if it is not valid UTF-8, and its encoding.
+func encodeBody(data []byte) (string, string) {
+	if utf8.Valid(data) {
+		return string(data), ""
+	}
+	return base64.StdEncoding.EncodeToString(data), encodingBase64
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == encodingBase64 {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+// normalizeJSON returns body with sorted fields and without insignificant whitespace, if it is JSON.
+func normalizeJSON(body string) string {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return string(normalized)
+}
+
+func urlPart(rawURL string, part func(*url.URL) string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return part(u)
+}
+
+func queryString(u *url.URL) string {
+	return u.Query().Encode()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
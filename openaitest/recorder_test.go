@@ -0,0 +1,226 @@
+package openaitest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+	"gitlab.forensix.cn/ai/service/go-openai/openaitest"
+)
+
+// recorderClient returns a client of the server at baseURL which sends its requests through recorder.
+func recorderClient(baseURL string, recorder *openaitest.Recorder) *openai.Client {
+	config := openai.DefaultConfig("sk-secret")
+	config.BaseURL = baseURL + "/v1"
+	config.HTTPClient = recorder
+	return openai.NewClientWithConfig(config)
+}
+
+func readStream(t *testing.T, stream *openai.ChatCompletionStream) string {
+	t.Helper()
+	defer stream.Close()
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return content
+		}
+		checks.NoError(t, err, "Recv error")
+		for _, choice := range chunk.Choices {
+			content += choice.Delta.Content
+		}
+	}
+}
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "chat.json")
+	ctx := context.Background()
+
+	server := openaitest.NewServer(openaitest.WithAPIKey("sk-secret"))
+	server.On(http.MethodPost, "/chat/completions").Reply(openaitest.Response{
+		Stream: []any{
+			openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+				{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}},
+			}},
+			openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+				{Delta: openai.ChatCompletionStreamChoiceDelta{Content: " world"}},
+			}},
+		},
+		StreamInterval: 50 * time.Millisecond,
+	})
+	server.On(http.MethodPost, "/embeddings").Reply(openaitest.Response{})
+
+	recorder, err := openaitest.NewRecorder(path)
+	checks.NoError(t, err, "NewRecorder error")
+	if !recorder.Recording() {
+		t.Fatal("recorder should record when the cassette does not exist")
+	}
+	client := recorderClient(server.URL, recorder)
+
+	stream, err := client.CreateChatCompletionStream(ctx, chatRequest("Hi"))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	content := readStream(t, stream)
+	if content != "Hello world" {
+		t.Errorf("unexpected recorded stream content %q", content)
+	}
+	embeddings, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{Model: openai.SmallEmbedding3, Input: "a"})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	file, err := client.CreateFileBytes(ctx, openai.FileBytesRequest{
+		Name:    "data.jsonl",
+		Bytes:   []byte{0xff, 0xfe, 0x00},
+		Purpose: openai.PurposeBatch,
+	})
+	checks.NoError(t, err, "CreateFileBytes error")
+	checks.NoError(t, recorder.Save(), "Save error")
+	server.Close()
+
+	cassette, err := os.ReadFile(path)
+	checks.NoError(t, err, "ReadFile error")
+	if strings.Contains(string(cassette), "sk-secret") || !strings.Contains(string(cassette), openaitest.Redacted) {
+		t.Errorf("the API key was not redacted from the cassette")
+	}
+	interactions := recorder.Interactions()
+	if len(interactions) != 3 {
+		t.Fatalf("expected 3 interactions, got %d", len(interactions))
+	}
+	// The [DONE] message may be read with the last chunk or on its own right after it.
+	chunks := interactions[0].Response.Chunks
+	delayed := false
+	for i := 1; i < len(chunks); i++ {
+		delayed = delayed || chunks[i].Delay >= 40*time.Millisecond
+	}
+	if !delayed {
+		t.Errorf("the stream chunks were not recorded with their timing: %+v", chunks)
+	}
+	parts := interactions[2].Request.Parts
+	if len(parts) != 2 || parts[1].FileName != "data.jsonl" || parts[1].Encoding != "base64" {
+		t.Errorf("unexpected recorded multipart parts %+v", parts)
+	}
+
+	replayer, err := openaitest.NewRecorder(path, openaitest.WithStreamTimings())
+	checks.NoError(t, err, "NewRecorder error")
+	if replayer.Recording() {
+		t.Fatal("recorder should replay an existing cassette")
+	}
+	client = recorderClient(server.URL, replayer)
+
+	// The fields of the replayed request are in a different order than the recorded ones.
+	replayedEmbeddings, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: "a",
+		Model: openai.SmallEmbedding3,
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if replayedEmbeddings.Data[0].Embedding[0] != embeddings.Data[0].Embedding[0] {
+		t.Errorf("unexpected replayed embeddings %+v", replayedEmbeddings)
+	}
+
+	start := time.Now()
+	stream, err = client.CreateChatCompletionStream(ctx, chatRequest("Hi"))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	content = readStream(t, stream)
+	if content != "Hello world" || time.Since(start) < 40*time.Millisecond {
+		t.Errorf("unexpected replayed stream content %q after %s", content, time.Since(start))
+	}
+
+	replayedFile, err := client.CreateFileBytes(ctx, openai.FileBytesRequest{
+		Name:    "data.jsonl",
+		Bytes:   []byte{0xff, 0xfe, 0x00},
+		Purpose: openai.PurposeBatch,
+	})
+	checks.NoError(t, err, "CreateFileBytes error")
+	if replayedFile.ID != file.ID {
+		t.Errorf("unexpected replayed file %+v", replayedFile)
+	}
+
+	_, err = client.CreateEmbeddings(ctx, openai.EmbeddingRequest{Model: openai.SmallEmbedding3, Input: "a"})
+	if !errors.Is(err, openaitest.ErrInteractionNotFound) {
+		t.Errorf("expected ErrInteractionNotFound once the interaction is replayed, got %v", err)
+	}
+	if !errors.Is(replayer.Save(), openaitest.ErrRecorderReplaying) {
+		t.Errorf("expected ErrRecorderReplaying")
+	}
+}
+
+func TestRecorderMatchers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	ctx := context.Background()
+
+	server := openaitest.NewServer()
+	server.On(http.MethodGet, "/files").Reply(openaitest.Response{Body: openai.FilesList{
+		Files: []openai.File{{ID: "file-1"}},
+	}})
+	recorder, err := openaitest.NewRecorder(path,
+		openaitest.WithMode(openaitest.ModeRecord), openaitest.WithRedactedHeaders("OpenAI-Organization"))
+	checks.NoError(t, err, "NewRecorder error")
+	_, err = recorderClient(server.URL, recorder).ListFiles(ctx, openai.ListFilesWithPurpose("batch"))
+	checks.NoError(t, err, "ListFiles error")
+	checks.NoError(t, recorder.Save(), "Save error")
+	server.Close()
+
+	replayer, err := openaitest.NewRecorder(path, openaitest.WithMode(openaitest.ModeReplay))
+	checks.NoError(t, err, "NewRecorder error")
+	files, err := recorderClient("http://localhost:1", replayer).ListFiles(ctx)
+	checks.NoError(t, err, "ListFiles error")
+	if len(files.Files) != 1 {
+		t.Errorf("unexpected replayed files %+v", files)
+	}
+
+	replayer, err = openaitest.NewRecorder(path,
+		openaitest.WithMatchers(openaitest.MatchMethod, openaitest.MatchPath, openaitest.MatchQuery))
+	checks.NoError(t, err, "NewRecorder error")
+	_, err = recorderClient(server.URL, replayer).ListFiles(ctx)
+	if !errors.Is(err, openaitest.ErrInteractionNotFound) {
+		t.Errorf("expected ErrInteractionNotFound for a different query, got %v", err)
+	}
+
+	_, err = openaitest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), openaitest.WithMode(openaitest.ModeReplay))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist for a missing cassette, got %v", err)
+	}
+}
+
+func TestRecorderTruncatedStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drop.json")
+	ctx := context.Background()
+
+	server := openaitest.NewServer()
+	defer server.Close()
+	server.On(http.MethodPost, "/chat/completions").Reply(openaitest.Response{
+		Stream: []any{
+			openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+				{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "Hel"}},
+			}},
+			openaitest.StreamDrop{},
+		},
+	})
+
+	for _, mode := range []openaitest.RecorderMode{openaitest.ModeRecord, openaitest.ModeReplay} {
+		recorder, err := openaitest.NewRecorder(path, openaitest.WithMode(mode))
+		checks.NoError(t, err, "NewRecorder error")
+		stream, err := recorderClient(server.URL, recorder).CreateChatCompletionStream(ctx, chatRequest("Hi"))
+		checks.NoError(t, err, "CreateChatCompletionStream error")
+		chunk, err := stream.Recv()
+		checks.NoError(t, err, "Recv error")
+		if chunk.Choices[0].Delta.Content != "Hel" {
+			t.Errorf("unexpected chunk %+v", chunk)
+		}
+		if _, err = stream.Recv(); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("expected the truncated stream to fail in mode %d, got %v", mode, err)
+		}
+		stream.Close()
+		if recorder.Recording() {
+			checks.NoError(t, recorder.Save(), "Save error")
+			if !recorder.Interactions()[0].Response.Truncated {
+				t.Errorf("the stream was not recorded as truncated")
+			}
+		}
+	}
+}
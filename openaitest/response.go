@@ -0,0 +1,279 @@
+package openaitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+)
+
+// Response is a scripted response of the server.
+//
+// A response which sets none of Status, Body, Error and Stream decorates the default response of the route
+// with its Header, RateLimit and Latency.
+type Response struct {
+	// Status is the HTTP status code. Defaults to 200, or to the status code of Error.
+	Status int
+	Header http.Header
+	// Body is written as is if it is a string or a []byte, and JSON encoded otherwise.
+	Body any
+	// Error is written as the body of an OpenAI error response. Its HTTPStatusCode sets the default Status,
+	// or 500 if it is not set.
+	Error *openai.APIError
+	// RateLimit sets the x-ratelimit headers.
+	RateLimit *openai.RateLimitHeaders
+	// Latency delays the response.
+	Latency time.Duration
+
+	// Stream is written as server-sent events: strings and []byte as is, other values JSON encoded.
+	// The stream ends with the [DONE] event, unless a StreamDrop is reached first.
+	Stream []any
+	// StreamInterval is the delay between two events of Stream.
+	StreamInterval time.Duration
+}
+
+// StreamDrop, placed in Response.Stream, aborts the connection at that point of the stream,
+// as a network failure would.
+type StreamDrop struct{}
+
+// APIError returns a response with an OpenAI error.
+func APIError(status int, errType, message string) Response {
+	return Response{Error: &openai.APIError{
+		Type:           errType,
+		Message:        message,
+		HTTPStatusCode: status,
+	}}
+}
+
+// RateLimitError returns a 429 response with the rate limit headers of exhausted requests,
+// which are reset after reset.
+func RateLimitError(reset time.Duration) Response {
+	response := APIError(http.StatusTooManyRequests, "requests", "Rate limit reached for requests.")
+	response.Error.Code = "rate_limit_exceeded"
+	response.RateLimit = &openai.RateLimitHeaders{
+		LimitRequests:     60,
+		RemainingRequests: 0,
+		ResetRequests:     openai.ResetTime(reset.String()),
+	}
+	return response
+}
+
+func (r Response) decoratesDefault() bool {
+	return r.Status == 0 && r.Body == nil && r.Error == nil && r.Stream == nil
+}
+
+// decorate returns the default response with the headers, rate limit and latency of r.
+func (r Response) decorate(response Response) Response {
+	if len(r.Header) > 0 {
+		header := response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		for key, values := range r.Header {
+			header[key] = values
+		}
+		response.Header = header
+	}
+	if r.RateLimit != nil {
+		response.RateLimit = r.RateLimit
+	}
+	response.Latency += r.Latency
+	return response
+}
+
+// Route is a scripted route of the server, created by On.
+type Route struct {
+	mu      *sync.Mutex
+	method  string
+	pattern string
+
+	responses []Response
+	fn        func(Request) Response
+	calls     int
+}
+
+// On returns a route of the server whose responses can be scripted. An empty method matches every method.
+// Segments of path in braces, such as /files/{file_id}, or * match any segment; the /v1 prefix is optional.
+// Routes scripted last take precedence, and a route which has no response uses the default response.
+func (s *Server) On(method, path string) *Route {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	route := &Route{mu: &s.mu, method: method, pattern: path}
+	s.routes = append(s.routes, route)
+	return route
+}
+
+// Reply adds responses to the route. They are returned in order, and the last one is repeated.
+func (r *Route) Reply(responses ...Response) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, responses...)
+	return r
+}
+
+// ReplyFunc makes the route answer with the response computed by fn, once the responses of Reply are used.
+func (r *Route) ReplyFunc(fn func(Request) Response) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fn = fn
+	return r
+}
+
+// Calls returns the number of requests the route answered.
+func (r *Route) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+// scriptedResponse returns the response of the scripted route which matches request, if any.
+func (s *Server) scriptedResponse(request Request) (Response, bool) {
+	response, fn, ok := s.nextScriptedResponse(request)
+	if fn != nil {
+		response = fn(request)
+	}
+	return response, ok
+}
+
+func (s *Server) nextScriptedResponse(request Request) (Response, func(Request) Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		route := s.routes[i]
+		if route.method != "" && route.method != request.Method {
+			continue
+		}
+		if _, ok := matchPath(route.pattern, request.Path); !ok {
+			continue
+		}
+		if len(route.responses) == 0 && route.fn == nil {
+			continue
+		}
+
+		call := route.calls
+		route.calls++
+		switch {
+		case call < len(route.responses):
+			return route.responses[call], nil, true
+		case route.fn != nil:
+			return Response{}, route.fn, true
+		default:
+			return route.responses[len(route.responses)-1], nil, true
+		}
+	}
+	return Response{}, nil, false
+}
+
+func (s *Server) write(w http.ResponseWriter, r *http.Request, response Response) {
+	if response.Latency > 0 && !sleep(r, response.Latency) {
+		return
+	}
+
+	header := w.Header()
+	for key, values := range response.Header {
+		header[key] = values
+	}
+	if limit := response.RateLimit; limit != nil {
+		header.Set("x-ratelimit-limit-requests", strconv.Itoa(limit.LimitRequests))
+		header.Set("x-ratelimit-limit-tokens", strconv.Itoa(limit.LimitTokens))
+		header.Set("x-ratelimit-remaining-requests", strconv.Itoa(limit.RemainingRequests))
+		header.Set("x-ratelimit-remaining-tokens", strconv.Itoa(limit.RemainingTokens))
+		header.Set("x-ratelimit-reset-requests", limit.ResetRequests.String())
+		header.Set("x-ratelimit-reset-tokens", limit.ResetTokens.String())
+	}
+
+	status := response.Status
+	body := response.Body
+	if response.Error != nil {
+		if status == 0 {
+			status = response.Error.HTTPStatusCode
+		}
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		body = openai.ErrorResponse{Error: response.Error}
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if response.Stream != nil && response.Error == nil {
+		writeStream(w, r, status, response)
+		return
+	}
+
+	var data []byte
+	switch body := body.(type) {
+	case nil:
+	case string:
+		data = []byte(body)
+	case []byte:
+		data = body
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func writeStream(w http.ResponseWriter, r *http.Request, status int, response Response) {
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	flusher, _ := w.(http.Flusher)
+
+	for i, event := range response.Stream {
+		if i > 0 && response.StreamInterval > 0 && !sleep(r, response.StreamInterval) {
+			return
+		}
+
+		var data []byte
+		switch event := event.(type) {
+		case StreamDrop:
+			if flusher != nil {
+				flusher.Flush()
+			}
+			// The server closes the connection without ending the response.
+			panic(http.ErrAbortHandler)
+		case string:
+			data = []byte(event)
+		case []byte:
+			data = event
+		default:
+			var err error
+			if data, err = json.Marshal(event); err != nil {
+				panic(http.ErrAbortHandler)
+			}
+		}
+		_, _ = w.Write([]byte("data: "))
+		_, _ = w.Write(data)
+		_, _ = w.Write([]byte("\n\n"))
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	_, _ = w.Write([]byte("data: [DONE]\n\n"))
+}
+
+// sleep waits for d, and reports false if the request was canceled before.
+func sleep(r *http.Request, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-r.Context().Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
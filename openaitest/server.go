@@ -0,0 +1,247 @@
+// Package openaitest provides a fake of the OpenAI API for the tests of code using the openai package.
+//
+// A Server answers the chat, completions, embeddings, audio, files, batches, assistants, threads and
+// vector stores endpoints with plausible responses, including streamed ones. Tests script the responses
+// of a route with On, and inspect the requests the server received with Requests and RequestsTo.
+//
+//	server := openaitest.NewServer()
+//	defer server.Close()
+//	server.On(http.MethodPost, "/chat/completions").Reply(openaitest.RateLimitError(time.Second))
+//	client := server.Client()
+//
+// A Recorder records the traffic of a client with the real API to a cassette file, and replays it offline.
+package openaitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+)
+
+const (
+	defaultAPIKey = "openaitest"
+	apiPrefix     = "/v1"
+	maxFormMemory = 32 << 20
+)
+
+var ErrNotMultipart = errors.New("request body is not multipart")
+
+// Server is a fake of the OpenAI API running on a local HTTP server. It is safe for concurrent use.
+type Server struct {
+	// URL is the root URL of the server, of the form http://ipaddr:port with no trailing slash.
+	// The API is served under URL + "/v1".
+	URL string
+
+	server   *httptest.Server
+	apiKey   string
+	stateful bool
+	defaults []*route
+
+	mu       sync.Mutex
+	routes   []*Route
+	requests []Request
+	ids      map[string]int
+	state    state
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAPIKey makes the server reject the requests which are not authenticated with key,
+// as a Bearer token or with the api-key header of Azure.
+func WithAPIKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithState makes the server keep the files, assistants, threads, messages, batches and vector stores
+// it creates in memory. Without it, created objects are not kept: getting an object returns a placeholder
+// with the requested ID and lists are empty.
+func WithState() Option {
+	return func(s *Server) {
+		s.stateful = true
+	}
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when finished, to shut it down.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		ids:   map[string]int{},
+		state: newState(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.defaults = s.defaultRoutes()
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on this server have completed.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Config returns a client configuration for the server.
+func (s *Server) Config() openai.ClientConfig {
+	key := s.apiKey
+	if key == "" {
+		key = defaultAPIKey
+	}
+	config := openai.DefaultConfig(key)
+	config.BaseURL = s.URL + apiPrefix
+	return config
+}
+
+// Client returns a client of the server.
+func (s *Server) Client() *openai.Client {
+	return openai.NewClientWithConfig(s.Config())
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	// Path is the path of the request, without the /v1 prefix.
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// DecodeJSON decodes the JSON body of the request into v.
+func (r Request) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// MultipartForm parses the multipart body of the request, such as a file upload.
+func (r Request) MultipartForm() (*multipart.Form, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, ErrNotMultipart
+	}
+	return multipart.NewReader(bytes.NewReader(r.Body), params["boundary"]).ReadForm(maxFormMemory)
+}
+
+// FormValue returns the value of a field of the multipart body of the request, or "".
+func (r Request) FormValue(key string) string {
+	form, err := r.MultipartForm()
+	if err != nil || len(form.Value[key]) == 0 {
+		return ""
+	}
+	return form.Value[key][0]
+}
+
+// Requests returns the requests received by the server, in the order they were received.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received on a route, in the order they were received.
+// An empty method matches every method; path is matched as by On.
+func (s *Server) RequestsTo(method, path string) []Request {
+	var requests []Request
+	for _, request := range s.Requests() {
+		if method != "" && request.Method != method {
+			continue
+		}
+		if _, ok := matchPath(path, request.Path); ok {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// Reset removes the scripted routes and the received requests. The stored objects are kept.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = nil
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := Request{
+		Method: r.Method,
+		Path:   strings.TrimPrefix(r.URL.Path, apiPrefix),
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	s.mu.Unlock()
+
+	if !s.authorized(r) {
+		s.write(w, r, APIError(http.StatusUnauthorized, "invalid_request_error", "Incorrect API key provided."))
+		return
+	}
+
+	scripted, hasScript := s.scriptedResponse(request)
+	if hasScript && !scripted.decoratesDefault() {
+		s.write(w, r, scripted)
+		return
+	}
+	response := s.defaultResponse(request)
+	if hasScript {
+		response = scripted.decorate(response)
+	}
+	s.write(w, r, response)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.apiKey == "" {
+		return true
+	}
+	return r.Header.Get("Authorization") == "Bearer "+s.apiKey || r.Header.Get("api-key") == s.apiKey
+}
+
+// newID returns a new object ID, such as file-3.
+func (s *Server) newID(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[prefix]++
+	return fmt.Sprintf("%s%d", prefix, s.ids[prefix])
+}
+
+// matchPath matches path against pattern, whose segments in braces, such as {file_id}, or * match any segment.
+// The /v1 prefix is optional on both.
+func matchPath(pattern, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(strings.TrimPrefix(pattern, apiPrefix), "/"), "/")
+	pathSegments := strings.Split(strings.Trim(strings.TrimPrefix(path, apiPrefix), "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range patternSegments {
+		switch {
+		case segment == "*":
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			params[strings.Trim(segment, "{}")] = pathSegments[i]
+		case segment != pathSegments[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
@@ -0,0 +1,343 @@
+package openaitest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+	"gitlab.forensix.cn/ai/service/go-openai/openaitest"
+)
+
+func chatRequest(content string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: content}},
+	}
+}
+
+func TestServerDefaultResponses(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	chat, err := client.CreateChatCompletion(ctx, chatRequest("Hello"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if chat.Choices[0].Message.Content != "You said: Hello" || chat.Usage.TotalTokens == 0 {
+		t.Errorf("unexpected chat completion %+v", chat)
+	}
+
+	request := chatRequest("Hello there")
+	request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	stream, err := client.CreateChatCompletionStream(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	var content string
+	var usage *openai.Usage
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		for _, choice := range chunk.Choices {
+			content += choice.Delta.Content
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	if content != "You said: Hello there" || usage == nil {
+		t.Errorf("unexpected stream content %q and usage %+v", content, usage)
+	}
+
+	completion, err := client.CreateCompletion(ctx, openai.CompletionRequest{
+		Model:  "gpt-3.5-turbo-instruct",
+		Prompt: "Hi",
+	})
+	checks.NoError(t, err, "CreateCompletion error")
+	if completion.Choices[0].Text != "You said: Hi" {
+		t.Errorf("unexpected completion %+v", completion)
+	}
+
+	embeddings, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Model: openai.SmallEmbedding3,
+		Input: []string{"a", "b", "a"},
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(embeddings.Data) != 3 || len(embeddings.Data[0].Embedding) != 8 {
+		t.Fatalf("unexpected embeddings %+v", embeddings)
+	}
+	if similarity, _ := embeddings.Data[0].DotProduct(&embeddings.Data[2]); similarity < 0.999 {
+		t.Errorf("equal inputs have different embeddings: %f", similarity)
+	}
+
+	base64Embeddings, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Model:          openai.SmallEmbedding3,
+		Input:          "a",
+		EncodingFormat: openai.EmbeddingEncodingFormatBase64,
+		Dimensions:     4,
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	expected := openaitest.Embedding("a", 4)
+	for i, value := range base64Embeddings.Data[0].Embedding {
+		if value != expected[i] {
+			t.Errorf("unexpected base64 embedding %v, expected %v", base64Embeddings.Data[0].Embedding, expected)
+			break
+		}
+	}
+}
+
+func TestServerAudio(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	transcription, err := client.CreateTranscription(ctx, openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: "meeting.mp3",
+		Reader:   bytes.NewReader([]byte("audio")),
+	})
+	checks.NoError(t, err, "CreateTranscription error")
+	if transcription.Text != "This is a transcription of meeting.mp3." {
+		t.Errorf("unexpected transcription %q", transcription.Text)
+	}
+
+	speech, err := client.CreateSpeech(ctx, openai.CreateSpeechRequest{
+		Model: openai.TTSModel1,
+		Input: "Hello",
+		Voice: openai.VoiceAlloy,
+	})
+	checks.NoError(t, err, "CreateSpeech error")
+	defer speech.Close()
+	audio, err := io.ReadAll(speech)
+	checks.NoError(t, err, "ReadAll error")
+	if string(audio) != "fake audio: Hello" || speech.Header().Get("Content-Type") != "audio/mpeg" {
+		t.Errorf("unexpected speech %q", audio)
+	}
+}
+
+func TestServerScriptedResponses(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	route := server.On(http.MethodPost, "/chat/completions").Reply(
+		openaitest.RateLimitError(2*time.Second),
+		openaitest.Response{Header: http.Header{"X-Request-Id": {"req_1"}}},
+	)
+
+	_, err := client.CreateChatCompletion(ctx, chatRequest("Hello"))
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests ||
+		apiErr.Code != "rate_limit_exceeded" {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		chat, chatErr := client.CreateChatCompletion(ctx, chatRequest("Hello"))
+		checks.NoError(t, chatErr, "CreateChatCompletion error")
+		if chat.Choices[0].Message.Content != "You said: Hello" || chat.Header().Get("X-Request-Id") != "req_1" {
+			t.Errorf("the default response was not decorated: %+v", chat)
+		}
+	}
+	if route.Calls() != 3 {
+		t.Errorf("expected 3 calls, got %d", route.Calls())
+	}
+
+	server.On("", "/models/{model}").ReplyFunc(func(request openaitest.Request) openaitest.Response {
+		return openaitest.Response{
+			Body:      openai.Model{ID: request.Path[len("/models/"):], OwnedBy: "test"},
+			RateLimit: &openai.RateLimitHeaders{LimitRequests: 100, RemainingRequests: 99, ResetRequests: "1s"},
+		}
+	})
+	model, err := client.GetModel(ctx, "gpt-4o")
+	checks.NoError(t, err, "GetModel error")
+	if model.ID != "gpt-4o" || model.GetRateLimitHeaders().RemainingRequests != 99 {
+		t.Errorf("unexpected model %+v", model)
+	}
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/chat/completions")
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 chat requests, got %d", len(requests))
+	}
+	var received openai.ChatCompletionRequest
+	checks.NoError(t, requests[0].DecodeJSON(&received), "DecodeJSON error")
+	if received.Model != openai.GPT4o || received.Messages[0].Content != "Hello" {
+		t.Errorf("unexpected request %+v", received)
+	}
+
+	server.Reset()
+	if len(server.Requests()) != 0 {
+		t.Errorf("requests were not reset")
+	}
+	if _, err = client.CreateChatCompletion(ctx, chatRequest("Hello")); err != nil {
+		t.Errorf("scripted routes were not reset: %v", err)
+	}
+}
+
+func TestServerLatencyAndStreamDrop(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	server.On(http.MethodPost, "/chat/completions").Reply(
+		openaitest.Response{Latency: time.Second},
+		openaitest.Response{
+			Stream: []any{
+				openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+					{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "Hel"}},
+				}},
+				openaitest.StreamDrop{},
+			},
+			StreamInterval: time.Millisecond,
+		},
+	)
+
+	_, err := client.CreateChatCompletion(ctx, chatRequest("Hello"), openai.WithTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	request := chatRequest("Hello")
+	stream, err := client.CreateChatCompletionStream(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	chunk, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if chunk.Choices[0].Delta.Content != "Hel" {
+		t.Errorf("unexpected chunk %+v", chunk)
+	}
+	if _, err = stream.Recv(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("expected the dropped stream to fail, got %v", err)
+	}
+}
+
+func TestServerAPIKey(t *testing.T) {
+	server := openaitest.NewServer(openaitest.WithAPIKey("sk-test"))
+	defer server.Close()
+
+	_, err := server.Client().CreateChatCompletion(context.Background(), chatRequest("Hello"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	config := openai.DefaultConfig("sk-wrong")
+	config.BaseURL = server.URL + "/v1"
+	_, err = openai.NewClientWithConfig(config).CreateChatCompletion(context.Background(), chatRequest("Hello"))
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusUnauthorized {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestServerStateless(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	file, err := client.CreateFileBytes(ctx, openai.FileBytesRequest{
+		Name:    "a.jsonl",
+		Bytes:   []byte("{}"),
+		Purpose: "batch",
+	})
+	checks.NoError(t, err, "CreateFileBytes error")
+	if file.ID != "file-1" || file.Bytes != 2 || file.FileName != "a.jsonl" {
+		t.Errorf("unexpected file %+v", file)
+	}
+	files, err := client.ListFiles(ctx)
+	checks.NoError(t, err, "ListFiles error")
+	if len(files.Files) != 0 {
+		t.Errorf("stateless server kept the file: %+v", files)
+	}
+
+	batch, err := client.RetrieveBatch(ctx, "batch_42")
+	checks.NoError(t, err, "RetrieveBatch error")
+	if batch.ID != "batch_42" || batch.Status != "completed" {
+		t.Errorf("unexpected batch %+v", batch)
+	}
+}
+
+func TestServerState(t *testing.T) {
+	server := openaitest.NewServer(openaitest.WithState())
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	file, err := client.CreateFileBytes(ctx, openai.FileBytesRequest{
+		Name:    "batch.jsonl",
+		Bytes:   []byte(`{"custom_id":"1"}`),
+		Purpose: openai.PurposeBatch,
+	})
+	checks.NoError(t, err, "CreateFileBytes error")
+	_, err = client.CreateFileBytes(ctx, openai.FileBytesRequest{Name: "a.txt", Bytes: []byte("a"), Purpose: "assistants"})
+	checks.NoError(t, err, "CreateFileBytes error")
+
+	files, err := client.ListFiles(ctx, openai.ListFilesWithPurpose(openai.PurposeBatch))
+	checks.NoError(t, err, "ListFiles error")
+	if len(files.Files) != 1 || files.Files[0].ID != file.ID {
+		t.Errorf("unexpected files %+v", files)
+	}
+	var content bytes.Buffer
+	_, err = client.DownloadFile(ctx, file.ID, &content)
+	checks.NoError(t, err, "DownloadFile error")
+	if content.String() != `{"custom_id":"1"}` {
+		t.Errorf("unexpected content %q", content.String())
+	}
+
+	batch, err := client.CreateBatch(ctx, openai.CreateBatchRequest{
+		InputFileID: file.ID,
+		Endpoint:    openai.BatchEndpointChatCompletions,
+	})
+	checks.NoError(t, err, "CreateBatch error")
+	cancelled, err := client.CancelBatch(ctx, batch.ID)
+	checks.NoError(t, err, "CancelBatch error")
+	retrieved, err := client.RetrieveBatch(ctx, batch.ID)
+	checks.NoError(t, err, "RetrieveBatch error")
+	if cancelled.Status != "cancelling" || retrieved.Status != "cancelling" || retrieved.InputFileID != file.ID {
+		t.Errorf("unexpected batch %+v", retrieved)
+	}
+
+	thread, err := client.CreateThread(ctx, openai.ThreadRequest{Messages: []openai.ThreadMessage{
+		{Role: openai.ThreadMessageRoleUser, Content: "Hello"},
+	}})
+	checks.NoError(t, err, "CreateThread error")
+	_, err = client.CreateMessage(ctx, thread.ID, openai.MessageRequest{Role: "user", Content: "Again"})
+	checks.NoError(t, err, "CreateMessage error")
+	messages, err := client.ListMessage(ctx, thread.ID, nil, nil, nil, nil, nil)
+	checks.NoError(t, err, "ListMessage error")
+	if len(messages.Messages) != 2 || messages.Messages[1].Content[0].Text.Value != "Again" ||
+		messages.Messages[0].ThreadID != thread.ID {
+		t.Errorf("unexpected messages %+v", messages)
+	}
+
+	store, err := client.CreateVectorStore(ctx, openai.VectorStoreRequest{Name: "docs", FileIDs: []string{file.ID}})
+	checks.NoError(t, err, "CreateVectorStore error")
+	storeFiles, err := client.ListVectorStoreFiles(ctx, store.ID, openai.Pagination{})
+	checks.NoError(t, err, "ListVectorStoreFiles error")
+	if store.FileCounts.Total != 1 || len(storeFiles.VectorStoreFiles) != 1 ||
+		storeFiles.VectorStoreFiles[0].ID != file.ID {
+		t.Errorf("unexpected vector store %+v with files %+v", store, storeFiles)
+	}
+
+	_, err = client.DeleteVectorStore(ctx, store.ID)
+	checks.NoError(t, err, "DeleteVectorStore error")
+	_, err = client.RetrieveVectorStore(ctx, store.ID)
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	checks.NoError(t, client.DeleteFile(ctx, file.ID), "DeleteFile error")
+	if _, err = client.GetFileContent(ctx, file.ID); !errors.As(err, &apiErr) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
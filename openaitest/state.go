@@ -0,0 +1,257 @@
+package openaitest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// state holds the objects created in stateful mode, as JSON objects keyed by the path of their list.
+type state struct {
+	lists    map[string]*objectList
+	contents map[string][]byte
+}
+
+type objectList struct {
+	ids     []string
+	objects map[string]map[string]any
+}
+
+func newState() state {
+	return state{lists: map[string]*objectList{}, contents: map[string][]byte{}}
+}
+
+// collection is a kind of object served with the usual create, list, get, modify and delete endpoints.
+type collection struct {
+	// path is the pattern of the path of the list, such as /threads/{thread_id}/messages.
+	path   string
+	object string
+	prefix string
+	// placeholder holds the fields of the object returned for an unknown ID when the server is stateless.
+	placeholder map[string]any
+	// filters are the query parameters which filter the list by the field of the same name.
+	filters []string
+	// prepare completes a created object.
+	prepare func(object map[string]any, params map[string]string)
+}
+
+func (s *Server) collectionRoutes(c collection) []*route {
+	item := c.path + "/{id}"
+	return []*route{
+		{http.MethodPost, c.path, s.createObject(c)},
+		{http.MethodGet, c.path, s.listObjects(c)},
+		{http.MethodGet, item, s.getObject(c)},
+		{http.MethodPost, item, s.modifyObject(c)},
+		{http.MethodDelete, item, s.deleteObject(c)},
+	}
+}
+
+func (s *Server) createObject(c collection) handler {
+	return func(request Request, params map[string]string) Response {
+		object := map[string]any{}
+		if len(request.Body) > 0 {
+			if err := request.DecodeJSON(&object); err != nil {
+				return invalidRequest(err.Error())
+			}
+		}
+		s.newObject(c, object, params)
+		s.store(request.Path, object)
+		return Response{Body: object}
+	}
+}
+
+// newObject sets the ID and the common fields of a created object.
+func (s *Server) newObject(c collection, object map[string]any, params map[string]string) {
+	object["id"] = s.newID(c.prefix)
+	object["object"] = c.object
+	object["created_at"] = time.Now().Unix()
+	if c.prepare != nil {
+		c.prepare(object, params)
+	}
+}
+
+func (s *Server) listObjects(c collection) handler {
+	return func(request Request, _ map[string]string) Response {
+		var objects []map[string]any
+		for _, object := range s.list(request.Path) {
+			matches := true
+			for _, filter := range c.filters {
+				if value := request.Query.Get(filter); value != "" && fmt.Sprint(object[filter]) != value {
+					matches = false
+				}
+			}
+			if matches {
+				objects = append(objects, object)
+			}
+		}
+		return Response{Body: listBody(objects, request)}
+	}
+}
+
+func (s *Server) getObject(c collection) handler {
+	return func(request Request, params map[string]string) Response {
+		object, ok := s.objectOrPlaceholder(c, request.Path, params["id"])
+		if !ok {
+			return notFound(c.object, params["id"])
+		}
+		return Response{Body: object}
+	}
+}
+
+func (s *Server) modifyObject(c collection) handler {
+	return func(request Request, params map[string]string) Response {
+		object, ok := s.objectOrPlaceholder(c, request.Path, params["id"])
+		if !ok {
+			return notFound(c.object, params["id"])
+		}
+		var fields map[string]any
+		if err := request.DecodeJSON(&fields); err != nil {
+			return invalidRequest(err.Error())
+		}
+
+		modified := make(map[string]any, len(object)+len(fields))
+		for key, value := range object {
+			modified[key] = value
+		}
+		for key, value := range fields {
+			modified[key] = value
+		}
+		s.store(parentPath(request.Path), modified)
+		return Response{Body: modified}
+	}
+}
+
+func (s *Server) deleteObject(c collection) handler {
+	return func(request Request, params map[string]string) Response {
+		if s.stateful && !s.remove(parentPath(request.Path), params["id"]) {
+			return notFound(c.object, params["id"])
+		}
+		return Response{Body: map[string]any{"id": params["id"], "object": c.object + ".deleted", "deleted": true}}
+	}
+}
+
+// objectOrPlaceholder returns the stored object with the given ID at the path of an item,
+// or a placeholder when the server is stateless.
+func (s *Server) objectOrPlaceholder(c collection, path, id string) (map[string]any, bool) {
+	if s.stateful {
+		return s.load(parentPath(path), id)
+	}
+	object := map[string]any{"id": id, "object": c.object, "created_at": time.Now().Unix()}
+	for key, value := range c.placeholder {
+		object[key] = value
+	}
+	return object, true
+}
+
+// store saves object in the list at path. Stored objects are never modified, but replaced.
+func (s *Server) store(path string, object map[string]any) {
+	if !s.stateful {
+		return
+	}
+	path = strings.TrimSuffix(path, "/")
+	id, _ := object["id"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.state.lists[path]
+	if list == nil {
+		list = &objectList{objects: map[string]map[string]any{}}
+		s.state.lists[path] = list
+	}
+	if _, ok := list.objects[id]; !ok {
+		list.ids = append(list.ids, id)
+	}
+	list.objects[id] = object
+}
+
+func (s *Server) load(path, id string) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.state.lists[strings.TrimSuffix(path, "/")]
+	if list == nil {
+		return nil, false
+	}
+	object, ok := list.objects[id]
+	return object, ok
+}
+
+func (s *Server) remove(path, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.state.lists[strings.TrimSuffix(path, "/")]
+	if list == nil || list.objects[id] == nil {
+		return false
+	}
+	delete(list.objects, id)
+	for i := range list.ids {
+		if list.ids[i] == id {
+			list.ids = append(list.ids[:i], list.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// list returns the objects of the list at path, oldest first.
+func (s *Server) list(path string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.state.lists[strings.TrimSuffix(path, "/")]
+	if list == nil {
+		return nil
+	}
+	objects := make([]map[string]any, 0, len(list.ids))
+	for _, id := range list.ids {
+		objects = append(objects, list.objects[id])
+	}
+	return objects
+}
+
+// listBody pages objects with the order, after and limit query parameters of request.
+func listBody(objects []map[string]any, request Request) map[string]any {
+	if request.Query.Get("order") == "desc" {
+		reversed := make([]map[string]any, len(objects))
+		for i, object := range objects {
+			reversed[len(objects)-1-i] = object
+		}
+		objects = reversed
+	}
+	if after := request.Query.Get("after"); after != "" {
+		for i, object := range objects {
+			if object["id"] == after {
+				objects = objects[i+1:]
+				break
+			}
+		}
+	}
+	hasMore := false
+	if limit, err := strconv.Atoi(request.Query.Get("limit")); err == nil && limit > 0 && limit < len(objects) {
+		objects = objects[:limit]
+		hasMore = true
+	}
+
+	body := map[string]any{"object": "list", "data": objects, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if objects == nil {
+		body["data"] = []map[string]any{}
+	}
+	if len(objects) > 0 {
+		body["first_id"] = objects[0]["id"]
+		body["last_id"] = objects[len(objects)-1]["id"]
+	}
+	return body
+}
+
+func parentPath(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	return path[:strings.LastIndex(path, "/")]
+}
+
+func notFound(object, id string) Response {
+	return APIError(http.StatusNotFound, "invalid_request_error", fmt.Sprintf("No %s found with id '%s'.", object, id))
+}
+
+func invalidRequest(message string) Response {
+	return APIError(http.StatusBadRequest, "invalid_request_error", message)
+}
@@ -0,0 +1,159 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestRequestOptionsHeaderAndQuery(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.OrgID = "org-client"
+	client := openai.NewClientWithConfig(config)
+	server.RegisterHandler("/v1/models/gpt-4o", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Idempotency-Key") != "key-1" || r.Header.Get("X-Trace") != "trace-1" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if r.Header.Get("OpenAI-Organization") != "org-call" {
+			t.Errorf("option header did not replace the client header: %s", r.Header.Get("OpenAI-Organization"))
+		}
+		if r.URL.Query().Get("api-version") != "2024-10-21" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"id":"gpt-4o","object":"model"}`)
+	})
+
+	model, err := client.GetModel(context.Background(), "gpt-4o",
+		openai.WithIdempotencyKey("key-1"),
+		openai.WithHeader("X-Trace", "trace-1"),
+		openai.WithHeader("OpenAI-Organization", "org-call"),
+		openai.WithQueryParam("api-version", "2024-10-21"),
+	)
+	checks.NoError(t, err, "GetModel error")
+	if model.ID != "gpt-4o" {
+		t.Errorf("unexpected model %+v", model)
+	}
+}
+
+func TestRequestOptionsExtraBody(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var body map[string]any
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "Decode error")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion"}`)
+	})
+
+	request := openai.ChatCompletionRequest{
+		Model:     openai.GPT4o,
+		Messages:  []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		ExtraBody: map[string]any{"enable_thinking": false},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request,
+		openai.WithExtraBody(map[string]any{"top_k": 20, "model": "qwen3"}))
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if _, ok := body["extra_body"]; ok {
+		t.Errorf("extra body was not merged: %v", body)
+	}
+	if body["enable_thinking"] != false || body["top_k"] != float64(20) || body["model"] != "qwen3" {
+		t.Errorf("unexpected body %v", body)
+	}
+	if _, ok := body["messages"]; !ok {
+		t.Errorf("body lost the request fields: %v", body)
+	}
+}
+
+func TestRequestOptionsExtraBodyMultipart(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	_, err := client.CreateFileBytes(context.Background(), openai.FileBytesRequest{
+		Name:    "batch.jsonl",
+		Bytes:   []byte("{}"),
+		Purpose: openai.PurposeBatch,
+	}, openai.WithExtraBody(map[string]any{"foo": "bar"}))
+	if !errors.Is(err, openai.ErrExtraBodyNotJSON) {
+		t.Fatalf("expected ErrExtraBodyNotJSON, got %v", err)
+	}
+}
+
+func TestRequestOptionsBaseURL(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/models/gpt-4o", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("request was sent to the client base URL")
+		fmt.Fprint(w, `{"id":"gpt-4o"}`)
+	})
+
+	other := test.NewTestServer()
+	ts := other.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	other.RegisterHandler("/proxy/v1/models/gpt-4o", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"gpt-4o","owned_by":"proxy"}`)
+	})
+
+	model, err := client.GetModel(context.Background(), "gpt-4o", openai.WithBaseURL(ts.URL+"/proxy/v1/"))
+	checks.NoError(t, err, "GetModel error")
+	if model.OwnedBy != "proxy" {
+		t.Errorf("unexpected model %+v", model)
+	}
+}
+
+func TestRequestOptionsTimeout(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/models/gpt-4o", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		fmt.Fprint(w, `{"id":"gpt-4o"}`)
+	})
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"object":"list","data":[]}`)
+	})
+
+	_, err := client.GetModel(context.Background(), "gpt-4o", openai.WithTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	_, err = client.ListModels(context.Background(), openai.WithTimeout(time.Second))
+	checks.NoError(t, err, "ListModels error")
+}
+
+func TestRequestOptionsListParameter(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace") != "trace-1" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if r.URL.Query().Get("purpose") != "batch" || r.URL.Query().Get("extra") != "1" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[]}`)
+	})
+
+	_, err := client.ListFiles(context.Background(),
+		openai.ListFilesWithPurpose(openai.PurposeBatch),
+		openai.ListFilesWithRequestOptions(openai.WithHeader("X-Trace", "trace-1"), openai.WithQueryParam("extra", "1")),
+	)
+	checks.NoError(t, err, "ListFiles error")
+}
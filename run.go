@@ -236,6 +236,7 @@ func (c *Client) CreateRun(
 	ctx context.Context,
 	threadID string,
 	request RunRequest,
+	opts ...RequestOption,
 ) (response Run, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/runs", threadID)
 	req, err := c.newRequest(
@@ -243,7 +244,7 @@ func (c *Client) CreateRun(
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -257,13 +258,14 @@ func (c *Client) RetrieveRun(
 	ctx context.Context,
 	threadID string,
 	runID string,
+	opts ...RequestOption,
 ) (response Run, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s", threadID, runID)
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -278,6 +280,7 @@ func (c *Client) ModifyRun(
 	threadID string,
 	runID string,
 	request RunModifyRequest,
+	opts ...RequestOption,
 ) (response Run, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s", threadID, runID)
 	req, err := c.newRequest(
@@ -285,7 +288,7 @@ func (c *Client) ModifyRun(
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -299,6 +302,7 @@ func (c *Client) ListRuns(
 	ctx context.Context,
 	threadID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response RunList, err error) {
 	urlValues := url.Values{}
 	if pagination.Limit != nil {
@@ -324,7 +328,7 @@ func (c *Client) ListRuns(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -338,14 +342,14 @@ func (c *Client) SubmitToolOutputs(
 	ctx context.Context,
 	threadID string,
 	runID string,
-	request SubmitToolOutputsRequest) (response Run, err error) {
+	request SubmitToolOutputsRequest, opts ...RequestOption) (response Run, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/submit_tool_outputs", threadID, runID)
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -358,13 +362,13 @@ func (c *Client) SubmitToolOutputs(
 func (c *Client) CancelRun(
 	ctx context.Context,
 	threadID string,
-	runID string) (response Run, err error) {
+	runID string, opts ...RequestOption) (response Run, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/cancel", threadID, runID)
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -376,14 +380,14 @@ func (c *Client) CancelRun(
 // CreateThreadAndRun submits tool outputs.
 func (c *Client) CreateThreadAndRun(
 	ctx context.Context,
-	request CreateThreadAndRunRequest) (response Run, err error) {
+	request CreateThreadAndRunRequest, opts ...RequestOption) (response Run, err error) {
 	urlSuffix := "/threads/runs"
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -398,13 +402,14 @@ func (c *Client) RetrieveRunStep(
 	threadID string,
 	runID string,
 	stepID string,
+	opts ...RequestOption,
 ) (response RunStep, err error) {
 	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps/%s", threadID, runID, stepID)
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -419,6 +424,7 @@ func (c *Client) ListRunSteps(
 	threadID string,
 	runID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response RunStepList, err error) {
 	urlValues := url.Values{}
 	if pagination.Limit != nil {
@@ -444,7 +450,7 @@ func (c *Client) ListRunSteps(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 	if err != nil {
 		return
 	}
@@ -40,6 +40,17 @@ const (
 	SpeechResponseFormatPcm  SpeechResponseFormat = "pcm"
 )
 
+// SpeechStreamFormat is how the audio of a speech is streamed.
+type SpeechStreamFormat string
+
+const (
+	// SpeechStreamFormatAudio streams the raw audio bytes, the default.
+	SpeechStreamFormatAudio SpeechStreamFormat = "audio"
+	// SpeechStreamFormatSSE streams the audio base64 encoded in server-sent events.
+	// It is not supported by tts-1 and tts-1-hd.
+	SpeechStreamFormatSSE SpeechStreamFormat = "sse"
+)
+
 type CreateSpeechRequest struct {
 	Model          SpeechModel          `json:"model"`
 	Input          string               `json:"input"`
@@ -47,15 +58,21 @@ type CreateSpeechRequest struct {
 	Instructions   string               `json:"instructions,omitempty"`    // Optional, Doesnt work with tts-1 or tts-1-hd.
 	ResponseFormat SpeechResponseFormat `json:"response_format,omitempty"` // Optional, default to mp3
 	Speed          float64              `json:"speed,omitempty"`           // Optional, default to 1.0
+	StreamFormat   SpeechStreamFormat   `json:"stream_format,omitempty"`   // Optional, default to audio
 }
 
-func (c *Client) CreateSpeech(ctx context.Context, request CreateSpeechRequest) (response RawResponse, err error) {
+func (c *Client) CreateSpeech(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	opts ...RequestOption,
+) (response RawResponse, err error) {
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL("/audio/speech", withModel(string(request.Model))),
 		withBody(request),
 		withContentType("application/json"),
+		withOptions(opts),
 	)
 	if err != nil {
 		return
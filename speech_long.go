@@ -0,0 +1,341 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// SpeechMaxInputLength is the largest number of characters of the input of a speech.
+	SpeechMaxInputLength = 4096
+
+	defaultLongSpeechConcurrency = 4
+)
+
+var (
+	ErrLongSpeechUnsupportedFormat = errors.New("long speech can only be generated as mp3, wav or pcm")
+	ErrLongSpeechEmptyInput        = errors.New("long speech input is empty")
+)
+
+// LongSpeechRequest is a speech of text which may exceed SpeechMaxInputLength.
+//
+// The input is split on sentence boundaries into chunks which are synthesized concurrently
+// with the same voice, instructions and speed, and the audio is concatenated in order.
+type LongSpeechRequest struct {
+	CreateSpeechRequest
+
+	// MaxChunkLength is the largest number of characters of a chunk, SpeechMaxInputLength by default.
+	MaxChunkLength int
+	// Concurrency is the number of chunks synthesized in parallel, 4 by default.
+	Concurrency int
+}
+
+func (r LongSpeechRequest) withDefaults() LongSpeechRequest {
+	if r.MaxChunkLength <= 0 || r.MaxChunkLength > SpeechMaxInputLength {
+		r.MaxChunkLength = SpeechMaxInputLength
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = defaultLongSpeechConcurrency
+	}
+	if r.ResponseFormat == "" {
+		r.ResponseFormat = SpeechResponseFormatMp3
+	}
+	return r
+}
+
+// SpeechChunk is a part of the input of a long speech and its position in the audio.
+type SpeechChunk struct {
+	// Text is the part of the input, starting at the byte TextOffset of the input.
+	Text       string
+	TextOffset int
+	// Start is the time at which the chunk starts in the audio.
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// LongSpeechResult is the outcome of CreateLongSpeech.
+type LongSpeechResult struct {
+	Chunks   []SpeechChunk
+	Duration time.Duration
+	// Written is the number of bytes written.
+	Written int64
+}
+
+// CreateLongSpeech synthesizes text of any length and writes the audio to w in order,
+// each chunk as soon as it and the chunks before it are synthesized.
+//
+// The audio is concatenated losslessly: pcm samples are joined as they are, wav output
+// is a single WAV file written through a WAVWriter, and mp3 output is the audio frames of
+// every chunk without their tags and VBR headers.
+func (c *Client) CreateLongSpeech(
+	ctx context.Context,
+	request LongSpeechRequest,
+	w io.Writer,
+	opts ...RequestOption,
+) (result LongSpeechResult, err error) {
+	request = request.withDefaults()
+	joiner, err := newSpeechJoiner(request.ResponseFormat, w)
+	if err != nil {
+		return
+	}
+
+	chunks := SplitSpeechInput(request.Input, request.MaxChunkLength)
+	if len(chunks) == 0 {
+		err = ErrLongSpeechEmptyInput
+		return
+	}
+
+	type synthesis struct {
+		audio []byte
+		err   error
+		done  chan struct{}
+	}
+	syntheses := make([]synthesis, len(chunks))
+	for i := range syntheses {
+		syntheses[i].done = make(chan struct{})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	pending := make(chan int, len(chunks))
+	for i := range chunks {
+		pending <- i
+	}
+	close(pending)
+
+	for n := 0; n < request.Concurrency && n < len(chunks); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				chunkRequest := request.CreateSpeechRequest
+				chunkRequest.Input = chunks[i].Text
+				chunkRequest.StreamFormat = ""
+				syntheses[i].audio, syntheses[i].err = c.synthesizeSpeech(ctx, chunkRequest, opts...)
+				close(syntheses[i].done)
+			}
+		}()
+	}
+
+	for i := range chunks {
+		select {
+		case <-syntheses[i].done:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		if err = syntheses[i].err; err != nil {
+			err = fmt.Errorf("synthesizing chunk %d: %w", i+1, err)
+			return
+		}
+
+		chunks[i].Start = result.Duration
+		chunks[i].Duration, err = joiner.write(syntheses[i].audio)
+		if err != nil {
+			err = fmt.Errorf("joining chunk %d: %w", i+1, err)
+			return
+		}
+		syntheses[i].audio = nil
+		result.Duration += chunks[i].Duration
+	}
+
+	result.Chunks = chunks
+	err = joiner.close()
+	result.Written = joiner.written
+	return
+}
+
+func (c *Client) synthesizeSpeech(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	opts ...RequestOption,
+) ([]byte, error) {
+	response, err := c.CreateSpeech(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Close()
+	return io.ReadAll(response)
+}
+
+// speechJoiner concatenates the audio of consecutive chunks.
+type speechJoiner struct {
+	format  SpeechResponseFormat
+	w       io.Writer
+	wav     *WAVWriter
+	written int64
+}
+
+func newSpeechJoiner(format SpeechResponseFormat, w io.Writer) (*speechJoiner, error) {
+	switch format {
+	case SpeechResponseFormatPcm, SpeechResponseFormatMp3, SpeechResponseFormatWav:
+		return &speechJoiner{format: format, w: w}, nil
+	}
+	return nil, ErrLongSpeechUnsupportedFormat
+}
+
+// write appends the audio of a chunk and returns its duration.
+func (j *speechJoiner) write(audio []byte) (time.Duration, error) {
+	switch j.format {
+	case SpeechResponseFormatPcm:
+		return durationOf(SpeechPCMFormat, int64(len(audio))), j.writeBytes(audio)
+	case SpeechResponseFormatWav:
+		format, offset, size, err := readWAVHeader(bytes.NewReader(audio), int64(len(audio)))
+		if err != nil {
+			return 0, err
+		}
+		if j.wav == nil {
+			j.wav = NewWAVWriter(j.w, format)
+		} else if j.wav.format != format {
+			return 0, fmt.Errorf("%w: chunks have different formats", ErrWAVInvalid)
+		}
+		n, err := j.wav.Write(audio[offset : offset+size])
+		j.written += int64(n)
+		return durationOf(format, size), err
+	default:
+		frames, err := mp3AudioFrames(audio)
+		if err != nil {
+			return 0, err
+		}
+		for _, frame := range frames {
+			if err = j.writeBytes(audio[frame.offset : frame.offset+frame.length]); err != nil {
+				return 0, err
+			}
+		}
+		return mp3FramesDuration(frames), nil
+	}
+}
+
+func (j *speechJoiner) writeBytes(p []byte) error {
+	n, err := j.w.Write(p)
+	j.written += int64(n)
+	return err
+}
+
+func (j *speechJoiner) close() error {
+	if j.wav == nil {
+		return nil
+	}
+	err := j.wav.Close()
+	j.written += wavHeaderSize
+	return err
+}
+
+func durationOf(format PCMFormat, size int64) time.Duration {
+	return time.Duration(size * int64(time.Second) / format.byteRate())
+}
+
+// SplitSpeechInput splits text into chunks of at most maxLength characters, on sentence
+// boundaries where possible, then on clause boundaries and spaces. Whitespace between
+// chunks is dropped and every chunk records its byte offset in text.
+func SplitSpeechInput(text string, maxLength int) []SpeechChunk {
+	var (
+		chunks []SpeechChunk
+		start  = -1
+		end    int
+	)
+	flush := func() {
+		if start >= 0 {
+			chunks = append(chunks, SpeechChunk{Text: text[start:end], TextOffset: start})
+		}
+		start = -1
+	}
+
+	for _, sentence := range splitSpeechUnits(text, 0, len(text), maxLength, 0) {
+		if start >= 0 && utf8.RuneCountInString(text[start:sentence[1]]) > maxLength {
+			flush()
+		}
+		if start < 0 {
+			start = sentence[0]
+		}
+		end = sentence[1]
+	}
+	flush()
+	return chunks
+}
+
+// speechBoundaries are the separators tried in turn to split text which is too long.
+var speechBoundaries = []func(text string, i int, r rune) bool{
+	isSentenceEnd,
+	func(_ string, _ int, r rune) bool { return strings.ContainsRune(",;:，；：、", r) },
+	func(_ string, _ int, r rune) bool { return unicode.IsSpace(r) },
+}
+
+// splitSpeechUnits returns the byte ranges of the parts of text[start:end] separated by the
+// boundaries of speechBoundaries[level], with surrounding whitespace trimmed.
+// Parts longer than maxLength are split on the next kind of boundary.
+func splitSpeechUnits(text string, start, end, maxLength, level int) [][2]int {
+	var units [][2]int
+	unitStart := start
+	add := func(from, to int) {
+		segment := text[from:to]
+		trimmed := strings.TrimLeftFunc(segment, unicode.IsSpace)
+		from += len(segment) - len(trimmed)
+		to = from + len(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+		if from >= to {
+			return
+		}
+		if utf8.RuneCountInString(text[from:to]) <= maxLength {
+			units = append(units, [2]int{from, to})
+			return
+		}
+		if level+1 < len(speechBoundaries) {
+			units = append(units, splitSpeechUnits(text, from, to, maxLength, level+1)...)
+			return
+		}
+		units = append(units, hardSplit(text, from, to, maxLength)...)
+	}
+
+	for i, r := range text[start:end] {
+		if speechBoundaries[level](text, start+i, r) {
+			next := start + i + utf8.RuneLen(r)
+			add(unitStart, next)
+			unitStart = next
+		}
+	}
+	add(unitStart, end)
+	return units
+}
+
+// isSentenceEnd reports whether the rune at i ends a sentence: terminal punctuation
+// followed by whitespace or the end of the text, or a line break.
+func isSentenceEnd(text string, i int, r rune) bool {
+	switch r {
+	case '\n', '。', '！', '？':
+		return true
+	case '.', '!', '?', '…':
+		next := i + utf8.RuneLen(r)
+		if next >= len(text) {
+			return true
+		}
+		following, _ := utf8.DecodeRuneInString(text[next:])
+		return unicode.IsSpace(following)
+	}
+	return false
+}
+
+// hardSplit cuts text[start:end] every maxLength characters.
+func hardSplit(text string, start, end, maxLength int) [][2]int {
+	var units [][2]int
+	count, from := 0, start
+	for i := range text[start:end] {
+		if count == maxLength {
+			units = append(units, [2]int{from, start + i})
+			from, count = start+i, 0
+		}
+		count++
+	}
+	return append(units, [2]int{from, end})
+}
@@ -0,0 +1,204 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestSplitSpeechInput(t *testing.T) {
+	text := "First sentence. Second one! Is it third? " +
+		"A much longer sentence, with clauses; which must be split.\n" +
+		"Supercalifragilisticexpialidocious"
+	chunks := openai.SplitSpeechInput(text, 30)
+
+	expected := []string{
+		"First sentence. Second one!",
+		"Is it third?",
+		"A much longer sentence,",
+		"with clauses;",
+		"which must be split.",
+		"Supercalifragilisticexpialidoc",
+		"ious",
+	}
+	if len(chunks) != len(expected) {
+		t.Fatalf("unexpected chunks %+v", chunks)
+	}
+	for i, chunk := range chunks {
+		if chunk.Text != expected[i] || text[chunk.TextOffset:chunk.TextOffset+len(chunk.Text)] != chunk.Text {
+			t.Errorf("unexpected chunk %d: %+v", i, chunk)
+		}
+		if utf8.RuneCountInString(chunk.Text) > 30 {
+			t.Errorf("chunk %d is too long", i)
+		}
+	}
+
+	if chunks = openai.SplitSpeechInput("3.14 is pi. 你好。世界！", 100); len(chunks) != 1 {
+		t.Errorf("short text should not be split: %+v", chunks)
+	}
+	if chunks = openai.SplitSpeechInput(" \n ", 100); len(chunks) != 0 {
+		t.Errorf("blank text should have no chunks: %+v", chunks)
+	}
+}
+
+// registerSpeechChunks answers every speech with audio built by synthesize from the input.
+func registerSpeechChunks(t *testing.T, server *test.ServerTest, synthesize func(input string) []byte) {
+	t.Helper()
+	server.RegisterHandler("/v1/audio/speech", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.CreateSpeechRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.Voice != openai.VoiceNova || request.Instructions != "Calm" {
+			t.Errorf("voice and instructions must be sent with every chunk: %+v", request)
+		}
+		// Later chunks are answered first.
+		if strings.HasPrefix(request.Input, "One") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		_, _ = w.Write(synthesize(request.Input))
+	})
+}
+
+func TestCreateLongSpeechPCM(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	// 2400 bytes of samples, i.e. 50ms, per character
+	registerSpeechChunks(t, server, func(input string) []byte {
+		return bytes.Repeat([]byte(input[:1]), 2400*len(input))
+	})
+
+	var out bytes.Buffer
+	result, err := client.CreateLongSpeech(context.Background(), openai.LongSpeechRequest{
+		CreateSpeechRequest: openai.CreateSpeechRequest{
+			Model:          openai.TTSModelGPT4oMini,
+			Input:          "One. Two. Three.",
+			Voice:          openai.VoiceNova,
+			Instructions:   "Calm",
+			ResponseFormat: openai.SpeechResponseFormatPcm,
+		},
+		MaxChunkLength: 6,
+	}, &out)
+	checks.NoError(t, err, "CreateLongSpeech error")
+
+	if len(result.Chunks) != 3 || result.Written != int64(out.Len()) || result.Duration != 700*time.Millisecond {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Chunks[1].Start != 200*time.Millisecond || result.Chunks[2].Start != 400*time.Millisecond ||
+		result.Chunks[2].TextOffset != 10 {
+		t.Errorf("unexpected chunks %+v", result.Chunks)
+	}
+	if out.Bytes()[0] != 'O' || out.Bytes()[2400*4] != 'T' || out.Bytes()[2400*8] != 'T' {
+		t.Error("chunks were not written in order")
+	}
+}
+
+func TestCreateLongSpeechWAV(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	registerSpeechChunks(t, server, func(input string) []byte {
+		samples := bytes.Repeat([]byte{1, 0}, 24*len(input))
+		return append(openai.SpeechPCMFormat.WAVHeader(int64(len(samples))), samples...)
+	})
+
+	path := filepath.Join(t.TempDir(), "book.wav")
+	f, err := os.Create(path)
+	checks.NoError(t, err, "Create error")
+	defer f.Close()
+
+	result, err := client.CreateLongSpeech(context.Background(), openai.LongSpeechRequest{
+		CreateSpeechRequest: openai.CreateSpeechRequest{
+			Input:          "One. Two.",
+			Voice:          openai.VoiceNova,
+			Instructions:   "Calm",
+			ResponseFormat: openai.SpeechResponseFormatWav,
+		},
+		MaxChunkLength: 5,
+	}, f)
+	checks.NoError(t, err, "CreateLongSpeech error")
+
+	content, err := os.ReadFile(path)
+	checks.NoError(t, err, "ReadFile error")
+	if len(content) != 44+2*48*4 || result.Written != int64(len(content)) {
+		t.Fatalf("unexpected WAV file of %d bytes, result %+v", len(content), result)
+	}
+	if binary.LittleEndian.Uint32(content[40:]) != 2*48*4 || result.Chunks[1].Start != 4*time.Millisecond {
+		t.Errorf("unexpected WAV header %v or chunks %+v", content[:44], result.Chunks)
+	}
+}
+
+// testMP3Frame is an MPEG-2 layer III frame of 64kbps at 24kHz: 576 samples in 192 bytes.
+func testMP3Frame(fill byte) []byte {
+	frame := bytes.Repeat([]byte{fill}, 192)
+	copy(frame, []byte{0xFF, 0xF3, 0x84, 0xC4})
+	return frame
+}
+
+func TestCreateLongSpeechMP3(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	registerSpeechChunks(t, server, func(input string) []byte {
+		var mp3 bytes.Buffer
+		mp3.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 3, 'a', 'b', 'c'})
+		xing := testMP3Frame(0)
+		copy(xing[13:], "Xing")
+		mp3.Write(xing)
+		for i := 0; i < len(input); i++ {
+			mp3.Write(testMP3Frame(input[0]))
+		}
+		mp3.WriteString("TAG")
+		return mp3.Bytes()
+	})
+
+	var out bytes.Buffer
+	result, err := client.CreateLongSpeech(context.Background(), openai.LongSpeechRequest{
+		CreateSpeechRequest: openai.CreateSpeechRequest{
+			Input:        "One. Three.",
+			Voice:        openai.VoiceNova,
+			Instructions: "Calm",
+		},
+		MaxChunkLength: 6,
+		Concurrency:    2,
+	}, &out)
+	checks.NoError(t, err, "CreateLongSpeech error")
+
+	// 4 + 6 frames of 24ms, without tags or VBR headers
+	if out.Len() != 10*192 || result.Duration != 240*time.Millisecond || result.Chunks[1].Start != 96*time.Millisecond {
+		t.Fatalf("unexpected output of %d bytes and result %+v", out.Len(), result)
+	}
+	if out.Bytes()[4] != 'O' || out.Bytes()[4*192+4] != 'T' {
+		t.Error("unexpected frames")
+	}
+}
+
+func TestCreateLongSpeechErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/speech", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("not audio"))
+	})
+	ctx := context.Background()
+
+	_, err := client.CreateLongSpeech(ctx, openai.LongSpeechRequest{
+		CreateSpeechRequest: openai.CreateSpeechRequest{Input: "Hi.", ResponseFormat: openai.SpeechResponseFormatOpus},
+	}, &bytes.Buffer{})
+	checks.ErrorIs(t, err, openai.ErrLongSpeechUnsupportedFormat, "opus cannot be joined")
+
+	_, err = client.CreateLongSpeech(ctx, openai.LongSpeechRequest{}, &bytes.Buffer{})
+	checks.ErrorIs(t, err, openai.ErrLongSpeechEmptyInput, "empty input")
+
+	_, err = client.CreateLongSpeech(ctx, openai.LongSpeechRequest{
+		CreateSpeechRequest: openai.CreateSpeechRequest{Input: "Hi."},
+	}, &bytes.Buffer{})
+	checks.ErrorIs(t, err, openai.ErrMP3Invalid, "invalid audio")
+}
@@ -0,0 +1,164 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Speech stream event types.
+const (
+	SpeechStreamEventAudioDelta = "speech.audio.delta"
+	SpeechStreamEventAudioDone  = "speech.audio.done"
+)
+
+var ErrSpeechStreamUnsupportedModel = errors.New("server-sent events speech streaming is not supported by tts-1 and tts-1-hd") //nolint:lll
+
+// SpeechPCMFormat is the format of speech generated with SpeechResponseFormatPcm:
+// 24kHz mono 16-bit signed little-endian samples.
+var SpeechPCMFormat = PCMFormat{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+
+// SpeechUsage is the token usage of a speech generation.
+type SpeechUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// SpeechStreamEvent is an event of a speech streamed as server-sent events.
+// Delta events carry the next piece of audio and the done event the usage.
+type SpeechStreamEvent struct {
+	Type  string       `json:"type"`
+	Audio string       `json:"audio,omitempty"`
+	Usage *SpeechUsage `json:"usage,omitempty"`
+
+	// Data holds the decoded bytes of Audio, in the response format of the request.
+	Data []byte `json:"-"`
+}
+
+// SpeechStream is a stream of speech events. Recv returns io.EOF after the done event.
+type SpeechStream struct {
+	*streamReader[SpeechStreamEvent]
+}
+
+// Recv returns the next event with its audio decoded.
+func (stream *SpeechStream) Recv() (event SpeechStreamEvent, err error) {
+	event, err = stream.streamReader.Recv()
+	if err != nil {
+		return
+	}
+	if event.Audio != "" {
+		event.Data, err = base64.StdEncoding.DecodeString(event.Audio)
+	}
+	return
+}
+
+// CreateSpeechStream — API call to create a speech streamed as server-sent events.
+func (c *Client) CreateSpeechStream(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	opts ...RequestOption,
+) (stream *SpeechStream, err error) {
+	if request.Model == TTSModel1 || request.Model == TTSModel1HD {
+		err = ErrSpeechStreamUnsupportedModel
+		return
+	}
+
+	request.StreamFormat = SpeechStreamFormatSSE
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/audio/speech", withModel(string(request.Model))),
+		withBody(request),
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[SpeechStreamEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &SpeechStream{
+		streamReader: resp,
+	}
+	return
+}
+
+// SpeechStreamResult is the outcome of StreamSpeech.
+type SpeechStreamResult struct {
+	// Written is the number of bytes of audio written.
+	Written int64
+	// Usage is only reported when the speech is streamed as server-sent events.
+	Usage *SpeechUsage
+
+	httpHeader
+}
+
+// StreamSpeech creates a speech and writes its audio to w as it arrives, so that playback can
+// start before the synthesis finishes. With SpeechStreamFormatSSE the audio deltas are decoded,
+// otherwise the response body is copied as it is received.
+//
+// To write SpeechResponseFormatPcm audio as a WAV file, pass a WAVWriter of SpeechPCMFormat
+// and close it once StreamSpeech returns.
+func (c *Client) StreamSpeech(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	w io.Writer,
+	opts ...RequestOption,
+) (result SpeechStreamResult, err error) {
+	if request.StreamFormat == SpeechStreamFormatSSE {
+		return c.streamSpeechEvents(ctx, request, w, opts...)
+	}
+
+	response, err := c.CreateSpeech(ctx, request, opts...)
+	if err != nil {
+		return
+	}
+	defer response.Close()
+
+	result.httpHeader = response.httpHeader
+	result.Written, err = io.Copy(w, response)
+	return
+}
+
+func (c *Client) streamSpeechEvents(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	w io.Writer,
+	opts ...RequestOption,
+) (result SpeechStreamResult, err error) {
+	stream, err := c.CreateSpeechStream(ctx, request, opts...)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+
+	result.httpHeader = stream.httpHeader
+	for {
+		var event SpeechStreamEvent
+		event, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		if event.Usage != nil {
+			result.Usage = event.Usage
+		}
+		if len(event.Data) > 0 {
+			var n int
+			n, err = w.Write(event.Data)
+			result.Written += int64(n)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+func TestStreamSpeechSSE(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/speech", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.CreateSpeechRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.StreamFormat != openai.SpeechStreamFormatSSE || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("unexpected stream format %q", request.StreamFormat)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{"\x01\x00\x02", "\x00"} {
+			fmt.Fprintf(w, "event: speech.audio.delta\ndata: {\"type\":\"speech.audio.delta\",\"audio\":%q}\n\n",
+				base64.StdEncoding.EncodeToString([]byte(chunk)))
+		}
+		fmt.Fprint(w, "event: speech.audio.done\ndata: {\"type\":\"speech.audio.done\","+
+			"\"usage\":{\"input_tokens\":5,\"output_tokens\":10,\"total_tokens\":15}}\n\n")
+	})
+
+	path := filepath.Join(t.TempDir(), "speech.wav")
+	f, err := os.Create(path)
+	checks.NoError(t, err, "Create error")
+	defer f.Close()
+
+	wav := openai.NewWAVWriter(f, openai.SpeechPCMFormat)
+	result, err := client.StreamSpeech(context.Background(), openai.CreateSpeechRequest{
+		Model:          openai.TTSModelGPT4oMini,
+		Input:          "Hi",
+		Voice:          openai.VoiceCoral,
+		ResponseFormat: openai.SpeechResponseFormatPcm,
+		StreamFormat:   openai.SpeechStreamFormatSSE,
+	}, wav)
+	checks.NoError(t, err, "StreamSpeech error")
+	checks.NoError(t, wav.Close(), "Close error")
+
+	if result.Written != 4 || result.Usage == nil || result.Usage.TotalTokens != 15 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	content, err := os.ReadFile(path)
+	checks.NoError(t, err, "ReadFile error")
+	expected := append(openai.SpeechPCMFormat.WAVHeader(4), 1, 0, 2, 0)
+	if !bytes.Equal(content, expected) {
+		t.Errorf("unexpected WAV file %v", content)
+	}
+	if binary.LittleEndian.Uint32(content[24:]) != 24000 {
+		t.Errorf("unexpected sample rate")
+	}
+}
+
+func TestStreamSpeechBinary(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/speech", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "audio/pcm")
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte{byte(i), 0})
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	// A WAV file streamed to a writer which cannot seek announces an unknown size.
+	var buf bytes.Buffer
+	wav := openai.NewWAVWriter(&buf, openai.SpeechPCMFormat)
+	result, err := client.StreamSpeech(context.Background(), openai.CreateSpeechRequest{
+		Model:          openai.TTSModel1,
+		Voice:          openai.VoiceAlloy,
+		ResponseFormat: openai.SpeechResponseFormatPcm,
+	}, wav)
+	checks.NoError(t, err, "StreamSpeech error")
+	checks.NoError(t, wav.Close(), "Close error")
+
+	content := buf.Bytes()
+	if result.Written != 6 || wav.Written() != 6 || len(content) != 44+6 {
+		t.Fatalf("unexpected result %+v and content %v", result, content)
+	}
+	if binary.LittleEndian.Uint32(content[40:]) != 0xFFFFFFFF || !bytes.Equal(content[44:], []byte{0, 0, 1, 0, 2, 0}) {
+		t.Errorf("unexpected WAV stream %v", content)
+	}
+}
+
+func TestSpeechStreamErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/speech", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"voice not found","type":"invalid_request_error"}}`)
+	})
+	ctx := context.Background()
+
+	_, err := client.CreateSpeechStream(ctx, openai.CreateSpeechRequest{Model: openai.TTSModel1HD})
+	checks.ErrorIs(t, err, openai.ErrSpeechStreamUnsupportedModel, "tts-1-hd cannot stream events")
+
+	_, err = client.StreamSpeech(ctx, openai.CreateSpeechRequest{
+		Model:        openai.TTSModelGPT4oMini,
+		StreamFormat: openai.SpeechStreamFormatSSE,
+	}, &bytes.Buffer{})
+	checks.HasError(t, err, "API errors are returned")
+
+	var empty bytes.Buffer
+	checks.NoError(t, openai.NewWAVWriter(&empty, openai.SpeechPCMFormat).Close(), "Close error")
+	if !bytes.Equal(empty.Bytes(), openai.SpeechPCMFormat.WAVHeader(0)) {
+		t.Errorf("an empty WAV file should only have a header")
+	}
+}
@@ -17,7 +17,8 @@ var (
 )
 
 type streamable interface {
-	ChatCompletionStreamResponse | CompletionResponse
+	ChatCompletionStreamResponse | CompletionResponse | ImageStreamEvent | TranscriptionStreamEvent |
+		SpeechStreamEvent
 }
 
 type streamReader[T streamable] struct {
@@ -0,0 +1,365 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	vttHeader     = "WEBVTT"
+	subtitleArrow = "-->"
+	utf8BOM       = "\ufeff"
+)
+
+var (
+	ErrSubtitleInvalid     = errors.New("invalid subtitles")
+	ErrSubtitleInvalidTime = errors.New("invalid subtitle timestamp")
+)
+
+// SubtitleCue is a caption shown between Start and End.
+// Index is the 1-based position of the cue; Text may span several lines.
+type SubtitleCue struct {
+	Index int
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Duration returns how long the cue is shown.
+func (c SubtitleCue) Duration() time.Duration {
+	return c.End - c.Start
+}
+
+// ParseSRT parses SubRip subtitles, as returned with AudioResponseFormatSRT.
+func ParseSRT(text string) ([]SubtitleCue, error) {
+	var cues []SubtitleCue
+	for _, block := range subtitleBlocks(text) {
+		lines := block.lines
+		// The numeric counter is optional in practice.
+		if !strings.Contains(lines[0], subtitleArrow) {
+			if _, err := strconv.Atoi(strings.TrimSpace(lines[0])); err != nil || len(lines) < 2 {
+				return nil, fmt.Errorf("%w: line %d: expected a cue number", ErrSubtitleInvalid, block.line)
+			}
+			lines = lines[1:]
+			block.line++
+		}
+		cue, err := parseSubtitleCue(lines, block.line)
+		if err != nil {
+			return nil, err
+		}
+		cue.Index = len(cues) + 1
+		cues = append(cues, cue)
+	}
+	return cues, nil
+}
+
+// ParseVTT parses WebVTT subtitles, as returned with AudioResponseFormatVTT.
+// NOTE, STYLE and REGION blocks and cue identifiers and settings are skipped.
+func ParseVTT(text string) ([]SubtitleCue, error) {
+	blocks := subtitleBlocks(text)
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0].lines[0], vttHeader) {
+		return nil, fmt.Errorf("%w: missing %s header", ErrSubtitleInvalid, vttHeader)
+	}
+
+	var cues []SubtitleCue
+	for _, block := range blocks[1:] {
+		lines := block.lines
+		switch first := lines[0]; {
+		case first == "NOTE" || strings.HasPrefix(first, "NOTE "),
+			first == "STYLE", first == "REGION":
+			continue
+		case !strings.Contains(first, subtitleArrow):
+			if len(lines) < 2 {
+				return nil, fmt.Errorf("%w: line %d: expected a cue timing", ErrSubtitleInvalid, block.line)
+			}
+			lines = lines[1:]
+			block.line++
+		}
+		cue, err := parseSubtitleCue(lines, block.line)
+		if err != nil {
+			return nil, err
+		}
+		cue.Index = len(cues) + 1
+		cues = append(cues, cue)
+	}
+	return cues, nil
+}
+
+type subtitleBlock struct {
+	line  int
+	lines []string
+}
+
+// subtitleBlocks splits subtitles into the groups of lines separated by blank lines.
+func subtitleBlocks(text string) []subtitleBlock {
+	text = strings.TrimPrefix(text, utf8BOM)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var (
+		blocks  []subtitleBlock
+		current *subtitleBlock
+	)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			blocks = append(blocks, subtitleBlock{line: i + 1})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, line)
+	}
+	return blocks
+}
+
+// parseSubtitleCue parses a timing line followed by the text of the cue.
+func parseSubtitleCue(lines []string, line int) (cue SubtitleCue, err error) {
+	start, rest, found := strings.Cut(lines[0], subtitleArrow)
+	if !found {
+		err = fmt.Errorf("%w: line %d: expected a cue timing", ErrSubtitleInvalid, line)
+		return
+	}
+	// Settings may follow the end time.
+	end := strings.Fields(rest)
+	if len(end) == 0 {
+		err = fmt.Errorf("%w: line %d: missing end time", ErrSubtitleInvalid, line)
+		return
+	}
+
+	if cue.Start, err = ParseSubtitleTime(strings.TrimSpace(start)); err != nil {
+		err = fmt.Errorf("line %d: %w", line, err)
+		return
+	}
+	if cue.End, err = ParseSubtitleTime(end[0]); err != nil {
+		err = fmt.Errorf("line %d: %w", line, err)
+		return
+	}
+	cue.Text = strings.Join(lines[1:], "\n")
+	return
+}
+
+// ParseSubtitleTime parses an SRT (00:01:02,500) or VTT (00:01:02.500 or 01:02.500) timestamp.
+func ParseSubtitleTime(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("%w: %q", ErrSubtitleInvalidTime, value)
+	}
+
+	seconds, millis, found := strings.Cut(parts[len(parts)-1], ",")
+	if !found {
+		seconds, millis, found = strings.Cut(seconds, ".")
+	}
+	if !found || len(millis) != 3 {
+		return 0, fmt.Errorf("%w: %q", ErrSubtitleInvalidTime, value)
+	}
+
+	units := []time.Duration{time.Millisecond, time.Second, time.Minute, time.Hour}
+	fields := append([]string{millis, seconds}, reverseStrings(parts[:len(parts)-1])...)
+	var total time.Duration
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%w: %q", ErrSubtitleInvalidTime, value)
+		}
+		total += time.Duration(n) * units[i]
+	}
+	return total, nil
+}
+
+func reverseStrings(values []string) []string {
+	reversed := make([]string, len(values))
+	for i, v := range values {
+		reversed[len(values)-1-i] = v
+	}
+	return reversed
+}
+
+// FormatSubtitleTime formats d as hh:mm:ss followed by the separator and milliseconds:
+// "," for SRT and "." for VTT.
+func FormatSubtitleTime(d time.Duration, separator string) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Millisecond)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d",
+		d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second, separator, d%time.Second/time.Millisecond)
+}
+
+// FormatSRT returns cues as SubRip subtitles, numbered from 1.
+func FormatSRT(cues []SubtitleCue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+			i+1, FormatSubtitleTime(cue.Start, ","), FormatSubtitleTime(cue.End, ","), cue.Text)
+	}
+	return b.String()
+}
+
+// FormatVTT returns cues as WebVTT subtitles.
+func FormatVTT(cues []SubtitleCue) string {
+	var b strings.Builder
+	b.WriteString(vttHeader + "\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n",
+			FormatSubtitleTime(cue.Start, "."), FormatSubtitleTime(cue.End, "."), cue.Text)
+	}
+	return b.String()
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds*float64(time.Second/time.Millisecond))) * time.Millisecond
+}
+
+// SubtitleCues returns the cues of a transcription: the segments of a verbose_json response,
+// or the parsed Text of an srt or vtt response.
+func (r AudioResponse) SubtitleCues() ([]SubtitleCue, error) {
+	if len(r.Segments) > 0 {
+		cues := make([]SubtitleCue, len(r.Segments))
+		for i, segment := range r.Segments {
+			cues[i] = SubtitleCue{
+				Index: i + 1,
+				Start: secondsToDuration(segment.Start),
+				End:   secondsToDuration(segment.End),
+				Text:  strings.TrimSpace(segment.Text),
+			}
+		}
+		return cues, nil
+	}
+
+	text := strings.TrimSpace(strings.TrimPrefix(r.Text, utf8BOM))
+	if strings.HasPrefix(text, vttHeader) {
+		return ParseVTT(text)
+	}
+	return ParseSRT(text)
+}
+
+// AudioResponseFromSubtitleCues returns a verbose_json style response with one segment per cue,
+// so that subtitles can be used wherever segments are expected.
+func AudioResponseFromSubtitleCues(cues []SubtitleCue) AudioResponse {
+	var response AudioResponse
+	response.Segments = make([]TranscriptionSegment, len(cues))
+	texts := make([]string, len(cues))
+	for i, cue := range cues {
+		segment := &response.Segments[i]
+		segment.ID = i
+		segment.Seek = int(cue.Start / (time.Second / whisperSeekFramesPerSecond))
+		segment.Start = cue.Start.Seconds()
+		segment.End = cue.End.Seconds()
+		segment.Text = " " + cue.Text
+		texts[i] = cue.Text
+		if end := cue.End.Seconds(); end > response.Duration {
+			response.Duration = end
+		}
+	}
+	response.Text = strings.Join(texts, " ")
+	return response
+}
+
+// SRT returns the cues of a transcription as SubRip subtitles. See SubtitleCues for the cues used.
+func (r AudioResponse) SRT() (string, error) {
+	cues, err := r.SubtitleCues()
+	if err != nil {
+		return "", err
+	}
+	return FormatSRT(cues), nil
+}
+
+// VTT returns the cues of a transcription as WebVTT subtitles. See SubtitleCues for the cues used.
+func (r AudioResponse) VTT() (string, error) {
+	cues, err := r.SubtitleCues()
+	if err != nil {
+		return "", err
+	}
+	return FormatVTT(cues), nil
+}
+
+// SubtitleResegmentOptions limits the length of cues. Zero values are not limited.
+type SubtitleResegmentOptions struct {
+	// MaxChars is the largest number of characters of a cue, line breaks excluded.
+	MaxChars int
+	// MaxDuration is the longest time a cue is shown.
+	MaxDuration time.Duration
+}
+
+// ResegmentSubtitleCues splits cues which exceed the limits at word boundaries.
+// The time of a cue is shared between its consecutive parts in proportion to their length.
+// A single word longer than MaxChars is kept whole. The returned cues are renumbered.
+func ResegmentSubtitleCues(cues []SubtitleCue, options SubtitleResegmentOptions) []SubtitleCue {
+	var result []SubtitleCue
+	for _, cue := range cues {
+		for _, part := range splitSubtitleCue(cue, options) {
+			part.Index = len(result) + 1
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func splitSubtitleCue(cue SubtitleCue, options SubtitleResegmentOptions) []SubtitleCue {
+	words := strings.Fields(cue.Text)
+	text := strings.Join(words, " ")
+	length := utf8.RuneCountInString(text)
+	if length == 0 || (options.MaxChars <= 0 || length <= options.MaxChars) &&
+		(options.MaxDuration <= 0 || cue.Duration() <= options.MaxDuration) {
+		return []SubtitleCue{cue}
+	}
+
+	// The number of characters that can be shown within MaxDuration.
+	maxChars := options.MaxChars
+	if options.MaxDuration > 0 && cue.Duration() > 0 {
+		durationChars := int(float64(length) * float64(options.MaxDuration) / float64(cue.Duration()))
+		if maxChars <= 0 || durationChars < maxChars {
+			maxChars = durationChars
+		}
+	}
+
+	var (
+		parts    []SubtitleCue
+		current  []string
+		chars    int
+		consumed int
+	)
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		start := cue.Start
+		if len(parts) > 0 {
+			start = parts[len(parts)-1].End
+		}
+		consumed += chars
+		parts = append(parts, SubtitleCue{
+			Start: start,
+			End:   cue.Start + proportionalDuration(cue.Duration(), consumed, length),
+			Text:  strings.Join(current, " "),
+		})
+		current, chars = nil, 0
+	}
+	for _, word := range words {
+		wordChars := utf8.RuneCountInString(word)
+		if len(current) > 0 && chars+1+wordChars > maxChars {
+			flush()
+			// the space between two parts belongs to the later one
+			consumed++
+		}
+		if len(current) > 0 {
+			chars++
+		}
+		current = append(current, word)
+		chars += wordChars
+	}
+	flush()
+	parts[len(parts)-1].End = cue.End
+	return parts
+}
+
+func proportionalDuration(total time.Duration, part, length int) time.Duration {
+	return (total * time.Duration(part) / time.Duration(length)).Round(time.Millisecond)
+}
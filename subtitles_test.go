@@ -0,0 +1,143 @@
+package openai_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+const testSRT = "\ufeff1\r\n00:00:00,000 --> 00:00:02,500\r\nHello there.\r\n\r\n" +
+	"2\r\n00:00:02,500 --> 00:01:05,040\r\nGeneral Kenobi!\r\nYou are a bold one.\r\n"
+
+const testVTT = `WEBVTT - transcript
+
+NOTE generated by the audio API
+
+STYLE
+::cue { color: white }
+
+intro
+00:00.000 --> 00:02.500 align:start
+Hello there.
+
+00:00:02.500 --> 00:01:05.040
+General Kenobi!
+You are a bold one.
+`
+
+var testCues = []openai.SubtitleCue{
+	{Index: 1, Start: 0, End: 2500 * time.Millisecond, Text: "Hello there."},
+	{
+		Index: 2,
+		Start: 2500 * time.Millisecond,
+		End:   65040 * time.Millisecond,
+		Text:  "General Kenobi!\nYou are a bold one.",
+	},
+}
+
+func TestParseSubtitles(t *testing.T) {
+	cues, err := openai.ParseSRT(testSRT)
+	checks.NoError(t, err, "ParseSRT error")
+	if !reflect.DeepEqual(cues, testCues) {
+		t.Errorf("unexpected SRT cues %+v", cues)
+	}
+
+	cues, err = openai.ParseVTT(testVTT)
+	checks.NoError(t, err, "ParseVTT error")
+	if !reflect.DeepEqual(cues, testCues) {
+		t.Errorf("unexpected VTT cues %+v", cues)
+	}
+
+	_, err = openai.ParseVTT(testSRT)
+	checks.ErrorIs(t, err, openai.ErrSubtitleInvalid, "VTT requires a header")
+	_, err = openai.ParseSRT("1\n00:00:01,000 -> 00:00:02,000\nbroken")
+	checks.ErrorIs(t, err, openai.ErrSubtitleInvalid, "missing arrow")
+	_, err = openai.ParseSRT("1\n00:00:01 --> 00:00:02,000\nbroken")
+	checks.ErrorIs(t, err, openai.ErrSubtitleInvalidTime, "missing milliseconds")
+}
+
+func TestSubtitleRoundTrip(t *testing.T) {
+	srt := openai.FormatSRT(testCues)
+	if !strings.HasPrefix(srt, "1\n00:00:00,000 --> 00:00:02,500\nHello there.\n\n2\n00:00:02,500 --> 00:01:05,040\n") {
+		t.Errorf("unexpected SRT %q", srt)
+	}
+	vtt := openai.FormatVTT(testCues)
+	if !strings.HasPrefix(vtt, "WEBVTT\n\n00:00:00.000 --> 00:00:02.500\nHello there.\n\n") {
+		t.Errorf("unexpected VTT %q", vtt)
+	}
+
+	// SRT -> verbose_json segments -> VTT -> cues
+	cues, err := openai.AudioResponse{Text: testSRT}.SubtitleCues()
+	checks.NoError(t, err, "SubtitleCues error")
+	response := openai.AudioResponseFromSubtitleCues(cues)
+	if len(response.Segments) != 2 || response.Segments[1].Start != 2.5 || response.Segments[1].Seek != 250 ||
+		response.Duration != 65.04 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	vtt, err = response.VTT()
+	checks.NoError(t, err, "VTT error")
+	cues, err = openai.AudioResponse{Text: vtt}.SubtitleCues()
+	checks.NoError(t, err, "SubtitleCues error")
+	if !reflect.DeepEqual(cues, testCues) {
+		t.Errorf("unexpected cues after round trip %+v", cues)
+	}
+}
+
+func TestVerboseJSONSubtitles(t *testing.T) {
+	var response openai.AudioResponse
+	checks.NoError(t, json.Unmarshal([]byte(`{"segments":[
+		{"id":0,"start":0.0,"end":1.2344,"text":" One."},
+		{"id":1,"start":1.2344,"end":3725.5,"text":" Two."}]}`), &response), "Unmarshal error")
+
+	expected := "1\n00:00:00,000 --> 00:00:01,234\nOne.\n\n2\n00:00:01,234 --> 01:02:05,500\nTwo.\n\n"
+	srt, err := response.SRT()
+	checks.NoError(t, err, "SRT error")
+	if srt != expected {
+		t.Errorf("unexpected SRT %q", srt)
+	}
+
+	_, err = openai.AudioResponse{Text: "1\nnot a time range\nHello.\n"}.SRT()
+	checks.HasError(t, err, "SRT should fail on malformed subtitles")
+	_, err = openai.AudioResponse{Text: "WEBVTT\n\n00:00:01.000 --> later\nHello.\n"}.VTT()
+	checks.HasError(t, err, "VTT should fail on malformed subtitles")
+}
+
+func TestResegmentSubtitleCues(t *testing.T) {
+	cues := []openai.SubtitleCue{
+		{Index: 1, Start: 0, End: 10 * time.Second, Text: "aaaa bbbb\ncccc dddd eeee"},
+		{Index: 2, Start: 10 * time.Second, End: 11 * time.Second, Text: "short"},
+	}
+
+	byChars := openai.ResegmentSubtitleCues(cues, openai.SubtitleResegmentOptions{MaxChars: 10})
+	texts := make([]string, len(byChars))
+	for i, cue := range byChars {
+		texts[i] = cue.Text
+		if cue.Index != i+1 {
+			t.Errorf("cue %d is numbered %d", i, cue.Index)
+		}
+	}
+	if !reflect.DeepEqual(texts, []string{"aaaa bbbb", "cccc dddd", "eeee", "short"}) {
+		t.Fatalf("unexpected cues %q", texts)
+	}
+	// 24 characters over 10 seconds: each part lasts in proportion to its length.
+	if byChars[0].End != 3750*time.Millisecond || byChars[1].Start != byChars[0].End ||
+		byChars[1].End != 7917*time.Millisecond || byChars[2].End != 10*time.Second ||
+		byChars[3].Start != cues[1].Start || byChars[3].End != cues[1].End {
+		t.Errorf("unexpected timings %+v", byChars)
+	}
+
+	byDuration := openai.ResegmentSubtitleCues(cues, openai.SubtitleResegmentOptions{MaxDuration: 5 * time.Second})
+	if len(byDuration) != 4 {
+		t.Fatalf("unexpected cues %+v", byDuration)
+	}
+	for _, cue := range byDuration {
+		if cue.Duration() > 5*time.Second {
+			t.Errorf("cue is too long %+v", cue)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
+)
+
+const (
+	// The audio API accepts files of up to 25MB, the default chunk size leaves room
+	// for the rest of the multipart form.
+	defaultLongAudioChunkSize     = 24 * 1024 * 1024
+	defaultLongAudioSilenceWindow = 10 * time.Second
+	defaultLongAudioConcurrency   = 1
+	// longAudioPromptRunes bounds the previous text passed as prompt, the model only
+	// considers the last 224 tokens of a prompt.
+	longAudioPromptRunes = 800
+	// whisper seeks are expressed in 10ms frames.
+	whisperSeekFramesPerSecond = 100
+)
+
+var ErrLongAudioChunkTooSmall = errors.New("maximum chunk size or duration is too small to hold any audio")
+
+// LongAudioRequest is a transcription of audio that may exceed the size limit of the audio API.
+//
+// WAV files and raw PCM audio are split in pure Go into chunks which are transcribed
+// and merged into a single response. Other formats are sent as is and must fit in a single request.
+//
+// The chunks are transcribed sequentially by default. Every chunk is sent with the end of the text
+// of the previous chunk as prompt, so a chunk cannot be sent before the previous one is transcribed.
+// Set Concurrency to transcribe chunks concurrently at the cost of continuity at the boundaries
+// of the runs of chunks transcribed in parallel.
+type LongAudioRequest struct {
+	AudioRequest
+
+	// PCMFormat describes the input when it is raw PCM audio rather than a WAV file.
+	PCMFormat *PCMFormat
+	// MaxChunkSize is the size of the largest chunk sent, 24MB by default.
+	MaxChunkSize int64
+	// MaxChunkDuration optionally limits the duration of every chunk.
+	MaxChunkDuration time.Duration
+	// SilenceWindow is the length of audio before the end of a full chunk in which the
+	// quietest point is used as the boundary, 10 seconds by default.
+	// A negative value splits chunks at exactly MaxChunkSize or MaxChunkDuration.
+	SilenceWindow time.Duration
+	// Concurrency is the number of chunks transcribed in parallel, 1 (sequential) by default.
+	//
+	// With a higher concurrency, the chunks are divided into as many contiguous runs transcribed
+	// in parallel. The chunks of a run are still chained, but the first chunk of every run is sent
+	// with AudioRequest.Prompt instead of the text of the previous chunk.
+	Concurrency int
+}
+
+func (r LongAudioRequest) withDefaults() LongAudioRequest {
+	if r.MaxChunkSize == 0 {
+		r.MaxChunkSize = defaultLongAudioChunkSize
+	}
+	if r.SilenceWindow == 0 {
+		r.SilenceWindow = defaultLongAudioSilenceWindow
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = defaultLongAudioConcurrency
+	}
+	return r
+}
+
+// audioChunk is a part of the audio and the time at which it starts.
+type audioChunk struct {
+	reader   io.ReadSeeker
+	name     string
+	offset   float64
+	duration float64 // unknown for audio sent as is
+}
+
+// CreateLongTranscription transcribes audio of any length. See LongAudioRequest for how it is split.
+//
+// With the verbose_json, srt or vtt formats, the chunks are transcribed as verbose_json and the
+// segments and words are merged with their times moved to the position of the chunk in the audio.
+// The srt and vtt outputs are then generated from the merged segments.
+// With the json and text formats, the text of the chunks is concatenated.
+func (c *Client) CreateLongTranscription(
+	ctx context.Context,
+	request LongAudioRequest,
+	opts ...RequestOption,
+) (response AudioResponse, err error) {
+	if request.Stream {
+		err = ErrTranscriptionStreamNotSupported
+		return
+	}
+	request = request.withDefaults()
+
+	source, size, closeSource, err := longAudioSource(request.AudioRequest)
+	if err != nil {
+		return
+	}
+	defer closeSource()
+
+	chunks, err := splitLongAudio(source, size, request)
+	if err != nil {
+		return
+	}
+
+	chunkRequest := request.AudioRequest
+	chunkRequest.Reader = nil
+	chunkRequest.Progress = nil
+	switch request.Format {
+	case AudioResponseFormatSRT, AudioResponseFormatVTT:
+		chunkRequest.Format = AudioResponseFormatVerboseJSON
+	case "":
+		chunkRequest.Format = AudioResponseFormatJSON
+	}
+
+	responses, err := c.transcribeAudioChunks(ctx, chunkRequest, chunks, request.Concurrency, opts...)
+	if err != nil {
+		return
+	}
+	response = mergeAudioResponses(responses, chunks)
+
+	switch request.Format {
+	case AudioResponseFormatSRT:
+		response.Text, err = response.SRT()
+	case AudioResponseFormatVTT:
+		response.Text, err = response.VTT()
+	}
+	return
+}
+
+// longAudioSource returns random access to the audio of the request.
+// Readers that cannot be read at an offset are read into memory.
+func longAudioSource(request AudioRequest) (source io.ReaderAt, size int64, closeSource func(), err error) {
+	closeSource = func() {}
+	if request.Reader == nil {
+		var f *os.File
+		f, err = os.Open(request.FilePath)
+		if err != nil {
+			err = fmt.Errorf("opening audio file: %w", err)
+			return
+		}
+		var info os.FileInfo
+		if info, err = f.Stat(); err != nil {
+			f.Close()
+			return
+		}
+		return f, info.Size(), func() { f.Close() }, nil
+	}
+
+	if readerAt, ok := request.Reader.(io.ReaderAt); ok {
+		if seeker, isSeeker := request.Reader.(io.Seeker); isSeeker {
+			if size = utils.ReaderSize(request.Reader); size >= 0 {
+				var offset int64
+				if offset, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+					return
+				}
+				return io.NewSectionReader(readerAt, offset, size), size, closeSource, nil
+			}
+		}
+	}
+
+	data, err := io.ReadAll(request.Reader)
+	if err != nil {
+		return
+	}
+	return bytes.NewReader(data), int64(len(data)), closeSource, nil
+}
+
+// splitLongAudio cuts the audio into chunks that can each be sent to the audio API.
+func splitLongAudio(source io.ReaderAt, size int64, request LongAudioRequest) ([]audioChunk, error) {
+	var (
+		format     PCMFormat
+		dataOffset int64
+		dataSize   = size
+		err        error
+	)
+	if request.PCMFormat != nil {
+		format = *request.PCMFormat
+		if err = format.validate(); err != nil {
+			return nil, err
+		}
+		dataSize -= dataSize % format.blockAlign()
+	} else {
+		format, dataOffset, dataSize, err = readWAVHeader(source, size)
+		if errors.Is(err, ErrWAVInvalid) && size <= request.MaxChunkSize {
+			// Other formats are sent as is when they fit in one request.
+			return []audioChunk{{
+				reader: io.NewSectionReader(source, 0, size),
+				name:   request.FilePath,
+			}}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	block := format.blockAlign()
+	maxBytes := request.MaxChunkSize - wavHeaderSize
+	if request.MaxChunkDuration > 0 {
+		durationBytes := int64(request.MaxChunkDuration.Seconds() * float64(format.byteRate()))
+		if durationBytes < maxBytes {
+			maxBytes = durationBytes
+		}
+	}
+	maxBytes -= maxBytes % block
+	if maxBytes <= 0 {
+		return nil, ErrLongAudioChunkTooSmall
+	}
+
+	var window int64
+	if request.SilenceWindow > 0 {
+		window = int64(request.SilenceWindow.Seconds() * float64(format.byteRate()))
+		// Chunks are never shortened to less than half of the maximum.
+		if window > maxBytes/2 {
+			window = maxBytes / 2
+		}
+		window -= window % block
+	}
+
+	var chunks []audioChunk
+	for start := int64(0); start < dataSize; {
+		end := start + maxBytes
+		if end >= dataSize {
+			end = dataSize
+		} else if window > 0 {
+			buffer := make([]byte, window)
+			if _, err = source.ReadAt(buffer, dataOffset+end-window); err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			end = end - window + format.quietestOffset(buffer)
+			if end <= start {
+				end = start + maxBytes
+			}
+		}
+
+		chunks = append(chunks, audioChunk{
+			reader:   newWAVChunkReader(format, io.NewSectionReader(source, dataOffset+start, end-start)),
+			name:     fmt.Sprintf("chunk-%03d.wav", len(chunks)),
+			offset:   format.Duration(start),
+			duration: format.Duration(end - start),
+		})
+		start = end
+	}
+	return chunks, nil
+}
+
+// newWAVChunkReader returns a WAV file made of a canonical header followed by the samples of data.
+func newWAVChunkReader(format PCMFormat, data *io.SectionReader) io.ReadSeeker {
+	header := format.WAVHeader(data.Size())
+	return io.NewSectionReader(&wavChunkReaderAt{header: header, data: data}, 0, int64(len(header))+data.Size())
+}
+
+type wavChunkReaderAt struct {
+	header []byte
+	data   *io.SectionReader
+}
+
+func (r *wavChunkReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	headerSize := int64(len(r.header))
+	if off < headerSize {
+		n = copy(p, r.header[off:])
+		if n == len(p) {
+			return n, nil
+		}
+		off += int64(n)
+	}
+	m, err := r.data.ReadAt(p[n:], off-headerSize)
+	return n + m, err
+}
+
+// transcribeAudioChunks transcribes the chunks in contiguous runs, one run per unit of concurrency.
+func (c *Client) transcribeAudioChunks(
+	ctx context.Context,
+	request AudioRequest,
+	chunks []audioChunk,
+	concurrency int,
+	opts ...RequestOption,
+) ([]AudioResponse, error) {
+	responses := make([]AudioResponse, len(chunks))
+	if concurrency > len(chunks) {
+		concurrency = len(chunks)
+	}
+	runSize := (len(chunks) + concurrency - 1) / concurrency
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for first := 0; first < len(chunks); first += runSize {
+		last := first + runSize
+		if last > len(chunks) {
+			last = len(chunks)
+		}
+
+		wg.Add(1)
+		go func(first, last int) {
+			defer wg.Done()
+			prompt := request.Prompt
+			for i := first; i < last; i++ {
+				chunkRequest := request
+				chunkRequest.Reader = chunks[i].reader
+				chunkRequest.FilePath = chunks[i].name
+				chunkRequest.Prompt = prompt
+
+				response, err := c.CreateTranscription(ctx, chunkRequest, opts...)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("transcribing chunk %d: %w", i+1, err)
+						cancel()
+					}
+					mu.Unlock()
+					return
+				}
+				responses[i] = response
+				if text := strings.TrimSpace(response.Text); text != "" {
+					prompt = promptTail(text)
+				}
+			}
+		}(first, last)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return responses, nil
+}
+
+// promptTail returns the end of text, starting at a word boundary, short enough to be used as a prompt.
+func promptTail(text string) string {
+	if utf8.RuneCountInString(text) <= longAudioPromptRunes {
+		return text
+	}
+	runes := []rune(text)
+	tail := string(runes[len(runes)-longAudioPromptRunes:])
+	if i := strings.IndexAny(tail, " \n"); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return tail
+}
+
+// mergeAudioResponses joins the transcriptions of consecutive chunks, moving the segments
+// and words of every chunk to the time at which the chunk starts.
+func mergeAudioResponses(responses []AudioResponse, chunks []audioChunk) (merged AudioResponse) {
+	texts := make([]string, 0, len(responses))
+	for i, response := range responses {
+		offset := chunks[i].offset
+		if i == 0 {
+			merged.Task = response.Task
+			merged.Language = response.Language
+			merged.httpHeader = response.httpHeader
+		}
+		if text := strings.TrimSpace(response.Text); text != "" {
+			texts = append(texts, text)
+		}
+
+		for _, segment := range response.Segments {
+			segment.ID = len(merged.Segments)
+			segment.Seek += int(math.Round(offset * whisperSeekFramesPerSecond))
+			segment.Start += offset
+			segment.End += offset
+			merged.Segments = append(merged.Segments, segment)
+		}
+		for _, word := range response.Words {
+			word.Start += offset
+			word.End += offset
+			merged.Words = append(merged.Words, word)
+		}
+		merged.Logprobs = append(merged.Logprobs, response.Logprobs...)
+		merged.Usage = addTranscriptionUsage(merged.Usage, response.Usage)
+
+		duration := chunks[i].duration
+		if duration == 0 {
+			duration = response.Duration
+		}
+		merged.Duration = offset + duration
+	}
+	merged.Text = strings.Join(texts, " ")
+	return
+}
+
+func addTranscriptionUsage(total, usage *TranscriptionUsage) *TranscriptionUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		sum := *usage
+		if usage.InputTokenDetails != nil {
+			details := *usage.InputTokenDetails
+			sum.InputTokenDetails = &details
+		}
+		return &sum
+	}
+	total.InputTokens += usage.InputTokens
+	total.OutputTokens += usage.OutputTokens
+	total.TotalTokens += usage.TotalTokens
+	total.Seconds += usage.Seconds
+	if usage.InputTokenDetails != nil {
+		if total.InputTokenDetails == nil {
+			total.InputTokenDetails = &TranscriptionInputTokenDetail{}
+		}
+		total.InputTokenDetails.TextTokens += usage.InputTokenDetails.TextTokens
+		total.InputTokenDetails.AudioTokens += usage.InputTokenDetails.AudioTokens
+	}
+	return total
+}
@@ -0,0 +1,203 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+var testPCMFormat = openai.PCMFormat{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+// testSpeech returns 16 bit PCM samples alternating between a tone and silence, in seconds.
+func testSpeech(pattern ...float64) []byte {
+	var buf bytes.Buffer
+	for i, seconds := range pattern {
+		samples := int(seconds * float64(testPCMFormat.SampleRate))
+		for n := 0; n < samples; n++ {
+			var sample int16
+			if i%2 == 0 {
+				sample = int16(math.Sin(float64(n)/4) * 10000)
+			}
+			_ = binary.Write(&buf, binary.LittleEndian, sample)
+		}
+	}
+	return buf.Bytes()
+}
+
+type longTranscriptionServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	prompts map[string]string
+	sizes   []int
+}
+
+// handle answers with a single segment covering the uploaded WAV chunk, named after its duration.
+func (s *longTranscriptionServer) handle(w http.ResponseWriter, r *http.Request) {
+	checks.NoError(s.t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+	file, header, err := r.FormFile("file")
+	checks.NoError(s.t, err, "FormFile error")
+	data, err := io.ReadAll(file)
+	checks.NoError(s.t, err, "ReadAll error")
+	if string(data[:4]) != "RIFF" || header.Header.Get("Content-Type") != "audio/wav" {
+		s.t.Errorf("chunk %s is not a WAV file", header.Filename)
+	}
+	if r.FormValue("response_format") != string(openai.AudioResponseFormatVerboseJSON) {
+		s.t.Errorf("unexpected response format %q", r.FormValue("response_format"))
+	}
+
+	dataSize := int64(binary.LittleEndian.Uint32(data[40:]))
+	duration := testPCMFormat.Duration(dataSize)
+	text := fmt.Sprintf("%s lasts %.2f seconds.", header.Filename, duration)
+
+	s.mu.Lock()
+	s.prompts[header.Filename] = r.FormValue("prompt")
+	s.sizes = append(s.sizes, len(data))
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"task":     "transcribe",
+		"language": "english",
+		"duration": duration,
+		"text":     text,
+		"segments": []map[string]any{{"id": 0, "seek": 0, "start": 0, "end": duration, "text": " " + text}},
+		"words":    []map[string]any{{"word": "lasts", "start": 0.5, "end": 0.75}},
+		"usage":    map[string]any{"type": "duration", "seconds": duration},
+	})
+}
+
+func TestCreateLongTranscription(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &longTranscriptionServer{t: t, prompts: map[string]string{}}
+	server.RegisterHandler("/v1/audio/transcriptions", handler.handle)
+
+	path := filepath.Join(t.TempDir(), "meeting.wav")
+	samples := testSpeech(3, 1, 3, 1, 2)
+	content := append(testPCMFormat.WAVHeader(int64(len(samples))), samples...)
+	checks.NoError(t, os.WriteFile(path, content, 0o600), "WriteFile error")
+
+	response, err := client.CreateLongTranscription(context.Background(), openai.LongAudioRequest{
+		AudioRequest: openai.AudioRequest{
+			Model:    openai.Whisper1,
+			FilePath: path,
+			Prompt:   "Weekly meeting.",
+			Format:   openai.AudioResponseFormatSRT,
+		},
+		MaxChunkDuration: 5 * time.Second,
+		SilenceWindow:    2 * time.Second,
+		Concurrency:      1,
+	})
+	checks.NoError(t, err, "CreateLongTranscription error")
+
+	if len(response.Segments) != 3 {
+		t.Fatalf("expected 3 segments, got %+v", response.Segments)
+	}
+	// The chunks are cut in the middle of the silences.
+	for i, bounds := range [][2]float64{{0, 3.5}, {3.5, 7.5}, {7.5, 10}} {
+		segment := response.Segments[i]
+		if segment.ID != i || math.Abs(segment.Start-bounds[0]) > 0.02 || math.Abs(segment.End-bounds[1]) > 0.02 {
+			t.Errorf("unexpected segment %d: %+v", i, segment)
+		}
+		if word := response.Words[i]; math.Abs(word.Start-bounds[0]-0.5) > 0.02 {
+			t.Errorf("unexpected word %d: %+v", i, word)
+		}
+	}
+	if math.Abs(response.Duration-10) > 0.001 || response.Usage == nil || math.Abs(response.Usage.Seconds-10) > 0.001 {
+		t.Errorf("unexpected duration %f and usage %+v", response.Duration, response.Usage)
+	}
+
+	if handler.prompts["chunk-000.wav"] != "Weekly meeting." ||
+		handler.prompts["chunk-001.wav"] != "chunk-000.wav lasts 3.50 seconds." ||
+		handler.prompts["chunk-002.wav"] != "chunk-001.wav lasts 4.00 seconds." {
+		t.Errorf("unexpected prompts %v", handler.prompts)
+	}
+
+	if !strings.HasPrefix(response.Text, "1\n00:00:00,000 --> 00:00:03,500\nchunk-000.wav lasts 3.50 seconds.\n\n2\n00:00:03,500") {
+		t.Errorf("unexpected SRT %q", response.Text)
+	}
+}
+
+func TestCreateLongTranscriptionPCM(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &longTranscriptionServer{t: t, prompts: map[string]string{}}
+	server.RegisterHandler("/v1/audio/transcriptions", handler.handle)
+
+	samples := testSpeech(10)
+	response, err := client.CreateLongTranscription(context.Background(), openai.LongAudioRequest{
+		AudioRequest: openai.AudioRequest{
+			Model:    openai.Whisper1,
+			FilePath: "recording.pcm",
+			Reader:   io.MultiReader(bytes.NewReader(samples)),
+			Format:   openai.AudioResponseFormatVerboseJSON,
+		},
+		PCMFormat:     &testPCMFormat,
+		MaxChunkSize:  44 + 2*8000*3,
+		SilenceWindow: -1,
+	})
+	checks.NoError(t, err, "CreateLongTranscription error")
+
+	if len(handler.sizes) != 4 || len(response.Segments) != 4 {
+		t.Fatalf("expected 4 chunks, got %d and %d segments", len(handler.sizes), len(response.Segments))
+	}
+	for i, size := range handler.sizes {
+		if size > 44+2*8000*3 {
+			t.Errorf("chunk %d is too large: %d", i, size)
+		}
+	}
+	if response.Segments[3].Start != 9 || response.Segments[3].Seek != 900 {
+		t.Errorf("unexpected last segment %+v", response.Segments[3])
+	}
+	if !strings.HasPrefix(response.Text, "chunk-000.wav lasts 3.00 seconds. chunk-001.wav") {
+		t.Errorf("unexpected text %q", response.Text)
+	}
+	// Chunks are transcribed one after the other by default, each with the text of the previous one as prompt.
+	for i := 1; i < 4; i++ {
+		previous := fmt.Sprintf("chunk-%03d.wav", i-1)
+		if prompt := handler.prompts[fmt.Sprintf("chunk-%03d.wav", i)]; !strings.HasPrefix(prompt, previous) {
+			t.Errorf("chunk %d was not sent with the text of the previous chunk: %q", i, prompt)
+		}
+	}
+}
+
+func TestCreateLongTranscriptionErrors(t *testing.T) {
+	client := openai.NewClient("")
+	ctx := context.Background()
+
+	_, err := client.CreateLongTranscription(ctx, openai.LongAudioRequest{
+		AudioRequest: openai.AudioRequest{Reader: bytes.NewReader(make([]byte, 100)), FilePath: "long.mp3"},
+		MaxChunkSize: 50,
+	})
+	checks.ErrorIs(t, err, openai.ErrWAVInvalid, "large non WAV files cannot be split")
+
+	_, err = client.CreateLongTranscription(ctx, openai.LongAudioRequest{
+		AudioRequest: openai.AudioRequest{Reader: bytes.NewReader(make([]byte, 100))},
+		PCMFormat:    &openai.PCMFormat{SampleRate: 8000, Channels: 1, BitsPerSample: 12},
+	})
+	checks.ErrorIs(t, err, openai.ErrPCMFormatInvalid, "unsupported sample size")
+
+	_, err = client.CreateLongTranscription(ctx, openai.LongAudioRequest{
+		AudioRequest: openai.AudioRequest{Reader: bytes.NewReader(make([]byte, 100))},
+		PCMFormat:    &testPCMFormat,
+		MaxChunkSize: 40,
+	})
+	checks.ErrorIs(t, err, openai.ErrLongAudioChunkTooSmall, "chunks must hold audio")
+
+	_, err = client.CreateLongTranscription(ctx, openai.LongAudioRequest{AudioRequest: openai.AudioRequest{Stream: true}})
+	checks.ErrorIs(t, err, openai.ErrTranscriptionStreamNotSupported, "long transcriptions cannot stream")
+}
@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpeakerTurn is a run of consecutive segments spoken by the same speaker.
+type SpeakerTurn struct {
+	Speaker  string
+	Start    float64
+	End      float64
+	Text     string
+	Segments []TranscriptionSegment
+}
+
+// Duration returns the length of the turn in seconds.
+func (t SpeakerTurn) Duration() float64 {
+	return t.End - t.Start
+}
+
+// String returns the turn as "Speaker: text".
+func (t SpeakerTurn) String() string {
+	return fmt.Sprintf("%s: %s", t.Speaker, t.Text)
+}
+
+// SpeakerTurns groups the segments of a diarized_json transcription into speaker turns.
+// Consecutive segments of the same speaker are merged into one turn.
+func (r AudioResponse) SpeakerTurns() []SpeakerTurn {
+	var turns []SpeakerTurn
+	for _, segment := range r.Segments {
+		text := strings.TrimSpace(segment.Text)
+		if n := len(turns); n > 0 && turns[n-1].Speaker == segment.Speaker {
+			turn := &turns[n-1]
+			turn.End = segment.End
+			turn.Segments = append(turn.Segments, segment)
+			if text != "" {
+				turn.Text = strings.TrimSpace(turn.Text + " " + text)
+			}
+			continue
+		}
+		turns = append(turns, SpeakerTurn{
+			Speaker:  segment.Speaker,
+			Start:    segment.Start,
+			End:      segment.End,
+			Text:     text,
+			Segments: []TranscriptionSegment{segment},
+		})
+	}
+	return turns
+}
+
+// Speakers returns the speakers of a diarized_json transcription in order of first appearance.
+func (r AudioResponse) Speakers() []string {
+	var speakers []string
+	seen := make(map[string]bool)
+	for _, segment := range r.Segments {
+		if segment.Speaker != "" && !seen[segment.Speaker] {
+			seen[segment.Speaker] = true
+			speakers = append(speakers, segment.Speaker)
+		}
+	}
+	return speakers
+}
+
+// SpeakingTime returns the number of seconds spoken by every speaker.
+func (r AudioResponse) SpeakingTime() map[string]float64 {
+	durations := make(map[string]float64)
+	for _, segment := range r.Segments {
+		if segment.Speaker != "" {
+			durations[segment.Speaker] += segment.End - segment.Start
+		}
+	}
+	return durations
+}
+
+// SpeakerTranscript returns the transcription as one "Speaker: text" line per speaker turn,
+// a form suited to prompts that summarize a conversation.
+func (r AudioResponse) SpeakerTranscript() string {
+	turns := r.SpeakerTurns()
+	lines := make([]string, len(turns))
+	for i, turn := range turns {
+		lines[i] = turn.String()
+	}
+	return strings.Join(lines, "\n")
+}
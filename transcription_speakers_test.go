@@ -0,0 +1,83 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+const testDiarizedResponse = `{
+	"task": "transcribe",
+	"duration": 9.5,
+	"text": "Shall we start? Yes. Let's go through the agenda. First item.",
+	"segments": [
+		{"type": "transcript.text.segment", "id": "seg_0", "start": 0.0, "end": 1.5, "text": " Shall we start?", "speaker": "A"},
+		{"type": "transcript.text.segment", "id": "seg_1", "start": 1.8, "end": 2.2, "text": " Yes.", "speaker": "B"},
+		{"type": "transcript.text.segment", "id": "seg_2", "start": 2.5, "end": 5.0, "text": " Let's go through the agenda.", "speaker": "A"},
+		{"type": "transcript.text.segment", "id": "seg_3", "start": 5.0, "end": 9.5, "text": " First item.", "speaker": "A"}
+	],
+	"usage": {"type": "tokens", "input_tokens": 120, "output_tokens": 20, "total_tokens": 140}
+}`
+
+func TestDiarizedTranscription(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		if r.FormValue("response_format") != "diarized_json" || r.FormValue("chunking_strategy") != "auto" {
+			t.Errorf("unexpected form %v", r.MultipartForm.Value)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testDiarizedResponse)
+	})
+
+	response, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		Model:            openai.GPT4oTranscribeDiarize,
+		FilePath:         "meeting.wav",
+		Reader:           strings.NewReader("audio"),
+		Format:           openai.AudioResponseFormatDiarizedJSON,
+		ChunkingStrategy: &openai.TranscriptionChunkingStrategy{Type: openai.TranscriptionChunkingStrategyAuto},
+	})
+	checks.NoError(t, err, "CreateTranscription error")
+
+	if len(response.Segments) != 4 || response.Segments[2].SegmentID != "seg_2" || response.Segments[2].Speaker != "A" {
+		t.Fatalf("unexpected segments %+v", response.Segments)
+	}
+	if speakers := response.Speakers(); !reflect.DeepEqual(speakers, []string{"A", "B"}) {
+		t.Errorf("unexpected speakers %v", speakers)
+	}
+	if times := response.SpeakingTime(); times["A"] != 8.5 || times["B"] < 0.39 || times["B"] > 0.41 {
+		t.Errorf("unexpected speaking time %v", times)
+	}
+
+	turns := response.SpeakerTurns()
+	if len(turns) != 3 || turns[2].Start != 2.5 || turns[2].End != 9.5 || len(turns[2].Segments) != 2 {
+		t.Fatalf("unexpected turns %+v", turns)
+	}
+	expected := "A: Shall we start?\nB: Yes.\nA: Let's go through the agenda. First item."
+	if transcript := response.SpeakerTranscript(); transcript != expected {
+		t.Errorf("unexpected transcript %q", transcript)
+	}
+}
+
+func TestTranscriptionSegmentJSON(t *testing.T) {
+	for _, segment := range []openai.TranscriptionSegment{
+		{ID: 3, Seek: 300, Start: 3, End: 4, Text: " verbose", Tokens: []int{1, 2}},
+		{SegmentID: "seg_3", Type: "transcript.text.segment", Start: 3, End: 4, Text: " diarized", Speaker: "B"},
+	} {
+		data, err := json.Marshal(segment)
+		checks.NoError(t, err, "Marshal error")
+		var decoded openai.TranscriptionSegment
+		checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+		if !reflect.DeepEqual(decoded, segment) {
+			t.Errorf("segment %s changed to %+v", data, decoded)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+
+	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
+)
+
+// Transcription stream event types.
+const (
+	TranscriptionStreamEventTextDelta   = "transcript.text.delta"
+	TranscriptionStreamEventTextDone    = "transcript.text.done"
+	TranscriptionStreamEventTextSegment = "transcript.text.segment"
+)
+
+// TranscriptionStreamEvent is an event of a streamed transcription.
+// Delta events carry the next piece of text, and the done event the full transcription and usage.
+// Diarization models also send a segment event for every speaker segment.
+type TranscriptionStreamEvent struct {
+	Type     string                 `json:"type"`
+	Delta    string                 `json:"delta,omitempty"`
+	Text     string                 `json:"text,omitempty"`
+	Logprobs []TranscriptionLogprob `json:"logprobs,omitempty"`
+	Usage    *TranscriptionUsage    `json:"usage,omitempty"`
+
+	// SegmentID is the speaker segment a delta belongs to, for diarization models.
+	SegmentID string `json:"segment_id,omitempty"`
+
+	// ID, Start, End and Speaker describe the segment of a segment event.
+	ID      string  `json:"id,omitempty"`
+	Start   float64 `json:"start,omitempty"`
+	End     float64 `json:"end,omitempty"`
+	Speaker string  `json:"speaker,omitempty"`
+}
+
+// TranscriptionStream is a stream of transcription events. Recv returns io.EOF after the done event.
+type TranscriptionStream struct {
+	*streamReader[TranscriptionStreamEvent]
+}
+
+// CreateTranscriptionStream — API call to create a transcription, streaming the text
+// as it is transcribed. It is supported by the gpt-4o transcription models.
+func (c *Client) CreateTranscriptionStream(
+	ctx context.Context,
+	request AudioRequest,
+	opts ...RequestOption,
+) (stream *TranscriptionStream, err error) {
+	if request.Model == Whisper1 {
+		err = ErrTranscriptionStreamUnsupportedModel
+		return
+	}
+
+	request.Stream = true
+	req, err := c.newMultipartRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/audio/transcriptions", withModel(request.Model)),
+		func(b utils.FormBuilder) error {
+			return audioMultipartForm(request, b)
+		},
+		request.Progress,
+		withOptions(opts),
+	)
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[TranscriptionStreamEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &TranscriptionStream{
+		streamReader: resp,
+	}
+	return
+}
@@ -0,0 +1,555 @@
+package openai
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // the Uploads API verifies the file with an MD5 checksum
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	utils "gitlab.forensix.cn/ai/service/go-openai/internal"
+)
+
+const uploadsSuffix = "/uploads"
+
+const (
+	// UploadMaxPartSize is the largest part accepted by the Uploads API.
+	UploadMaxPartSize int64 = 64 * 1024 * 1024
+	// UploadMaxSize is the largest file that can be created through the Uploads API.
+	UploadMaxSize int64 = 8 * 1024 * 1024 * 1024
+
+	defaultUploadConcurrency    = 4
+	defaultUploadMaxPartRetries = 3
+	defaultUploadRetryBackoff   = 500 * time.Millisecond
+	fileMD5BufferSize           = 1024 * 1024
+)
+
+var (
+	ErrUploadFileTooLarge  = errors.New("file exceeds the maximum size supported by the Uploads API")
+	ErrUploadPartTooLarge  = errors.New("part size exceeds the maximum part size supported by the Uploads API")
+	ErrUploadEmptyFile     = errors.New("cannot upload an empty file")
+	ErrUploadNotCompleted  = errors.New("upload was not completed")
+	ErrUploadMissingFileID = errors.New("completed upload does not contain a file")
+)
+
+// Upload is an intermediate object that parts can be added to.
+// Once completed, it contains the nested File object that is ready to use.
+type Upload struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+	ExpiresAt int64  `json:"expires_at"`
+	File      *File  `json:"file,omitempty"`
+
+	httpHeader
+}
+
+// UploadPart represents a chunk of bytes that can be added to an Upload.
+type UploadPart struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	CreatedAt int64  `json:"created_at"`
+	UploadID  string `json:"upload_id"`
+
+	httpHeader
+}
+
+// CreateUploadRequest represents a request to create an Upload.
+type CreateUploadRequest struct {
+	// Filename is the name of the file to upload.
+	Filename string `json:"filename"`
+	// Purpose is the intended purpose of the uploaded file.
+	Purpose PurposeType `json:"purpose"`
+	// Bytes is the number of bytes in the file being uploaded.
+	Bytes int64 `json:"bytes"`
+	// MimeType is the MIME type of the file, it must fall within the supported types for the purpose.
+	MimeType string `json:"mime_type"`
+}
+
+// CompleteUploadRequest represents a request to complete an Upload.
+type CompleteUploadRequest struct {
+	// PartIDs is the ordered list of part IDs.
+	PartIDs []string `json:"part_ids"`
+	// MD5 is the optional hex encoded MD5 checksum of the file, verified by the API.
+	MD5 string `json:"md5,omitempty"`
+}
+
+// CreateUpload creates an intermediate Upload object that parts can be added to.
+func (c *Client) CreateUpload(
+	ctx context.Context,
+	request CreateUploadRequest,
+	opts ...RequestOption,
+) (response Upload, err error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(uploadsSuffix), withBody(request), withOptions(opts))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// AddUploadPart adds a part to an Upload. Each part can be at most 64 MB.
+func (c *Client) AddUploadPart(
+	ctx context.Context,
+	uploadID string,
+	data io.Reader,
+	opts ...RequestOption,
+) (response UploadPart, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/parts", uploadsSuffix, uploadID)
+	req, err := c.newMultipartRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		func(b utils.FormBuilder) error {
+			if formErr := b.CreateFormFileReader("data", data, "part"); formErr != nil {
+				return formErr
+			}
+			return b.Close()
+		}, nil, withOptions(opts))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// CompleteUpload completes an Upload. The returned Upload contains the created File.
+func (c *Client) CompleteUpload(
+	ctx context.Context,
+	uploadID string,
+	request CompleteUploadRequest,
+	opts ...RequestOption,
+) (response Upload, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/complete", uploadsSuffix, uploadID)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request), withOptions(opts))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// CancelUpload cancels an Upload. No parts may be added after an Upload is cancelled.
+func (c *Client) CancelUpload(
+	ctx context.Context,
+	uploadID string,
+	opts ...RequestOption,
+) (response Upload, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/cancel", uploadsSuffix, uploadID)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withOptions(opts))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// LargeFileUploadRequest configures UploadLargeFile.
+type LargeFileUploadRequest struct {
+	// FilePath is the local file to upload.
+	FilePath string
+	// Purpose is the intended purpose of the uploaded file.
+	Purpose PurposeType
+	// Filename defaults to the base name of FilePath.
+	Filename string
+	// MimeType defaults to a type derived from the file extension.
+	MimeType string
+	// PartSize defaults to and must not exceed UploadMaxPartSize.
+	PartSize int64
+	// Concurrency is the number of parts uploaded in parallel, 4 by default.
+	Concurrency int
+	// MaxPartRetries is the number of times a failed part is retried, 3 by default.
+	// A negative value disables retries.
+	MaxPartRetries int
+	// ManifestPath optionally persists the progress of the upload. If the manifest
+	// exists and matches the file, the upload is resumed and only missing parts are sent.
+	// The manifest is removed once the upload is completed.
+	ManifestPath string
+	// Progress is optionally called with the number of file bytes uploaded so far.
+	Progress UploadProgressFunc
+}
+
+// UploadManifest is the locally persisted state of a resumable upload.
+type UploadManifest struct {
+	UploadID  string         `json:"upload_id"`
+	FilePath  string         `json:"file_path"`
+	FileSize  int64          `json:"file_size"`
+	ModTime   int64          `json:"mod_time"`
+	PartSize  int64          `json:"part_size"`
+	ExpiresAt int64          `json:"expires_at"`
+	Parts     map[int]string `json:"parts"`
+}
+
+func (m *UploadManifest) matches(path string, info os.FileInfo, partSize int64) bool {
+	return m.UploadID != "" &&
+		m.FilePath == path &&
+		m.FileSize == info.Size() &&
+		m.ModTime == info.ModTime().UnixNano() &&
+		m.PartSize == partSize &&
+		(m.ExpiresAt == 0 || time.Now().Unix() < m.ExpiresAt)
+}
+
+// LoadUploadManifest reads a manifest written by UploadLargeFile.
+func LoadUploadManifest(path string) (*UploadManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &UploadManifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Parts == nil {
+		manifest.Parts = make(map[int]string)
+	}
+	return manifest, nil
+}
+
+// Save atomically writes the manifest to path.
+func (m *UploadManifest) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (r LargeFileUploadRequest) withDefaults() LargeFileUploadRequest {
+	if r.Filename == "" {
+		r.Filename = filepath.Base(r.FilePath)
+	}
+	if r.MimeType == "" {
+		r.MimeType = mimeTypeByExtension(r.FilePath)
+	}
+	if r.PartSize <= 0 {
+		r.PartSize = UploadMaxPartSize
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = defaultUploadConcurrency
+	}
+	if r.MaxPartRetries < 0 {
+		r.MaxPartRetries = 0
+	} else if r.MaxPartRetries == 0 {
+		r.MaxPartRetries = defaultUploadMaxPartRetries
+	}
+	return r
+}
+
+func mimeTypeByExtension(path string) string {
+	if mimeType := utils.ContentTypeByFilename(path); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// UploadLargeFile uploads a local file through the Uploads API, which supports files
+// larger than the /files endpoint. The file is split into parts that are uploaded in
+// parallel with per-part retries, and the upload is completed with the file's MD5
+// checksum into a regular File.
+//
+// If the upload fails and ManifestPath is set, calling UploadLargeFile again with the
+// same request resumes it. The Upload is not cancelled on failure so that it can be
+// resumed; use CancelUpload to abandon it.
+func (c *Client) UploadLargeFile(
+	ctx context.Context,
+	request LargeFileUploadRequest,
+	opts ...RequestOption,
+) (file File, err error) {
+	request = request.withDefaults()
+	if request.PartSize > UploadMaxPartSize {
+		err = ErrUploadPartTooLarge
+		return
+	}
+
+	f, err := os.Open(request.FilePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	if info.Size() == 0 {
+		err = ErrUploadEmptyFile
+		return
+	}
+	if info.Size() > UploadMaxSize {
+		err = ErrUploadFileTooLarge
+		return
+	}
+
+	manifest, err := c.prepareUploadManifest(ctx, request, info, opts...)
+	if err != nil {
+		return
+	}
+
+	// The checksum is computed while the parts are uploaded and stops as soon as an upload fails.
+	checksumCtx, stopChecksum := context.WithCancel(ctx)
+	defer stopChecksum()
+	checksum := make(chan string, 1)
+	checksumErr := make(chan error, 1)
+	go func() {
+		sum, sumErr := fileMD5(checksumCtx, io.NewSectionReader(f, 0, info.Size()))
+		checksum <- sum
+		checksumErr <- sumErr
+	}()
+
+	partIDs, err := c.uploadParts(ctx, f, info.Size(), request, manifest, opts...)
+	if err != nil {
+		return
+	}
+	sum, sumErr := <-checksum, <-checksumErr
+	if sumErr != nil {
+		err = sumErr
+		return
+	}
+
+	upload, err := c.CompleteUpload(ctx, manifest.UploadID, CompleteUploadRequest{
+		PartIDs: partIDs,
+		MD5:     sum,
+	}, opts...)
+	if err != nil {
+		return
+	}
+	if upload.Status != "completed" {
+		err = fmt.Errorf("%w: status %q", ErrUploadNotCompleted, upload.Status)
+		return
+	}
+	if upload.File == nil {
+		err = ErrUploadMissingFileID
+		return
+	}
+
+	if request.ManifestPath != "" {
+		_ = os.Remove(request.ManifestPath)
+	}
+	file = *upload.File
+	file.httpHeader = upload.httpHeader
+	return
+}
+
+func (c *Client) prepareUploadManifest(
+	ctx context.Context,
+	request LargeFileUploadRequest,
+	info os.FileInfo,
+	opts ...RequestOption,
+) (*UploadManifest, error) {
+	if request.ManifestPath != "" {
+		manifest, err := LoadUploadManifest(request.ManifestPath)
+		switch {
+		case err == nil && manifest.matches(request.FilePath, info, request.PartSize):
+			return manifest, nil
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	upload, err := c.CreateUpload(ctx, CreateUploadRequest{
+		Filename: request.Filename,
+		Purpose:  request.Purpose,
+		Bytes:    info.Size(),
+		MimeType: request.MimeType,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &UploadManifest{
+		UploadID:  upload.ID,
+		FilePath:  request.FilePath,
+		FileSize:  info.Size(),
+		ModTime:   info.ModTime().UnixNano(),
+		PartSize:  request.PartSize,
+		ExpiresAt: upload.ExpiresAt,
+		Parts:     make(map[int]string),
+	}
+	if request.ManifestPath != "" {
+		if err = manifest.Save(request.ManifestPath); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+//nolint:gocognit
+func (c *Client) uploadParts(
+	ctx context.Context,
+	f io.ReaderAt,
+	size int64,
+	request LargeFileUploadRequest,
+	manifest *UploadManifest,
+	opts ...RequestOption,
+) ([]string, error) {
+	partCount := int((size + request.PartSize - 1) / request.PartSize)
+	partIDs := make([]string, partCount)
+
+	var (
+		mu       sync.Mutex
+		uploaded int64
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pending := make(chan int)
+	for i := 0; i < partCount; i++ {
+		if id, ok := manifest.Parts[i]; ok {
+			partIDs[i] = id
+			uploaded += partLength(i, size, request.PartSize)
+		}
+	}
+	if request.Progress != nil && uploaded > 0 {
+		request.Progress(uploaded, size)
+	}
+
+	for w := 0; w < request.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range pending {
+				offset := int64(index) * request.PartSize
+				section := io.NewSectionReader(f, offset, partLength(index, size, request.PartSize))
+				part, err := c.addUploadPartWithRetry(ctx, manifest.UploadID, section, request.MaxPartRetries, opts...)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("uploading part %d: %w", index+1, err)
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				partIDs[index] = part.ID
+				manifest.Parts[index] = part.ID
+				uploaded += section.Size()
+				if request.ManifestPath != "" {
+					if saveErr := manifest.Save(request.ManifestPath); saveErr != nil && firstErr == nil {
+						firstErr = saveErr
+						cancel()
+					}
+				}
+				if request.Progress != nil {
+					request.Progress(uploaded, size)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < partCount; i++ {
+		if partIDs[i] != "" {
+			continue
+		}
+		select {
+		case pending <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(pending)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return partIDs, nil
+}
+
+func partLength(index int, size, partSize int64) int64 {
+	offset := int64(index) * partSize
+	if size-offset < partSize {
+		return size - offset
+	}
+	return partSize
+}
+
+func (c *Client) addUploadPartWithRetry(
+	ctx context.Context,
+	uploadID string,
+	data *io.SectionReader,
+	maxRetries int,
+	opts ...RequestOption,
+) (part UploadPart, err error) {
+	backoff := defaultUploadRetryBackoff
+	for attempt := 0; ; attempt++ {
+		if _, err = data.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+		part, err = c.AddUploadPart(ctx, uploadID, data, opts...)
+		if err == nil || attempt >= maxRetries || !isRetryableUploadError(err) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func isRetryableUploadError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	statusCode := 0
+	var apiErr *APIError
+	var reqErr *RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		statusCode = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		statusCode = reqErr.HTTPStatusCode
+	default:
+		// transport errors such as connection resets
+		return true
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// fileMD5 returns the hex MD5 checksum of r. It stops with the error of ctx once ctx is done.
+func fileMD5(ctx context.Context, r io.Reader) (string, error) {
+	hash := md5.New() //nolint:gosec // the Uploads API verifies the file with an MD5 checksum
+	buf := make([]byte, fileMD5BufferSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := r.Read(buf)
+		hash.Write(buf[:n])
+		if errors.Is(err, io.EOF) {
+			return hex.EncodeToString(hash.Sum(nil)), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
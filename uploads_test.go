@@ -0,0 +1,215 @@
+package openai_test
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // checksum used by the Uploads API
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gitlab.forensix.cn/ai/service/go-openai"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test"
+	"gitlab.forensix.cn/ai/service/go-openai/internal/test/checks"
+)
+
+// fakeUploads is an in-memory implementation of the Uploads API.
+type fakeUploads struct {
+	mu        sync.Mutex
+	created   int
+	parts     map[string][]byte
+	failParts map[int]int // part number (1-based, by arrival) -> status code
+	received  int
+	completed []byte
+}
+
+func newFakeUploads(server *test.ServerTest) *fakeUploads {
+	f := &fakeUploads{parts: make(map[string][]byte), failParts: make(map[int]int)}
+	server.RegisterHandler("/v1/uploads", func(w http.ResponseWriter, r *http.Request) {
+		var req openai.CreateUploadRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.created++
+		f.mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"id":"upload_abc","object":"upload","bytes":%d,"filename":%q,"purpose":%q,"status":"pending"}`,
+			req.Bytes, req.Filename, req.Purpose)
+	})
+	server.RegisterHandler("/v1/uploads/upload_abc/parts", func(w http.ResponseWriter, r *http.Request) {
+		data, _, err := r.FormFile("data")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(data)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.received++
+		if code, ok := f.failParts[f.received]; ok {
+			w.WriteHeader(code)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"part failed","type":"server_error"}}`)
+			return
+		}
+		id := fmt.Sprintf("part_%d", len(f.parts)+1)
+		f.parts[id] = content
+		_, _ = fmt.Fprintf(w, `{"id":%q,"object":"upload.part","upload_id":"upload_abc"}`, id)
+	})
+	server.RegisterHandler("/v1/uploads/upload_abc/complete", func(w http.ResponseWriter, r *http.Request) {
+		var req openai.CompleteUploadRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var content []byte
+		for _, id := range req.PartIDs {
+			content = append(content, f.parts[id]...)
+		}
+		sum := md5.Sum(content) //nolint:gosec // checksum used by the Uploads API
+		if hex.EncodeToString(sum[:]) != req.MD5 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"checksum mismatch","type":"invalid_request_error"}}`)
+			return
+		}
+		f.completed = content
+		_, _ = fmt.Fprintf(w, `{"id":"upload_abc","object":"upload","status":"completed","file":{"id":"file-xyz","object":"file","bytes":%d}}`, //nolint:lll
+			len(content))
+	})
+	server.RegisterHandler("/v1/uploads/upload_abc/cancel", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"id":"upload_abc","object":"upload","status":"cancelled"}`)
+	})
+	return f
+}
+
+func writeUploadTestFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+	content := []byte(strings.Repeat("0123456789abcdef", size/16+1)[:size])
+	path := filepath.Join(t.TempDir(), "training.jsonl")
+	checks.NoErrorF(t, os.WriteFile(path, content, 0600), "failed to write file")
+	return path, content
+}
+
+func TestUploadEndpoints(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	newFakeUploads(server)
+	ctx := context.Background()
+
+	upload, err := client.CreateUpload(ctx, openai.CreateUploadRequest{
+		Filename: "training.jsonl",
+		Purpose:  openai.PurposeFineTune,
+		Bytes:    5,
+		MimeType: "application/jsonl",
+	})
+	checks.NoError(t, err, "CreateUpload error")
+
+	part, err := client.AddUploadPart(ctx, upload.ID, strings.NewReader("hello"))
+	checks.NoError(t, err, "AddUploadPart error")
+
+	sum := md5.Sum([]byte("hello")) //nolint:gosec // checksum used by the Uploads API
+	completed, err := client.CompleteUpload(ctx, upload.ID, openai.CompleteUploadRequest{
+		PartIDs: []string{part.ID},
+		MD5:     hex.EncodeToString(sum[:]),
+	})
+	checks.NoError(t, err, "CompleteUpload error")
+	if completed.File == nil || completed.File.ID != "file-xyz" {
+		t.Fatalf("expected a completed file, got %+v", completed.File)
+	}
+
+	cancelled, err := client.CancelUpload(ctx, upload.ID)
+	checks.NoError(t, err, "CancelUpload error")
+	if cancelled.Status != "cancelled" {
+		t.Errorf("unexpected status %q", cancelled.Status)
+	}
+}
+
+func TestUploadLargeFile(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := newFakeUploads(server)
+	fake.failParts[2] = http.StatusInternalServerError
+
+	path, content := writeUploadTestFile(t, 10_000)
+	var lastUploaded int64
+	file, err := client.UploadLargeFile(context.Background(), openai.LargeFileUploadRequest{
+		FilePath:    path,
+		Purpose:     openai.PurposeFineTune,
+		PartSize:    1024,
+		Concurrency: 3,
+		Progress: func(uploaded, _ int64) {
+			lastUploaded = uploaded
+		},
+	})
+	checks.NoError(t, err, "UploadLargeFile error")
+	if file.ID != "file-xyz" {
+		t.Errorf("unexpected file %q", file.ID)
+	}
+	if string(fake.completed) != string(content) {
+		t.Error("completed upload does not match the file content")
+	}
+	if lastUploaded != int64(len(content)) {
+		t.Errorf("expected progress to reach %d, got %d", len(content), lastUploaded)
+	}
+}
+
+func TestUploadLargeFileResume(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := newFakeUploads(server)
+	fake.failParts[3] = http.StatusBadRequest
+
+	path, content := writeUploadTestFile(t, 4096)
+	manifestPath := filepath.Join(t.TempDir(), "upload.json")
+	request := openai.LargeFileUploadRequest{
+		FilePath:     path,
+		Purpose:      openai.PurposeFineTune,
+		PartSize:     1024,
+		Concurrency:  1,
+		ManifestPath: manifestPath,
+	}
+
+	_, err := client.UploadLargeFile(context.Background(), request)
+	checks.HasError(t, err, "UploadLargeFile should fail on a non-retryable part error")
+
+	manifest, err := openai.LoadUploadManifest(manifestPath)
+	checks.NoErrorF(t, err, "LoadUploadManifest error")
+	if manifest.UploadID != "upload_abc" || len(manifest.Parts) != 2 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	file, err := client.UploadLargeFile(context.Background(), request)
+	checks.NoError(t, err, "resumed UploadLargeFile error")
+	if file.ID != "file-xyz" {
+		t.Errorf("unexpected file %q", file.ID)
+	}
+	if fake.created != 1 {
+		t.Errorf("expected the upload to be resumed, but %d uploads were created", fake.created)
+	}
+	if string(fake.completed) != string(content) {
+		t.Error("completed upload does not match the file content")
+	}
+	if _, err = os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Error("manifest should be removed after completion")
+	}
+}
+
+func TestUploadLargeFileValidation(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	_, err := client.UploadLargeFile(context.Background(), openai.LargeFileUploadRequest{
+		FilePath: "client.go",
+		PartSize: openai.UploadMaxPartSize + 1,
+	})
+	checks.ErrorIs(t, err, openai.ErrUploadPartTooLarge, "UploadLargeFile should validate the part size")
+
+	path := filepath.Join(t.TempDir(), "empty.jsonl")
+	checks.NoErrorF(t, os.WriteFile(path, nil, 0600), "failed to write file")
+	_, err = client.UploadLargeFile(context.Background(), openai.LargeFileUploadRequest{FilePath: path})
+	checks.ErrorIs(t, err, openai.ErrUploadEmptyFile, "UploadLargeFile should reject empty files")
+}
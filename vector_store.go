@@ -106,13 +106,18 @@ type VectorStoreFileBatchRequest struct {
 }
 
 // CreateVectorStore creates a new vector store.
-func (c *Client) CreateVectorStore(ctx context.Context, request VectorStoreRequest) (response VectorStore, err error) {
+func (c *Client) CreateVectorStore(
+	ctx context.Context,
+	request VectorStoreRequest,
+	opts ...RequestOption,
+) (response VectorStore, err error) {
 	req, _ := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(vectorStoresSuffix),
 		withBody(request),
 		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts),
 	)
 
 	err = c.sendRequest(req, &response)
@@ -123,10 +128,11 @@ func (c *Client) CreateVectorStore(ctx context.Context, request VectorStoreReque
 func (c *Client) RetrieveVectorStore(
 	ctx context.Context,
 	vectorStoreID string,
+	opts ...RequestOption,
 ) (response VectorStore, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
 	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -137,10 +143,11 @@ func (c *Client) ModifyVectorStore(
 	ctx context.Context,
 	vectorStoreID string,
 	request VectorStoreRequest,
+	opts ...RequestOption,
 ) (response VectorStore, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
 	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -150,10 +157,11 @@ func (c *Client) ModifyVectorStore(
 func (c *Client) DeleteVectorStore(
 	ctx context.Context,
 	vectorStoreID string,
+	opts ...RequestOption,
 ) (response VectorStoreDeleteResponse, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
 	req, _ := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -163,6 +171,7 @@ func (c *Client) DeleteVectorStore(
 func (c *Client) ListVectorStores(
 	ctx context.Context,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response VectorStoresList, err error) {
 	urlValues := url.Values{}
 
@@ -186,7 +195,7 @@ func (c *Client) ListVectorStores(
 
 	urlSuffix := fmt.Sprintf("%s%s", vectorStoresSuffix, encodedValues)
 	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -197,11 +206,12 @@ func (c *Client) CreateVectorStoreFile(
 	ctx context.Context,
 	vectorStoreID string,
 	request VectorStoreFileRequest,
+	opts ...RequestOption,
 ) (response VectorStoreFile, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix)
 	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -212,10 +222,11 @@ func (c *Client) RetrieveVectorStoreFile(
 	ctx context.Context,
 	vectorStoreID string,
 	fileID string,
+	opts ...RequestOption,
 ) (response VectorStoreFile, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, fileID)
 	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -226,10 +237,11 @@ func (c *Client) DeleteVectorStoreFile(
 	ctx context.Context,
 	vectorStoreID string,
 	fileID string,
+	opts ...RequestOption,
 ) (err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, fileID)
 	req, _ := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, nil)
 	return
@@ -240,6 +252,7 @@ func (c *Client) ListVectorStoreFiles(
 	ctx context.Context,
 	vectorStoreID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response VectorStoreFilesList, err error) {
 	urlValues := url.Values{}
 	if pagination.After != nil {
@@ -262,7 +275,7 @@ func (c *Client) ListVectorStoreFiles(
 
 	urlSuffix := fmt.Sprintf("%s/%s%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, encodedValues)
 	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -273,11 +286,12 @@ func (c *Client) CreateVectorStoreFileBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	request VectorStoreFileBatchRequest,
+	opts ...RequestOption,
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFileBatchesSuffix)
 	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -288,10 +302,11 @@ func (c *Client) RetrieveVectorStoreFileBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	batchID string,
+	opts ...RequestOption,
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFileBatchesSuffix, batchID)
 	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -302,11 +317,12 @@ func (c *Client) CancelVectorStoreFileBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	batchID string,
+	opts ...RequestOption,
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s", vectorStoresSuffix,
 		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/cancel")
 	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -318,6 +334,7 @@ func (c *Client) ListVectorStoreFilesInBatch(
 	vectorStoreID string,
 	batchID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response VectorStoreFilesList, err error) {
 	urlValues := url.Values{}
 	if pagination.After != nil {
@@ -341,7 +358,7 @@ func (c *Client) ListVectorStoreFilesInBatch(
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s%s", vectorStoresSuffix,
 		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/files", encodedValues)
 	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion), withOptions(opts))
 
 	err = c.sendRequest(req, &response)
 	return
@@ -0,0 +1,273 @@
+package openai
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	wavHeaderSize        = 44
+	wavFormatPCM         = 1
+	wavFormatExtensible  = 0xFFFE
+	wavChunkHeaderSize   = 8
+	wavFmtChunkMinSize   = 16
+	wavUnknownDataSize   = 0xFFFFFFFF
+	wavSilenceFrameCount = 50    // frames per second used to look for silence, i.e. 20ms frames
+	wavSilenceThreshold  = 0.001 // mean amplitude above the quietest frame still considered silent
+)
+
+var (
+	ErrWAVInvalid           = errors.New("invalid WAV file")
+	ErrWAVUnsupportedFormat = errors.New("only integer PCM WAV files are supported")
+	ErrPCMFormatInvalid     = errors.New("PCM format needs a sample rate, channels and 8, 16, 24 or 32 bits per sample")
+)
+
+// PCMFormat describes raw integer PCM audio.
+type PCMFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+func (f PCMFormat) validate() error {
+	switch f.BitsPerSample {
+	case 8, 16, 24, 32:
+	default:
+		return ErrPCMFormatInvalid
+	}
+	if f.SampleRate <= 0 || f.Channels <= 0 {
+		return ErrPCMFormatInvalid
+	}
+	return nil
+}
+
+// blockAlign is the size in bytes of one sample of every channel.
+func (f PCMFormat) blockAlign() int64 {
+	return int64(f.Channels * f.BitsPerSample / 8)
+}
+
+// byteRate is the number of bytes per second of audio.
+func (f PCMFormat) byteRate() int64 {
+	return int64(f.SampleRate) * f.blockAlign()
+}
+
+// Duration returns the duration in seconds of size bytes of audio.
+func (f PCMFormat) Duration(size int64) float64 {
+	return float64(size) / float64(f.byteRate())
+}
+
+// WAVHeader returns a canonical 44 byte WAV header for dataSize bytes of PCM audio.
+func (f PCMFormat) WAVHeader(dataSize int64) []byte {
+	header := make([]byte, wavHeaderSize)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(wavHeaderSize-wavChunkHeaderSize+dataSize))
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], wavFmtChunkMinSize)
+	binary.LittleEndian.PutUint16(header[20:], wavFormatPCM)
+	binary.LittleEndian.PutUint16(header[22:], uint16(f.Channels))
+	binary.LittleEndian.PutUint32(header[24:], uint32(f.SampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(f.byteRate()))
+	binary.LittleEndian.PutUint16(header[32:], uint16(f.blockAlign()))
+	binary.LittleEndian.PutUint16(header[34:], uint16(f.BitsPerSample))
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(dataSize))
+	return header
+}
+
+// readWAVHeader finds the format and the position of the samples of a WAV file of the given size.
+func readWAVHeader(r io.ReaderAt, size int64) (format PCMFormat, dataOffset, dataSize int64, err error) {
+	riff := make([]byte, 12)
+	if _, err = r.ReadAt(riff, 0); err != nil || string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		err = ErrWAVInvalid
+		return
+	}
+
+	hasFormat := false
+	chunk := make([]byte, wavChunkHeaderSize)
+	for offset := int64(12); offset+wavChunkHeaderSize <= size; {
+		if _, err = r.ReadAt(chunk, offset); err != nil {
+			err = fmt.Errorf("%w: %w", ErrWAVInvalid, err)
+			return
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		body := offset + wavChunkHeaderSize
+
+		switch string(chunk[0:4]) {
+		case "fmt ":
+			if chunkSize < wavFmtChunkMinSize {
+				err = ErrWAVInvalid
+				return
+			}
+			fmtChunk := make([]byte, wavFmtChunkMinSize)
+			if _, err = r.ReadAt(fmtChunk, body); err != nil {
+				err = fmt.Errorf("%w: %w", ErrWAVInvalid, err)
+				return
+			}
+			audioFormat := binary.LittleEndian.Uint16(fmtChunk[0:])
+			if audioFormat != wavFormatPCM && audioFormat != wavFormatExtensible {
+				err = ErrWAVUnsupportedFormat
+				return
+			}
+			format = PCMFormat{
+				Channels:      int(binary.LittleEndian.Uint16(fmtChunk[2:])),
+				SampleRate:    int(binary.LittleEndian.Uint32(fmtChunk[4:])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(fmtChunk[14:])),
+			}
+			if err = format.validate(); err != nil {
+				return
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				err = ErrWAVInvalid
+				return
+			}
+			dataOffset = body
+			dataSize = chunkSize
+			// Streamed WAV files may not know their size, and files may be truncated.
+			if chunkSize == wavUnknownDataSize || dataOffset+dataSize > size {
+				dataSize = size - dataOffset
+			}
+			dataSize -= dataSize % format.blockAlign()
+			return
+		}
+		// chunks are padded to an even size
+		offset = body + chunkSize + chunkSize%2
+	}
+	err = ErrWAVInvalid
+	return
+}
+
+// sampleAmplitude returns the absolute amplitude of the sample at the start of b, between 0 and 1.
+func (f PCMFormat) sampleAmplitude(b []byte) float64 {
+	var v float64
+	switch f.BitsPerSample {
+	case 8:
+		v = (float64(b[0]) - 128) / 128
+	case 16:
+		v = float64(int16(binary.LittleEndian.Uint16(b))) / math.MaxInt16
+	case 24:
+		sample := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		v = float64(sample) / (1 << 23)
+	case 32:
+		v = float64(int32(binary.LittleEndian.Uint32(b))) / math.MaxInt32
+	}
+	return math.Abs(v)
+}
+
+// quietestOffset returns the offset, relative to data, of the middle of the longest
+// quiet stretch of data. The offset is aligned to a sample boundary.
+func (f PCMFormat) quietestOffset(data []byte) int64 {
+	block := f.blockAlign()
+	frame := f.byteRate() / wavSilenceFrameCount
+	frame -= frame % block
+	if frame <= 0 || int64(len(data)) < frame {
+		return int64(len(data)) - int64(len(data))%block
+	}
+
+	sampleSize := int64(f.BitsPerSample / 8)
+	energies := make([]float64, int64(len(data))/frame)
+	minEnergy := math.Inf(1)
+	for i := range energies {
+		start := int64(i) * frame
+		for j := start; j < start+frame; j += sampleSize {
+			energies[i] += f.sampleAmplitude(data[j:])
+		}
+		energies[i] /= float64(frame / sampleSize)
+		if energies[i] < minEnergy {
+			minEnergy = energies[i]
+		}
+	}
+
+	// Frames close to the quietest one are considered silent, so that the
+	// boundary falls in the middle of a pause rather than at its edge.
+	threshold := minEnergy*2 + wavSilenceThreshold
+	bestStart, bestLength, runStart := 0, 0, -1
+	for i := 0; i <= len(energies); i++ {
+		if i < len(energies) && energies[i] <= threshold {
+			if runStart < 0 {
+				runStart = i
+			}
+			continue
+		}
+		// prefer later runs on ties so chunks stay as long as possible
+		if runStart >= 0 && i-runStart >= bestLength {
+			bestStart, bestLength = runStart, i-runStart
+		}
+		runStart = -1
+	}
+	middle := int64(bestStart)*frame + int64(bestLength)*frame/2
+	return middle - middle%block
+}
+
+// WAVWriter writes PCM audio to an underlying writer as a WAV file.
+//
+// The header is written before the first samples. As the size of the audio is not known
+// yet, the header announces the largest possible size, which players handle as a stream.
+// If the underlying writer is an io.WriteSeeker, such as an *os.File, Close rewrites the
+// header with the actual size.
+type WAVWriter struct {
+	w             io.Writer
+	format        PCMFormat
+	written       int64
+	headerWritten bool
+}
+
+// NewWAVWriter returns a WAVWriter of audio in format.
+func NewWAVWriter(w io.Writer, format PCMFormat) *WAVWriter {
+	return &WAVWriter{w: w, format: format}
+}
+
+func (w *WAVWriter) writeHeader(dataSize int64) error {
+	if err := w.format.validate(); err != nil {
+		return err
+	}
+	header := w.format.WAVHeader(dataSize)
+	if dataSize < 0 {
+		binary.LittleEndian.PutUint32(header[4:], wavUnknownDataSize)
+		binary.LittleEndian.PutUint32(header[40:], wavUnknownDataSize)
+	}
+	_, err := w.w.Write(header)
+	w.headerWritten = err == nil
+	return err
+}
+
+// Write writes PCM samples, preceded by the WAV header on the first call.
+func (w *WAVWriter) Write(p []byte) (int, error) {
+	if !w.headerWritten {
+		if err := w.writeHeader(-1); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Written returns the number of bytes of samples written.
+func (w *WAVWriter) Written() int64 {
+	return w.written
+}
+
+// Close completes the WAV file. It does not close the underlying writer.
+func (w *WAVWriter) Close() error {
+	if !w.headerWritten {
+		return w.writeHeader(0)
+	}
+	seeker, ok := w.w.(io.WriteSeeker)
+	if !ok {
+		return nil
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := seeker.Write(w.format.WAVHeader(w.written)); err != nil {
+		return err
+	}
+	_, err := seeker.Seek(0, io.SeekEnd)
+	return err
+}